@@ -20,7 +20,7 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		// session-based authentication
 		session, err := c.Cookie("session_token")
 		if err != nil {
-			RespondError(c, http.StatusUnauthorized, "Unauthorized.")
+			RespondErrorT(c, http.StatusUnauthorized, "auth.unauthorized")
 			c.Abort()
 			return
 		}
@@ -28,7 +28,7 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		// Komari is a single user system
 		uuid, err := accounts.GetSession(session)
 		if err != nil {
-			RespondError(c, http.StatusUnauthorized, "Unauthorized.")
+			RespondErrorT(c, http.StatusUnauthorized, "auth.unauthorized")
 			c.Abort()
 			return
 		}
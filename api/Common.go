@@ -16,11 +16,17 @@ import (
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	recordsdb "github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
 )
 
 var (
 	Records = cache.New(1*time.Minute, 1*time.Minute)
+	// PushedMetrics 通过 HTTP 推送的自定义指标，仅 Custom 与 UpdatedAt 有效，与上报中的自定义指标一起按分钟聚合
+	PushedMetrics = cache.New(1*time.Minute, 1*time.Minute)
+	// PushedMetricsMutex 保护 PushedMetrics 中切片的读取、追加与写回
+	PushedMetricsMutex = &sync.Mutex{}
 )
 
 type TerminalSession struct {
@@ -38,6 +44,9 @@ func SaveClientReportToDB() error {
 	lastMinute := time.Now().Add(-time.Minute).Unix()
 	var records []models.Record
 	var gpuRecords []models.GPURecord
+	var customRecords []models.CustomRecord
+	var customDefs []models.CustomMetric
+	customSamples := make(map[string][]common.Report)
 
 	// 遍历所有客户端记录
 	for uuid, x := range Records.Items() {
@@ -71,6 +80,33 @@ func SaveClientReportToDB() error {
 			gpuAggregated := utils.AverageGPUReports(uuid, time.Now(), filtered, 0.3)
 			gpuRecords = append(gpuRecords, gpuAggregated...)
 		}
+		for _, r := range filtered {
+			if len(r.Custom) > 0 {
+				customSamples[uuid] = append(customSamples[uuid], r)
+			}
+		}
+	}
+
+	PushedMetricsMutex.Lock()
+	for uuid, x := range PushedMetrics.Items() {
+		pushed, ok := x.Object.([]common.Report)
+		if !ok {
+			continue
+		}
+		var filtered []common.Report
+		for _, r := range pushed {
+			if r.UpdatedAt.Unix() >= lastMinute {
+				filtered = append(filtered, r)
+			}
+		}
+		PushedMetrics.Set(uuid, filtered, cache.DefaultExpiration)
+		customSamples[uuid] = append(customSamples[uuid], filtered...)
+	}
+	PushedMetricsMutex.Unlock()
+	for uuid, samples := range customSamples {
+		recs, defs := utils.AverageCustomMetrics(uuid, time.Now(), samples, 0.3)
+		customRecords = append(customRecords, recs...)
+		customDefs = append(customDefs, defs...)
 	}
 
 	// 批量插入数据库前去重（client与time共同构成唯一键）
@@ -110,6 +146,13 @@ func SaveClientReportToDB() error {
 		}
 	}
 
+	if len(customRecords) > 0 {
+		if err := recordsdb.SaveCustomRecords(customRecords, customDefs); err != nil {
+			log.Printf("Failed to save custom metric records to database: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -130,13 +173,33 @@ func RespondSuccess(c *gin.Context, data interface{}) {
 }
 
 // RespondSuccessMessage sends a success response with message and data.
+// message 为消息目录中的 key 时按请求语言翻译。
 func RespondSuccessMessage(c *gin.Context, message string, data interface{}) {
-	Respond(c, http.StatusOK, "success", message, data)
+	Respond(c, http.StatusOK, "success", i18n.T(RequestLanguage(c), message), data)
 }
 
 // RespondError sends an error response with message.
+// message 为消息目录中的 key 时按请求语言翻译，否则原样返回。
 func RespondError(c *gin.Context, httpStatus int, message string) {
-	Respond(c, httpStatus, "error", message, nil)
+	Respond(c, httpStatus, "error", i18n.T(RequestLanguage(c), message), nil)
+}
+
+// RespondErrorT 返回带参数的可翻译错误消息，参数中的 i18n.Error 同样会被翻译
+func RespondErrorT(c *gin.Context, httpStatus int, key string, args ...any) {
+	Respond(c, httpStatus, "error", i18n.T(RequestLanguage(c), key, args...), nil)
+}
+
+// RespondErrorOf 返回错误的文本，可翻译的错误按请求语言翻译
+func RespondErrorOf(c *gin.Context, httpStatus int, err error) {
+	Respond(c, httpStatus, "error", i18n.Localize(RequestLanguage(c), err), nil)
+}
+
+// RequestLanguage 返回请求的语言，优先使用 lang 查询参数，其次是 Accept-Language
+func RequestLanguage(c *gin.Context) string {
+	if lang := c.Query("lang"); lang != "" {
+		return i18n.Normalize(lang)
+	}
+	return i18n.Match(c.GetHeader("Accept-Language"))
 }
 func GetVersion(c *gin.Context) {
 	RespondSuccess(c, gin.H{
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/utils/ipfilter"
+)
+
+// 访问控制作用域
+const (
+	ScopeAdmin  = "admin"
+	ScopeAgent  = "agent"
+	ScopePublic = "public"
+)
+
+// RouteScope 根据路径判断请求属于哪一类路由
+func RouteScope(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/api/admin"):
+		return ScopeAdmin
+	case strings.HasPrefix(path, "/api/clients/"):
+		// /api/clients 本身是公开的节点列表，子路径才是 Agent 接口
+		return ScopeAgent
+	default:
+		return ScopePublic
+	}
+}
+
+// IsIPAllowed 按作用域检查 IP 访问控制列表
+func IsIPAllowed(scope, ip string) bool {
+	cfg, err := config.Get()
+	if err != nil {
+		return true
+	}
+	var allow, deny string
+	switch scope {
+	case ScopeAdmin:
+		allow, deny = cfg.AdminAllowCidrs, cfg.AdminDenyCidrs
+	case ScopeAgent:
+		allow, deny = cfg.AgentAllowCidrs, cfg.AgentDenyCidrs
+	default:
+		allow, deny = cfg.PublicAllowCidrs, cfg.PublicDenyCidrs
+	}
+	if allow == "" && deny == "" {
+		return true
+	}
+	return ipfilter.Allowed(ip, ipfilter.Cached(allow), ipfilter.Cached(deny))
+}
+
+// IPAccessMiddleware 对 /api 下的路由应用 IP 允许/拒绝列表。
+// 客户端 IP 取自 c.ClientIP()，仅信任 --trusted-proxies 中代理传递的转发头。
+func IPAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, "/api") {
+			c.Next()
+			return
+		}
+		if !IsIPAllowed(RouteScope(path), c.ClientIP()) {
+			RespondErrorT(c, http.StatusForbidden, "auth.ip_denied")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
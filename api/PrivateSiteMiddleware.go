@@ -47,7 +47,7 @@ func PrivateSiteMiddleware() gin.HandlerFunc {
 		}
 		conf, err := config.Get()
 		if err != nil {
-			RespondError(c, http.StatusInternalServerError, "Failed to get configuration.")
+			RespondErrorT(c, http.StatusInternalServerError, "auth.config_failed")
 			c.Abort()
 			return
 		}
@@ -56,16 +56,24 @@ func PrivateSiteMiddleware() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// 通过公开面板访问的 RPC 请求不受私有站点限制，由 RPC 层按面板规则过滤，且只允许面板使用的只读方法
+		if c.Request.URL.Path == "/api/rpc2" {
+			if d, err := ResolveDashboard(c); err == nil && d != nil {
+				c.Set(DashboardOnlyKey, true)
+				c.Next()
+				return
+			}
+		}
 		// 如果是私有站点，检查是否有 session
 		session, err := c.Cookie("session_token")
 		if err != nil {
-			RespondError(c, http.StatusUnauthorized, "Private site is enabled, please login first.")
+			RespondErrorT(c, http.StatusUnauthorized, "auth.private_site")
 			c.Abort()
 			return
 		}
 		_, err = accounts.GetSession(session)
 		if err != nil {
-			RespondError(c, http.StatusUnauthorized, "Unauthorized.")
+			RespondErrorT(c, http.StatusUnauthorized, "auth.unauthorized")
 			c.Abort()
 			return
 		}
@@ -7,7 +7,9 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/komari-monitor/komari/cmd/flags"
 	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/utils/agentca"
 	"gorm.io/gorm"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,13 @@ func TokenAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 经内置 CA 校验的客户端证书可以代替 token
+		if uuid, ok := clientFromCertificate(c); ok {
+			c.Set("client_uuid", uuid)
+			c.Next()
+			return
+		}
+
 		var token string
 
 		// Step 1: Check query parameter for token
@@ -73,6 +82,27 @@ func TokenAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// clientFromCertificate 从已验证的客户端证书中取出 UUID（证书 CommonName），并检查证书是否已吊销
+func clientFromCertificate(c *gin.Context) (string, bool) {
+	if !flags.AgentMTLS || c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		return "", false
+	}
+	leaf := c.Request.TLS.VerifiedChains[0][0]
+	uuid := leaf.Subject.CommonName
+	if uuid == "" || !clients.IsCertificateValid(agentca.SerialString(leaf.SerialNumber), uuid) {
+		return "", false
+	}
+	return uuid, true
+}
+
+// GetClientUUID 获取当前 Agent 请求对应的客户端 UUID，支持客户端证书与 token 两种方式
+func GetClientUUID(c *gin.Context) (string, error) {
+	if uuid, ok := c.Get("client_uuid"); ok {
+		return uuid.(string), nil
+	}
+	return clients.GetClientUUIDByToken(c.Query("token"))
+}
+
 func checkTokenExists(token string) (bool, error) {
 	_, err := clients.GetClientUUIDByToken(token)
 
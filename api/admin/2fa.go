@@ -12,7 +12,7 @@ import (
 func Generate2FA(c *gin.Context) {
 	secret, img, err := accounts.Generate2Fa()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to generate 2FA: "+err.Error())
+		api.RespondErrorT(c, 500, "twofa.generate_failed", err)
 		return
 	}
 	c.SetCookie("2fa_secret", secret, 1800, "/", "", false, true)
@@ -26,16 +26,16 @@ func Enable2FA(c *gin.Context) {
 	secret, _ := c.Cookie("2fa_secret")
 	code := c.Query("code")
 	if secret == "" || uuid == nil || code == "" {
-		api.RespondError(c, 400, "2FA secret or code not provided")
+		api.RespondErrorT(c, 400, "twofa.secret_or_code_missing")
 		return
 	}
 	if !totp.Validate(code, secret) {
-		api.RespondError(c, 400, "Invalid 2FA code")
+		api.RespondErrorT(c, 400, "twofa.invalid_code")
 		return
 	}
 	err := accounts.Enable2Fa(uuid.(string), secret)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to enable 2FA: "+err.Error())
+		api.RespondErrorT(c, 500, "twofa.enable_failed", err)
 		return
 	}
 	c.SetCookie("2fa_secret", "", -1, "/", "", false, true)
@@ -47,7 +47,7 @@ func Disable2FA(c *gin.Context) {
 	uuid, _ := c.Get("uuid")
 	err := accounts.Disable2Fa(uuid.(string))
 	if err != nil {
-		api.RespondError(c, 500, "Failed to disable 2FA: "+err.Error())
+		api.RespondErrorT(c, 500, "twofa.disable_failed", err)
 		return
 	}
 	api.RespondSuccess(c, "")
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils/actions"
+)
+
+func GetAllThresholdActions(c *gin.Context) {
+	list, err := tasks.GetAllThresholdActions()
+	if err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// POST body: name, enable, clients, trigger, type, command, webhook_url
+func AddThresholdAction(c *gin.Context) {
+	var req models.ThresholdAction
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	req.Id = 0
+	if err := actions.Validate(req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := tasks.AddThresholdAction(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("add threshold action: %s (%s/%s)", req.Name, req.Trigger, req.Type), "warn")
+	api.RespondSuccess(c, gin.H{"id": req.Id})
+}
+
+// POST body: actions []models.ThresholdAction
+func EditThresholdAction(c *gin.Context) {
+	var req struct {
+		Actions []*models.ThresholdAction `json:"actions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_data")
+		return
+	}
+	for _, action := range req.Actions {
+		if err := actions.Validate(*action); err != nil {
+			api.RespondErrorOf(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := tasks.EditThresholdAction(req.Actions); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("edit threshold actions: %d", len(req.Actions)), "warn")
+	api.RespondSuccess(c, nil)
+}
+
+// POST body: id []uint
+func DeleteThresholdAction(c *gin.Context) {
+	var req struct {
+		ID []uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := tasks.DeleteThresholdAction(req.ID); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("delete threshold actions: %v", req.ID), "warn")
+	api.RespondSuccess(c, nil)
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	db "github.com/komari-monitor/komari/database/agentupdate"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/agentupdate"
+)
+
+// ListAgentBinaries 列出已保存的 Agent 二进制文件
+func ListAgentBinaries(c *gin.Context) {
+	list, err := db.ListBinaries()
+	if err != nil {
+		api.RespondErrorT(c, 500, "agent_update.list_binaries_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// AddAgentBinary 上传或从外部地址获取 Agent 二进制文件
+// multipart 表单：
+// - version / os / arch: 版本与平台
+// - file: 二进制文件，与 url 二选一
+// - url: 下载地址
+// - sha256: 可选，用于校验
+func AddAgentBinary(c *gin.Context) {
+	version := c.PostForm("version")
+	goos := c.PostForm("os")
+	arch := c.PostForm("arch")
+	sha256 := c.PostForm("sha256")
+	var b *models.AgentBinary
+	if file, _, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		b, err = agentupdate.StoreBinary(version, goos, arch, file, sha256, "")
+		if err != nil {
+			api.RespondErrorT(c, 400, "agent_update.save_binary_failed", err)
+			return
+		}
+	} else if url := c.PostForm("url"); url != "" {
+		b, err = agentupdate.FetchBinary(url, version, goos, arch, sha256)
+		if err != nil {
+			api.RespondErrorT(c, 400, "agent_update.fetch_binary_failed", err)
+			return
+		}
+	} else {
+		api.RespondErrorT(c, 400, "agent_update.file_or_url_required")
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("add agent binary %s %s/%s, sha256 %s", b.Version, b.OS, b.Arch, b.SHA256), "warn")
+	api.RespondSuccess(c, b)
+}
+
+// DeleteAgentBinary 删除 Agent 二进制文件
+func DeleteAgentBinary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_id")
+		return
+	}
+	b, err := db.GetBinary(uint(id))
+	if err != nil {
+		api.RespondErrorT(c, 404, "agent_update.binary_not_found")
+		return
+	}
+	if err := agentupdate.RemoveBinary(*b); err != nil {
+		api.RespondErrorT(c, 500, "agent_update.delete_binary_failed", err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("delete agent binary %s %s/%s", b.Version, b.OS, b.Arch), "info")
+	api.RespondSuccess(c, nil)
+}
+
+// ListAgentRollouts 列出 Agent 升级计划
+func ListAgentRollouts(c *gin.Context) {
+	list, err := db.ListRollouts()
+	if err != nil {
+		api.RespondErrorT(c, 500, "agent_update.list_rollouts_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// GetAgentRollout 获取升级计划及各客户端状态
+func GetAgentRollout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_id")
+		return
+	}
+	r, err := db.GetRollout(uint(id))
+	if err != nil {
+		api.RespondErrorT(c, 404, "agent_update.rollout_not_found")
+		return
+	}
+	api.RespondSuccess(c, r)
+}
+
+// CreateAgentRollout 为分组设置目标版本并开始分阶段升级
+// 接受数据类型：
+// - group: string，为空表示未分组的客户端
+// - target_version: string
+// - percent: int 灰度阶段比例，默认 10
+// - health_window: int 分钟，默认 10
+// - max_failures: int 失败多少个后自动暂停，默认 1
+func CreateAgentRollout(c *gin.Context) {
+	opts := agentupdate.RolloutOptions{Percent: 10, HealthWindow: 10, MaxFailures: 1}
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	r, err := agentupdate.CreateRollout(opts)
+	if err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("create agent rollout %d: group %q to %s, %d clients", r.Id, r.Group, r.TargetVersion, len(r.Targets)), "warn")
+	api.RespondSuccess(c, r)
+}
+
+// ControlAgentRollout 暂停、继续或取消升级计划
+// Param: action pause | resume | cancel
+// resume 接受数据类型：
+// - retry_failed: bool 是否重新下发给失败的客户端
+func ControlAgentRollout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_id")
+		return
+	}
+	action := c.Param("action")
+	switch action {
+	case "pause":
+		err = agentupdate.Pause(uint(id))
+	case "resume":
+		var req struct {
+			RetryFailed bool `json:"retry_failed"`
+		}
+		c.ShouldBindJSON(&req)
+		err = agentupdate.Resume(uint(id), req.RetryFailed)
+	case "cancel":
+		err = agentupdate.Cancel(uint(id))
+	default:
+		api.RespondErrorT(c, 400, "agent_update.unknown_action", action)
+		return
+	}
+	if err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("%s agent rollout %d", action, id), "info")
+	api.RespondSuccess(c, nil)
+}
+
+// GetAgentVersions 获取 Agent 版本分布
+func GetAgentVersions(c *gin.Context) {
+	report, err := agentupdate.Report()
+	if err != nil {
+		api.RespondErrorT(c, 500, "agent_update.report_failed", err)
+		return
+	}
+	api.RespondSuccess(c, report)
+}
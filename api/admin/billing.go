@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/traffic"
+	"github.com/komari-monitor/komari/utils/billing"
+)
+
+func buildBillingReport(c *gin.Context) (billing.Report, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		return billing.Report{}, err
+	}
+	rates, source, err := billing.LoadRates(cfg)
+	if err != nil {
+		return billing.Report{}, err
+	}
+	cls, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return billing.Report{}, err
+	}
+	currency := c.DefaultQuery("currency", cfg.BillingCurrency)
+	// 已用流量取当前流量周期的累计值，Agent 上报的累计计数会在重启后归零
+	usage := make(map[string]billing.Usage, len(cls))
+	for _, cl := range cls {
+		cycle := traffic.Current(cl.UUID)
+		usage[cl.UUID] = billing.Usage{
+			Bytes: cycle.Sum,
+			Start: cycle.CycleStart.ToTime(),
+			End:   cycle.CycleEnd.ToTime(),
+		}
+	}
+	report := billing.BuildReport(cls, usage, currency, rates, time.Now())
+	report.RateSource = source
+	return report, nil
+}
+
+// GET /api/admin/billing/summary?currency=USD
+func GetBillingSummary(c *gin.Context) {
+	report, err := buildBillingReport(c)
+	if err != nil {
+		api.RespondErrorT(c, 500, "billing.report_failed", err)
+		return
+	}
+	api.RespondSuccess(c, report)
+}
+
+// GET /api/admin/billing/rates
+func GetExchangeRates(c *gin.Context) {
+	cfg, err := config.Get()
+	if err != nil {
+		api.RespondErrorT(c, 500, "common.config_read_failed", err)
+		return
+	}
+	rates, source, err := billing.LoadRates(cfg)
+	if err != nil {
+		api.RespondErrorT(c, 500, "billing.exchange_rates_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{"base": "USD", "source": source, "rates": rates})
+}
+
+func parseLedgerQuery(c *gin.Context) ([]models.RenewalRecord, error) {
+	var from, to time.Time
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	return clients.GetRenewalRecords(c.Query("uuid"), from, to)
+}
+
+// GET /api/admin/billing/ledger?uuid=&from=&to=
+func GetRenewalLedger(c *gin.Context) {
+	records, err := parseLedgerQuery(c)
+	if err != nil {
+		api.RespondErrorT(c, 400, "billing.ledger_failed", err)
+		return
+	}
+	api.RespondSuccess(c, records)
+}
+
+// GET /api/admin/billing/export?type=clients|ledger
+func ExportBilling(c *gin.Context) {
+	var rows [][]string
+	switch c.DefaultQuery("type", "clients") {
+	case "clients":
+		report, err := buildBillingReport(c)
+		if err != nil {
+			api.RespondErrorT(c, 500, "billing.report_failed", err)
+			return
+		}
+		rows = billingClientRows(report)
+	case "ledger":
+		records, err := parseLedgerQuery(c)
+		if err != nil {
+			api.RespondErrorT(c, 400, "billing.ledger_failed", err)
+			return
+		}
+		rows = billingLedgerRows(records)
+	default:
+		api.RespondErrorT(c, 400, "billing.invalid_export_type")
+		return
+	}
+
+	filename := fmt.Sprintf("komari-billing-%s-%s.csv", c.DefaultQuery("type", "clients"), time.Now().Format("20060102"))
+	c.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	c.Writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	// UTF-8 BOM，便于 Excel 正确识别中文
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(c.Writer)
+	w.WriteAll(rows)
+}
+
+func billingClientRows(report billing.Report) [][]string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	rows := [][]string{{
+		"uuid", "name", "group", "tags", "provider", "price", "currency", "billing_cycle",
+		"monthly_" + report.Currency, "annual_" + report.Currency, "cpu_cores", "cost_per_core",
+		"traffic_limit_gb", "cost_per_gb", "traffic_used_gb", "cost_per_used_gb",
+		"expired_at", "remaining_days", "remaining_value_" + report.Currency, "converted",
+	}}
+	for _, cc := range report.Clients {
+		rows = append(rows, []string{
+			cc.UUID, cc.Name, cc.Group, strings.Join(cc.Tags, ";"), cc.Provider,
+			f(cc.Price), cc.Currency, strconv.Itoa(cc.BillingCycle),
+			f(cc.Monthly), f(cc.Annual), strconv.Itoa(cc.CpuCores), f(cc.CostPerCore),
+			f(cc.TrafficLimitGB), f(cc.CostPerGB), f(cc.TrafficUsedGB), f(cc.CostPerUsedGB),
+			cc.ExpiredAt.ToTime().Format(time.RFC3339), f(float64(cc.RemainingSeconds) / 86400), f(cc.RemainingValue),
+			strconv.FormatBool(cc.Converted),
+		})
+	}
+	return rows
+}
+
+func billingLedgerRows(records []models.RenewalRecord) [][]string {
+	rows := [][]string{{"id", "client", "client_name", "amount", "currency", "billing_cycle", "previous_expired_at", "new_expired_at", "created_at"}}
+	for _, r := range records {
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(r.Id), 10), r.Client, r.ClientName,
+			strconv.FormatFloat(r.Amount, 'f', 2, 64), r.Currency, strconv.Itoa(r.BillingCycle),
+			r.PreviousExpiredAt.ToTime().Format(time.RFC3339), r.NewExpiredAt.ToTime().Format(time.RFC3339),
+			r.CreatedAt.ToTime().Format(time.RFC3339),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/cmd/flags"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/utils/agentca"
+)
+
+// GET /api/admin/client/:uuid/certificate
+func ListClientCertificates(c *gin.Context) {
+	certs, err := clients.GetClientCertificates(c.Param("uuid"))
+	if err != nil {
+		api.RespondErrorT(c, 500, "certificate.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, certs)
+}
+
+// POST /api/admin/client/:uuid/certificate
+func IssueClientCertificate(c *gin.Context) {
+	if !flags.AgentMTLS {
+		api.RespondErrorT(c, 400, "certificate.mtls_disabled")
+		return
+	}
+	uuid := c.Param("uuid")
+	if _, err := clients.GetClientByUUID(uuid); err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	issued, err := clients.IssueClientCertificate(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 500, "certificate.issue_failed", err)
+		return
+	}
+	ca, _ := agentca.CertificatePEM()
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "issued client certificate: "+uuid+", serial: "+issued.Serial, "info")
+	api.RespondSuccess(c, gin.H{
+		"serial":      issued.Serial,
+		"not_after":   issued.NotAfter,
+		"certificate": string(issued.Certificate),
+		"private_key": string(issued.PrivateKey),
+		"ca":          string(ca),
+	})
+}
+
+// POST /api/admin/client/:uuid/certificate/revoke
+func RevokeClientCertificates(c *gin.Context) {
+	uuid := c.Param("uuid")
+	if err := clients.RevokeClientCertificates(uuid); err != nil {
+		api.RespondErrorT(c, 500, "certificate.revoke_failed", err)
+		return
+	}
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "revoked client certificates: "+uuid, "warn")
+	api.RespondSuccess(c, nil)
+}
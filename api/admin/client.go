@@ -2,11 +2,15 @@ package admin
 
 import (
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/auditlog"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/records"
+	"github.com/komari-monitor/komari/database/traffic"
 	"github.com/komari-monitor/komari/ws"
 )
 
@@ -44,12 +48,20 @@ func EditClient(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid or missing UUID"})
 		return
 	}
+	if v, ok := req["traffic_reset_day"].(float64); ok && (v < 1 || v > 31) {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "traffic_reset_day must be between 1 and 31"})
+		return
+	}
 	req["uuid"] = uuid
 	err := clients.SaveClient(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
 		return
 	}
+	if _, ok := req["group"]; ok {
+		// 分组变化后生效的采集配置可能随之变化
+		pushCollectConfig([]string{uuid}, false)
+	}
 	user_uuid, _ := c.Get("uuid")
 	auditlog.Log(c.ClientIP(), user_uuid.(string), "edit client:"+uuid, "info")
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
@@ -135,3 +147,78 @@ func GetClientToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "token": token, "message:": ""})
 }
+
+type providerBreakdown struct {
+	ASN          uint     `json:"asn"`
+	Organization string   `json:"organization"`
+	Count        int      `json:"count"`
+	Clients      []string `json:"clients"`
+}
+
+// GetProviderBreakdown 按 ASN / 服务商统计客户端数量，未解析到 ASN 的客户端归入 asn=0
+func GetProviderBreakdown(c *gin.Context) {
+	cls, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "client.get_failed", err)
+		return
+	}
+	groups := map[string]*providerBreakdown{}
+	for _, cl := range cls {
+		key := strconv.FormatUint(uint64(cl.ASN), 10) + "|" + cl.Organization
+		g, ok := groups[key]
+		if !ok {
+			g = &providerBreakdown{ASN: cl.ASN, Organization: cl.Organization, Clients: []string{}}
+			groups[key] = g
+		}
+		g.Count++
+		g.Clients = append(g.Clients, cl.UUID)
+	}
+	result := make([]*providerBreakdown, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].ASN < result[j].ASN
+	})
+	api.RespondSuccess(c, result)
+}
+
+// GET /api/admin/client/:uuid/ip-history
+func GetClientIPHistory(c *gin.Context) {
+	history, err := clients.GetClientIPHistory(c.Param("uuid"))
+	if err != nil {
+		api.RespondErrorT(c, 500, "client.ip_history_failed", err)
+		return
+	}
+	api.RespondSuccess(c, history)
+}
+
+// GET /api/admin/client/:uuid/traffic?limit=12
+func GetClientTraffic(c *gin.Context) {
+	uuid := c.Param("uuid")
+	client, err := clients.GetClientByUUID(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	if limit < 1 {
+		limit = 12
+	}
+	cycles, err := traffic.History(uuid, limit)
+	if err != nil {
+		api.RespondErrorT(c, 500, "client.traffic_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{
+		"reset_day":  client.TrafficResetDay,
+		"limit":      client.TrafficLimit,
+		"limit_type": client.TrafficLimitType,
+		"used":       cycles[0].Used(client.TrafficLimitType),
+		"current":    cycles[0],
+		"history":    cycles[1:],
+	})
+}
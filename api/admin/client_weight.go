@@ -11,14 +11,14 @@ import (
 func OrderWeight(c *gin.Context) {
 	var req = make(map[string]int)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, 400, "Invalid or missing request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
 		return
 	}
 	db := dbcore.GetDBInstance()
 	for uuid, weight := range req {
 		err := db.Model(&models.Client{}).Where("uuid = ?", uuid).Update("weight", weight).Error
 		if err != nil {
-			api.RespondError(c, 500, "Failed to update client weight: "+err.Error())
+			api.RespondErrorT(c, 500, "client.update_weight_failed", err)
 			return
 		}
 	}
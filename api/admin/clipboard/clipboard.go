@@ -1,6 +1,7 @@
 package clipboard
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/komari-monitor/komari/database/auditlog"
 	clipboardDB "github.com/komari-monitor/komari/database/clipboard"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils/snippet"
 )
 
 // GetClipboard retrieves a clipboard entry by ID
@@ -16,36 +19,64 @@ func GetClipboard(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid ID")
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_id")
 		return
 	}
 	cb, err := clipboardDB.GetClipboardByID(id)
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to get clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.get_failed", err)
 		return
 	}
 	api.RespondSuccess(c, cb)
 }
 
-// ListClipboard lists all clipboard entries
+// ListClipboard lists all clipboard entries, optionally filtered by folder and tag
 func ListClipboard(c *gin.Context) {
 	list, err := clipboardDB.ListClipboard()
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to list clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.list_failed", err)
 		return
 	}
+	folder, folderSet := c.GetQuery("folder")
+	tag := c.Query("tag")
+	if folderSet || tag != "" {
+		filtered := make([]models.Clipboard, 0, len(list))
+		for _, cb := range list {
+			if folderSet && cb.Folder != folder {
+				continue
+			}
+			if tag != "" && !hasTag(cb, tag) {
+				continue
+			}
+			filtered = append(filtered, cb)
+		}
+		list = filtered
+	}
 	api.RespondSuccess(c, list)
 }
 
+func hasTag(cb models.Clipboard, tag string) bool {
+	for _, t := range cb.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateClipboard creates a new clipboard entry
 func CreateClipboard(c *gin.Context) {
 	var req models.Clipboard
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
+		return
+	}
+	if err := snippet.ValidateParams(req); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_template", err)
 		return
 	}
 	if err := clipboardDB.CreateClipboard(&req); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to create clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.create_failed", err)
 		return
 	}
 	userUUID, _ := c.Get("uuid")
@@ -58,16 +89,42 @@ func UpdateClipboard(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid ID")
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_id")
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
 		return
 	}
 	var fields map[string]interface{}
-	if err := c.ShouldBindJSON(&fields); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+	if err := json.Unmarshal(body, &fields); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
+		return
+	}
+	current, err := clipboardDB.GetClipboardByID(id)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusNotFound, "clipboard.not_found")
 		return
 	}
+	// 合并后校验，JSON 字段需要转换为对应类型才能写入
+	merged := *current
+	if err := json.Unmarshal(body, &merged); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
+		return
+	}
+	if err := snippet.ValidateParams(merged); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_template", err)
+		return
+	}
+	if _, ok := fields["params"]; ok {
+		fields["params"] = merged.Params
+	}
+	if _, ok := fields["tags"]; ok {
+		fields["tags"] = merged.Tags
+	}
 	if err := clipboardDB.UpdateClipboardFields(id, fields); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to update clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.update_failed", err)
 		return
 	}
 	userUUID, _ := c.Get("uuid")
@@ -80,11 +137,11 @@ func DeleteClipboard(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid ID")
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_id")
 		return
 	}
 	if err := clipboardDB.DeleteClipboard(id); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to delete clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.delete_failed", err)
 		return
 	}
 	userUUID, _ := c.Get("uuid")
@@ -98,18 +155,76 @@ func BatchDeleteClipboard(c *gin.Context) {
 		IDs []int `json:"ids" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
 		return
 	}
 	if len(req.IDs) == 0 {
-		api.RespondError(c, http.StatusBadRequest, "IDs cannot be empty")
+		api.RespondErrorT(c, http.StatusBadRequest, "clipboard.ids_empty")
 		return
 	}
 	if err := clipboardDB.DeleteClipboardBatch(req.IDs); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to batch delete clipboard: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.batch_delete_failed", err)
 		return
 	}
 	userUUID, _ := c.Get("uuid")
 	auditlog.Log(c.ClientIP(), userUUID.(string), "batch delete clipboard: "+strconv.Itoa(len(req.IDs))+" items", "warn")
 	api.RespondSuccess(c, nil)
 }
+
+// ExportClipboard exports all clipboard entries without ids and timestamps
+func ExportClipboard(c *gin.Context) {
+	list, err := clipboardDB.ListClipboard()
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.list_failed", err)
+		return
+	}
+	for i := range list {
+		list[i].Id = 0
+		list[i].CreatedAt = models.LocalTime{}
+		list[i].UpdatedAt = models.LocalTime{}
+	}
+	c.Header("Content-Disposition", "attachment; filename=komari-clipboard.json")
+	api.RespondSuccess(c, list)
+}
+
+// ImportClipboard imports clipboard entries. Entries with the same folder and name
+// are overwritten when overwrite is true, otherwise they are skipped.
+func ImportClipboard(c *gin.Context) {
+	var req struct {
+		Items     []models.Clipboard `json:"items" binding:"required"`
+		Overwrite bool               `json:"overwrite"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request", err)
+		return
+	}
+	for i, item := range req.Items {
+		if err := snippet.ValidateParams(item); err != nil {
+			api.RespondErrorT(c, http.StatusBadRequest, "clipboard.invalid_item_template", i, err)
+			return
+		}
+	}
+	created, updated, skipped, err := clipboardDB.ImportClipboard(req.Items, req.Overwrite)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "clipboard.import_failed", err)
+		return
+	}
+	userUUID, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), userUUID.(string), "import clipboard: "+strconv.Itoa(created)+" created, "+strconv.Itoa(updated)+" updated", "info")
+	api.RespondSuccess(c, gin.H{"created": created, "updated": updated, "skipped": skipped})
+}
+
+// GetClipboardTasks lists the tasks executed from a clipboard entry
+func GetClipboardTasks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_id")
+		return
+	}
+	list, err := tasks.GetTasksBySnippet(id)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "task.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
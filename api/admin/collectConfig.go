@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
+)
+
+// GetClientCollectConfig 获取客户端生效的采集配置及其来源（client / group / default）
+func GetClientCollectConfig(c *gin.Context) {
+	uuid := c.Param("uuid")
+	client, err := clients.GetClientByUUID(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	cfg, source, err := clients.GetEffectiveConfig(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 500, "collect_config.get_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{
+		"config": cfg,
+		"source": source,
+		"group":  client.Group,
+	})
+}
+
+// EditClientCollectConfig 设置客户端的单独采集配置并下发，未提交的字段沿用当前生效的值
+func EditClientCollectConfig(c *gin.Context) {
+	uuid := c.Param("uuid")
+	cfg, _, err := clients.GetEffectiveConfig(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	if err := clients.SaveClientCollectConfig(uuid, cfg); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	pushCollectConfig([]string{uuid}, false)
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "edit collect config of client: "+uuid, "info")
+	api.RespondSuccess(c, cfg)
+}
+
+// ResetClientCollectConfig 删除客户端的单独配置，恢复使用分组配置或默认值
+func ResetClientCollectConfig(c *gin.Context) {
+	uuid := c.Param("uuid")
+	if _, err := clients.GetClientByUUID(uuid); err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	if err := clients.DeleteClientCollectConfig(uuid); err != nil {
+		api.RespondErrorT(c, 500, "collect_config.reset_failed", err)
+		return
+	}
+	pushCollectConfig([]string{uuid}, false)
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "reset collect config of client: "+uuid, "info")
+	api.RespondSuccess(c, nil)
+}
+
+// ListGroupCollectConfigs 列出分组的采集配置
+func ListGroupCollectConfigs(c *gin.Context) {
+	list, err := clients.ListGroupConfigs()
+	if err != nil {
+		api.RespondErrorT(c, 500, "collect_config.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// EditGroupCollectConfig 设置分组的采集配置，并下发给分组内没有单独配置的在线客户端
+func EditGroupCollectConfig(c *gin.Context) {
+	group := c.Param("group")
+	cfg := clients.GetGroupConfig(group)
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	if err := clients.SaveGroupConfig(group, cfg); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	uuids, _ := clients.GetGroupClientUUIDs(group)
+	pushCollectConfig(uuids, true)
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "edit collect config of group: "+group, "info")
+	api.RespondSuccess(c, cfg)
+}
+
+// DeleteGroupCollectConfig 删除分组的采集配置
+func DeleteGroupCollectConfig(c *gin.Context) {
+	group := c.Param("group")
+	if err := clients.DeleteGroupConfig(group); err != nil {
+		api.RespondErrorT(c, 500, "collect_config.delete_failed", err)
+		return
+	}
+	uuids, _ := clients.GetGroupClientUUIDs(group)
+	pushCollectConfig(uuids, true)
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "delete collect config of group: "+group, "info")
+	api.RespondSuccess(c, nil)
+}
+
+// pushCollectConfig 向在线客户端下发生效的配置，skipOverridden 为 true 时跳过有单独配置的客户端
+func pushCollectConfig(uuids []string, skipOverridden bool) {
+	for _, uuid := range uuids {
+		if skipOverridden {
+			if _, source, err := clients.GetEffectiveConfig(uuid); err != nil || source == clients.ConfigSourceClient {
+				continue
+			}
+		}
+		if err := api.PushCollectConfig(uuid); err != nil {
+			log.Printf("Failed to push config to client %s: %v", uuid, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/records"
+	"gorm.io/gorm"
+)
+
+// ListCustomMetrics 列出客户端上报过的自定义指标
+func ListCustomMetrics(c *gin.Context) {
+	list, err := records.ListCustomMetrics(c.Param("uuid"))
+	if err != nil {
+		api.RespondErrorT(c, 500, "custom_metric.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// DeleteCustomMetric 删除自定义指标及其历史记录，客户端再次上报时会重新出现
+func DeleteCustomMetric(c *gin.Context) {
+	uuid := c.Param("uuid")
+	name := c.Param("name")
+	if err := records.DeleteCustomMetric(uuid, name); err != nil {
+		api.RespondErrorT(c, 500, "custom_metric.delete_failed", err)
+		return
+	}
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "delete custom metric "+name+" of client: "+uuid, "info")
+	api.RespondSuccess(c, nil)
+}
+
+// SetCustomMetricPublic 设置自定义指标是否对访客公开，默认不公开
+func SetCustomMetricPublic(c *gin.Context) {
+	uuid := c.Param("uuid")
+	name := c.Param("name")
+	var req struct {
+		Public bool `json:"public"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if err := records.SetCustomMetricPublic(uuid, name, req.Public); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			api.RespondErrorT(c, 404, "custom_metric.not_found")
+			return
+		}
+		api.RespondErrorT(c, 500, "custom_metric.update_failed", err)
+		return
+	}
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), fmt.Sprintf("set custom metric %s of client %s public: %t", name, uuid, req.Public), "info")
+	api.RespondSuccess(c, nil)
+}
@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/dashboards"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/exposure"
+)
+
+var dashboardSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type dashboardRequest struct {
+	models.Dashboard
+	Password *string `json:"password"` // 为空时保持原密码
+}
+
+// validateDashboard 检查面板配置，hasPassword 表示保存后面板是否设置了访问密码
+func validateDashboard(d models.Dashboard, hasPassword bool) error {
+	if !dashboardSlugPattern.MatchString(d.Slug) {
+		return errors.New("slug must be 1-64 lowercase letters, digits, '-' or '_'")
+	}
+	switch d.Access {
+	case models.DashboardAccessPublic, models.DashboardAccessToken:
+	case models.DashboardAccessPassword:
+		if !hasPassword {
+			return errors.New("password is required for password-protected dashboard")
+		}
+	default:
+		return fmt.Errorf("invalid access: %s", d.Access)
+	}
+	for _, field := range d.HiddenFields {
+		valid := false
+		for _, f := range exposure.Fields {
+			if f == field {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid hidden field: %s", field)
+		}
+	}
+	return nil
+}
+
+// GET /api/admin/dashboard
+func GetAllDashboards(c *gin.Context) {
+	list, err := dashboards.GetAllDashboards()
+	if err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// POST body: models.Dashboard, password
+func AddDashboard(c *gin.Context) {
+	var req dashboardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Access == "" {
+		req.Access = models.DashboardAccessPublic
+	}
+	password := ""
+	if req.Password != nil {
+		password = *req.Password
+	}
+	if err := validateDashboard(req.Dashboard, password != ""); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := dashboards.GetDashboardBySlug(req.Slug); err == nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "dashboard.slug_exists")
+		return
+	}
+	if err := dashboards.AddDashboard(&req.Dashboard, password); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("add dashboard: %s (%s)", req.Slug, req.Access), "info")
+	api.RespondSuccess(c, gin.H{"id": req.Id, "share_token": req.ShareToken})
+}
+
+// POST body: models.Dashboard, password
+func EditDashboard(c *gin.Context) {
+	var req dashboardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	current, err := dashboards.GetDashboardById(req.Id)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusNotFound, "dashboard.not_found")
+		return
+	}
+	if req.Access == "" {
+		req.Access = current.Access
+	}
+	hasPassword := current.Password != ""
+	if req.Password != nil {
+		hasPassword = *req.Password != ""
+	}
+	if err := validateDashboard(req.Dashboard, hasPassword); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if existing, err := dashboards.GetDashboardBySlug(req.Slug); err == nil && existing.Id != req.Id {
+		api.RespondErrorT(c, http.StatusBadRequest, "dashboard.slug_exists")
+		return
+	}
+	if err := dashboards.EditDashboard(&req.Dashboard, req.Password); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("edit dashboard: %s (%s)", req.Slug, req.Access), "info")
+	api.RespondSuccess(c, nil)
+}
+
+// POST body: id []uint
+func DeleteDashboard(c *gin.Context) {
+	var req struct {
+		ID []uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := dashboards.DeleteDashboard(req.ID); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("delete dashboards: %v", req.ID), "warn")
+	api.RespondSuccess(c, nil)
+}
+
+// POST body: id uint，重新生成分享 token
+func RotateDashboardToken(c *gin.Context) {
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	token, err := dashboards.RotateShareToken(req.ID)
+	if err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("rotate dashboard share token: %d", req.ID), "warn")
+	api.RespondSuccess(c, gin.H{"share_token": token})
+}
@@ -108,14 +108,14 @@ func DownloadBackup(c *gin.Context) {
 	// 1) 创建临时目录
 	tempDir, err := os.MkdirTemp("", "komari-backup-*")
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error creating temporary directory: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.create_temp_dir_failed", err)
 		return
 	}
 	defer os.RemoveAll(tempDir)
 
 	// 2) 复制 ./data 下除 .db/.db-wal/.db-shm 外的所有文件到临时目录
 	if err := copyDataToTempExcludingDB(tempDir); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error copying data to temp: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.copy_data_failed", err)
 		return
 	}
 
@@ -125,18 +125,18 @@ func DownloadBackup(c *gin.Context) {
 
 	if flags.DatabaseType == "sqlite" || flags.DatabaseType == "" {
 		if err := backupSQLiteTo(destDB); err != nil {
-			api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error backing up sqlite database: %v", err))
+			api.RespondErrorT(c, http.StatusInternalServerError, "backup.sqlite_backup_failed", err)
 			return
 		}
 	} else if dbFilePath != "" {
 		// 非 sqlite 的情况：若配置了文件路径且存在，则直接复制（按用户需求仍然将名称固定为 komari.db）
 		if _, err := os.Stat(dbFilePath); err == nil {
 			if err := copyFile(dbFilePath, destDB); err != nil {
-				api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error copying database file: %v", err))
+				api.RespondErrorT(c, http.StatusInternalServerError, "backup.copy_database_failed", err)
 				return
 			}
 		} else if !os.IsNotExist(err) {
-			api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error stating database file: %v", err))
+			api.RespondErrorT(c, http.StatusInternalServerError, "backup.stat_database_failed", err)
 			return
 		}
 	}
@@ -188,7 +188,7 @@ func DownloadBackup(c *gin.Context) {
 		return err
 	})
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error archiving temp folder: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.archive_failed", err)
 		return
 	}
 
@@ -200,11 +200,11 @@ func DownloadBackup(c *gin.Context) {
 		Modified: time.Now(),
 	})
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error creating backup markup file: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.create_markup_failed", err)
 		return
 	}
 	if _, err = markupWriter.Write([]byte(markupContent)); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error writing backup markup file: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.write_markup_failed", err)
 		return
 	}
 }
@@ -2,15 +2,21 @@ package admin
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
+	clipboardDB "github.com/komari-monitor/komari/database/clipboard"
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/database/tasks"
 	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/snippet"
 	"github.com/komari-monitor/komari/ws"
 )
 
@@ -25,7 +31,7 @@ func Exec(c *gin.Context) {
 	var onlineClients []string
 	var offlineClients []string
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, 400, "Invalid or missing request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
 		return
 	}
 	// for uuid := range ws.GetConnectedClients() {
@@ -33,26 +39,34 @@ func Exec(c *gin.Context) {
 	// 		onlineClients = append(onlineClients, uuid)
 	// 	}
 	// 	// else {
-	// 	// 	api.RespondError(c, 400, "Client not connected: "+uuid)
+	// 	// 	api.RespondErrorT(c, 400, "exec.client_not_connected", uuid)
 	// 	// 	return
 	// 	// }
 	// }
-	for _, uuid := range req.Clients {
-		if client := ws.GetConnectedClients()[uuid]; client != nil {
-			onlineClients = append(onlineClients, uuid)
-		} else {
-			offlineClients = append(offlineClients, uuid)
-		}
-	}
+	onlineClients, offlineClients = splitOnline(req.Clients)
 	if len(onlineClients) == 0 {
-		api.RespondError(c, 400, "No clients connected")
+		api.RespondErrorT(c, 400, "exec.no_clients_connected")
 		return
 	}
 	taskId := utils.GenerateRandomString(16)
 	if err := tasks.CreateTask(taskId, append(onlineClients, offlineClients...), req.Command); err != nil {
-		api.RespondError(c, 500, "Failed to create task: "+err.Error())
+		api.RespondErrorT(c, 500, "exec.create_task_failed", err)
+		return
+	}
+	if !dispatchExec(c, taskId, onlineClients, offlineClients, func(string) string { return req.Command }) {
 		return
 	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), "REC, task id: "+taskId, "warn")
+	api.RespondSuccess(c, gin.H{
+		"task_id": taskId,
+		"clients": onlineClients,
+	})
+}
+
+// dispatchExec 向在线客户端下发命令，并将离线客户端的结果记为离线。
+// 发送失败时直接返回错误响应并返回 false。
+func dispatchExec(c *gin.Context, taskId string, onlineClients, offlineClients []string, command func(uuid string) string) bool {
 	for _, uuid := range onlineClients {
 		var send struct {
 			Message string `json:"message"`
@@ -60,32 +74,111 @@ func Exec(c *gin.Context) {
 			TaskId  string `json:"task_id"`
 		}
 		send.Message = "exec"
-		send.Command = req.Command
+		send.Command = command(uuid)
 		send.TaskId = taskId
 
 		payload, _ := json.Marshal(send)
 		client := ws.GetConnectedClients()[uuid]
 		if client != nil {
 			if err := client.WriteMessage(websocket.TextMessage, payload); err != nil {
-				api.RespondError(c, 400, "Client connection is broke: "+uuid)
-				return
+				api.RespondErrorT(c, 400, "exec.connection_broken", uuid)
+				return false
 			}
 		} else {
-			api.RespondError(c, 400, "Client connection is null: "+uuid)
+			api.RespondErrorT(c, 400, "exec.connection_null", uuid)
+			return false
+		}
+	}
+	for _, uuid := range offlineClients {
+		tasks.SaveTaskResult(taskId, uuid, "Client offline!", -1, models.FromTime(time.Now()))
+	}
+	return true
+}
+
+// splitOnline 按连接状态划分客户端
+func splitOnline(clients []string) (online, offline []string) {
+	for _, uuid := range clients {
+		if client := ws.GetConnectedClients()[uuid]; client != nil {
+			online = append(online, uuid)
+		} else {
+			offline = append(offline, uuid)
+		}
+	}
+	return
+}
+
+// ExecSnippet 以剪贴板命令模板创建任务，按目标客户端分别渲染命令
+// 接受数据类型：
+// - clients: []string (客户端 UUID 列表)
+// - params: map[string]string 模板参数
+// - confirm: bool 模板标记为危险时必须为 true
+func ExecSnippet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_id")
+		return
+	}
+	var req struct {
+		Clients []string          `json:"clients" binding:"required"`
+		Params  map[string]string `json:"params"`
+		Confirm bool              `json:"confirm"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	cb, err := clipboardDB.GetClipboardByID(id)
+	if err != nil {
+		api.RespondErrorT(c, 404, "exec.snippet_not_found")
+		return
+	}
+	if cb.Dangerous && !req.Confirm {
+		api.RespondErrorT(c, http.StatusPreconditionRequired, "exec.snippet_confirm_required")
+		return
+	}
+	values, err := snippet.ResolveParams(cb.Params, req.Params)
+	if err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	commands := make(map[string]string, len(req.Clients))
+	for _, uuid := range req.Clients {
+		client, err := clients.GetClientByUUID(uuid)
+		if err != nil {
+			api.RespondErrorT(c, 400, "common.client_not_found_with", uuid)
+			return
+		}
+		command, err := snippet.Render(cb.Text, client, values)
+		if err != nil {
+			api.RespondErrorT(c, 400, "exec.render_snippet_failed", client.Name, err)
 			return
 		}
+		commands[uuid] = command
+	}
+	onlineClients, offlineClients := splitOnline(req.Clients)
+	if len(onlineClients) == 0 {
+		api.RespondErrorT(c, 400, "exec.no_clients_connected")
+		return
+	}
+	taskId := utils.GenerateRandomString(16)
+	if err := tasks.CreateSnippetTask(taskId, cb.Id, cb.Text, append(onlineClients, offlineClients...), commands); err != nil {
+		api.RespondErrorT(c, 500, "exec.create_task_failed", err)
+		return
+	}
+	if !dispatchExec(c, taskId, onlineClients, offlineClients, func(uuid string) string { return commands[uuid] }) {
+		return
 	}
 	uuid, _ := c.Get("uuid")
-	auditlog.Log(c.ClientIP(), uuid.(string), "REC, task id: "+taskId, "warn")
+	level := "warn"
+	if cb.Dangerous {
+		level = "error"
+	}
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("REC snippet %d (%s), task id: %s", cb.Id, cb.Name, taskId), level)
 	api.RespondSuccess(c, gin.H{
-		"task_id": taskId,
-		"clients": onlineClients,
+		"task_id":    taskId,
+		"snippet_id": cb.Id,
+		"clients":    onlineClients,
 	})
-	if len(offlineClients) > 0 {
-		for _, uuid := range offlineClients {
-			tasks.SaveTaskResult(taskId, uuid, "Client offline!", -1, models.FromTime(time.Now()))
-		}
-	}
 }
 
 // func contain(clients []string, uuid string) bool {
@@ -22,12 +22,12 @@ func GetLogs(c *gin.Context) {
 	// If conversion fails, return an error
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil || limitInt <= 0 {
-		api.RespondError(c, 400, "Invalid limit: "+limit)
+		api.RespondErrorT(c, 400, "log.invalid_limit", limit)
 		return
 	}
 	pageInt, err := strconv.Atoi(page)
 	if err != nil || pageInt <= 0 {
-		api.RespondError(c, 400, "Invalid page: "+page)
+		api.RespondErrorT(c, 400, "log.invalid_page", page)
 		return
 	}
 	db := dbcore.GetDBInstance()
@@ -37,12 +37,12 @@ func GetLogs(c *gin.Context) {
 
 	var total int64
 	if err := db.Model(&models.Log{}).Count(&total).Error; err != nil {
-		api.RespondError(c, 500, "Failed to count logs: "+err.Error())
+		api.RespondErrorT(c, 500, "log.count_failed", err)
 		return
 	}
 
 	if err := db.Order("time desc").Limit(limitInt).Offset(offset).Find(&logs).Error; err != nil {
-		api.RespondError(c, 500, "Failed to retrieve logs: "+err.Error())
+		api.RespondErrorT(c, 500, "log.list_failed", err)
 		return
 	}
 	api.RespondSuccess(c, gin.H{"logs": logs, "total": total})
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database"
+	"github.com/komari-monitor/komari/database/auditlog"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/utils/messageSender"
@@ -16,7 +17,7 @@ func GetMessageSenderProvider(c *gin.Context) {
 		// 如果指定了provider，返回单个提供者的配置
 		config, err := database.GetMessageSenderConfigByName(provider)
 		if err != nil {
-			api.RespondError(c, 404, "Provider not found: "+err.Error())
+			api.RespondErrorT(c, 404, "common.provider_not_found", err)
 			return
 		}
 		api.RespondSuccess(c, config)
@@ -25,7 +26,7 @@ func GetMessageSenderProvider(c *gin.Context) {
 	// 否则返回所有提供者的配置项模板
 	providers := factory.GetSenderConfigs()
 	if len(providers) == 0 {
-		api.RespondError(c, 404, "No message sender providers found")
+		api.RespondErrorT(c, 404, "message_sender.no_providers")
 		return
 	}
 	api.RespondSuccess(c, providers)
@@ -34,20 +35,20 @@ func GetMessageSenderProvider(c *gin.Context) {
 func SetMessageSenderProvider(c *gin.Context) {
 	var senderConfig models.MessageSenderProvider
 	if err := c.ShouldBindJSON(&senderConfig); err != nil {
-		api.RespondError(c, 400, "Invalid configuration: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_config", err)
 		return
 	}
 	if senderConfig.Name == "" {
-		api.RespondError(c, 400, "Provider name is required")
+		api.RespondErrorT(c, 400, "common.provider_name_required")
 		return
 	}
 	_, exists := factory.GetConstructor(senderConfig.Name)
 	if !exists {
-		api.RespondError(c, 404, "Provider not found: "+senderConfig.Name)
+		api.RespondErrorT(c, 404, "common.provider_not_found", senderConfig.Name)
 		return
 	}
 	if err := database.SaveMessageSenderConfig(&senderConfig); err != nil {
-		api.RespondError(c, 500, "Failed to save message sender provider configuration: "+err.Error())
+		api.RespondErrorT(c, 500, "message_sender.save_provider_failed", err)
 		return
 	}
 	cfg, _ := config.Get()
@@ -55,9 +56,73 @@ func SetMessageSenderProvider(c *gin.Context) {
 	if cfg.NotificationMethod == senderConfig.Name {
 		err := messageSender.LoadProvider(senderConfig.Name, senderConfig.Addition)
 		if err != nil {
-			api.RespondError(c, 500, "Failed to load message sender provider: "+err.Error())
+			api.RespondErrorT(c, 500, "message_sender.load_provider_failed", err)
 			return
 		}
 	}
 	api.RespondSuccess(c, gin.H{"message": "Message sender provider set successfully"})
 }
+
+func ListNotificationTemplates(c *gin.Context) {
+	templates, err := database.GetAllNotificationTemplates()
+	if err != nil {
+		api.RespondErrorT(c, 500, "message_sender.get_templates_failed", err)
+		return
+	}
+	api.RespondSuccess(c, templates)
+}
+
+// POST body: id uint(可选), event string, channel string, format string, template string
+func SaveNotificationTemplate(c *gin.Context) {
+	var tmpl models.NotificationTemplate
+	if err := c.ShouldBindJSON(&tmpl); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if tmpl.Event == "" && tmpl.Channel == "" {
+		api.RespondErrorT(c, 400, "message_sender.template_scope_required")
+		return
+	}
+	if tmpl.Channel != "" {
+		if _, exists := factory.GetConstructor(tmpl.Channel); !exists {
+			api.RespondErrorT(c, 404, "common.provider_not_found", tmpl.Channel)
+			return
+		}
+	}
+	if tmpl.Format == "" {
+		tmpl.Format = messageSender.FormatText
+	}
+	if !messageSender.ValidFormat(tmpl.Format) {
+		api.RespondErrorT(c, 400, "common.invalid_format", tmpl.Format)
+		return
+	}
+	if err := messageSender.ParseTemplate(tmpl.Template); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_template", err)
+		return
+	}
+	if err := database.SaveNotificationTemplate(&tmpl); err != nil {
+		api.RespondErrorT(c, 500, "message_sender.save_template_failed", err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), "save notification template: "+tmpl.Event+"/"+tmpl.Channel, "info")
+	api.RespondSuccess(c, tmpl)
+}
+
+// POST body: id []uint
+func DeleteNotificationTemplates(c *gin.Context) {
+	var req struct {
+		ID []uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if err := database.DeleteNotificationTemplates(req.ID); err != nil {
+		api.RespondErrorT(c, 500, "message_sender.delete_templates_failed", err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), "delete notification templates", "warn")
+	api.RespondSuccess(c, nil)
+}
@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/notifier"
+	"gorm.io/gorm/clause"
+)
+
+func ListExpireNotifications(c *gin.Context) {
+	var notifications []models.ExpireNotification
+	if err := dbcore.GetDBInstance().Find(&notifications).Error; err != nil {
+		api.RespondErrorT(c, 500, "notification.expire_list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, notifications)
+}
+
+// POST body: []models.ExpireNotification
+func EditExpireNotification(c *gin.Context) {
+	var notifications []models.ExpireNotification
+	if err := c.ShouldBindJSON(&notifications); err != nil || len(notifications) == 0 {
+		api.RespondErrorT(c, 400, "common.invalid_request_body")
+		return
+	}
+	for _, n := range notifications {
+		if n.Client == "" {
+			api.RespondErrorT(c, 400, "notification.client_required")
+			return
+		}
+		if _, err := notifier.ParseLeadDays(n.LeadDays); err != nil {
+			api.RespondErrorOf(c, 400, err)
+			return
+		}
+	}
+	err := dbcore.GetDBInstance().Model(&models.ExpireNotification{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "client"}},
+			DoUpdates: clause.AssignmentColumns([]string{"disable", "lead_days"}),
+		}).
+		Select("client", "disable", "lead_days").
+		Create(&notifications).Error
+	if err != nil {
+		api.RespondErrorT(c, 500, "notification.expire_update_failed", err)
+		return
+	}
+	api.RespondSuccess(c, nil)
+}
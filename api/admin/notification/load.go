@@ -21,21 +21,21 @@ func AddLoadNotification(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if req.Interval > 4*60 || req.Interval <= 0 {
-		api.RespondError(c, http.StatusBadRequest, "Interval must be between 1 and 240 minutes")
+		api.RespondErrorT(c, http.StatusBadRequest, "notification.load_interval_invalid")
 		return
 	}
 	if req.Ratio <= 0 || req.Ratio > 1 {
-		api.RespondError(c, http.StatusBadRequest, "Ratio must be between 0 and 1")
+		api.RespondErrorT(c, http.StatusBadRequest, "notification.load_ratio_invalid")
 		return
 	}
 
 	if taskID, err := notification.AddLoadNotification(req.Clients, req.Name, req.Metric, req.Threshold, req.Ratio, req.Interval); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		api.RespondSuccess(c, gin.H{"task_id": taskID})
 	}
@@ -47,12 +47,12 @@ func DeleteLoadNotification(c *gin.Context) {
 		ID []uint `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := notification.DeleteLoadNotification(req.ID); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		api.RespondSuccess(c, nil)
 	}
@@ -65,12 +65,12 @@ func EditLoadNotification(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid request data")
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_data")
 		return
 	}
 
 	if err := notification.EditLoadNotification(req.Notifications); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		// for _, notification := range req.Notifications {
 		// 	notification.DeleteLoadNotification([]uint{notification.Id})
@@ -82,7 +82,7 @@ func EditLoadNotification(c *gin.Context) {
 func GetAllLoadNotifications(c *gin.Context) {
 	notifications, err := notification.GetAllLoadNotifications()
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 		return
 	}
 
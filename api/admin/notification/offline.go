@@ -12,7 +12,7 @@ import (
 func EnableOfflineNotification(c *gin.Context) {
 	var uuids []string
 	if err := c.ShouldBindJSON(&uuids); err != nil {
-		api.RespondError(c, 400, "Invalid request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
 		return
 	}
 	var notifications []models.OfflineNotification
@@ -30,7 +30,7 @@ func EnableOfflineNotification(c *gin.Context) {
 		Select("client", "enable").
 		Create(notifications).Error
 	if err != nil {
-		api.RespondError(c, 500, "Failed to enable offline notifications: "+err.Error())
+		api.RespondErrorT(c, 500, "notification.offline_enable_failed", err)
 		return
 	}
 	api.RespondSuccess(c, nil)
@@ -40,7 +40,7 @@ func EnableOfflineNotification(c *gin.Context) {
 func DisableOfflineNotification(c *gin.Context) {
 	var uuids []string
 	if err := c.ShouldBindJSON(&uuids); err != nil {
-		api.RespondError(c, 400, "Invalid request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
 		return
 	}
 	var notifications []models.OfflineNotification
@@ -58,7 +58,7 @@ func DisableOfflineNotification(c *gin.Context) {
 		Select("client", "enable").
 		Create(notifications).Error
 	if err != nil {
-		api.RespondError(c, 500, "Failed to disable offline notifications: "+err.Error())
+		api.RespondErrorT(c, 500, "notification.offline_disable_failed", err)
 		return
 	}
 	api.RespondSuccess(c, nil)
@@ -67,20 +67,20 @@ func DisableOfflineNotification(c *gin.Context) {
 func EditOfflineNotification(c *gin.Context) {
 	var notifications []models.OfflineNotification
 	if err := c.ShouldBindJSON(&notifications); err != nil {
-		api.RespondError(c, 400, "Invalid request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
 		return
 	}
 	if len(notifications) == 0 {
-		api.RespondError(c, 400, "At least one notification is required")
+		api.RespondErrorT(c, 400, "notification.offline_required")
 		return
 	}
 	for _, noti := range notifications {
 		if noti.Client == "" {
-			api.RespondError(c, 400, "Client UUID cannot be empty")
+			api.RespondErrorT(c, 400, "notification.client_uuid_empty")
 			return
 		}
 		if noti.GracePeriod <= 0 {
-			api.RespondError(c, 400, "GracePeriod must be a positive integer")
+			api.RespondErrorT(c, 400, "notification.grace_period_invalid")
 			return
 		}
 	}
@@ -92,7 +92,7 @@ func EditOfflineNotification(c *gin.Context) {
 		Select("*").
 		Create(notifications).Error
 	if err != nil {
-		api.RespondError(c, 500, "Failed to edit offline notifications: "+err.Error())
+		api.RespondErrorT(c, 500, "notification.offline_edit_failed", err)
 		return
 	}
 	api.RespondSuccess(c, nil)
@@ -102,7 +102,7 @@ func ListOfflineNotifications(c *gin.Context) {
 	var notifications []models.OfflineNotification
 	err := dbcore.GetDBInstance().Model(&models.OfflineNotification{}).Find(&notifications).Error
 	if err != nil {
-		api.RespondError(c, 500, "Failed to list offline notifications: "+err.Error())
+		api.RespondErrorT(c, 500, "notification.offline_list_failed", err)
 		return
 	}
 	api.RespondSuccess(c, notifications)
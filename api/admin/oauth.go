@@ -15,7 +15,7 @@ func BindingExternalAccount(c *gin.Context) {
 	session, _ := c.Cookie("session_token")
 	user, err := accounts.GetUserBySession(session)
 	if err != nil {
-		api.RespondError(c, 500, "No user found: "+err.Error())
+		api.RespondErrorT(c, 500, "oauth.user_not_found", err)
 		return
 	}
 
@@ -26,13 +26,13 @@ func UnbindExternalAccount(c *gin.Context) {
 	session, _ := c.Cookie("session_token")
 	user, err := accounts.GetUserBySession(session)
 	if err != nil {
-		api.RespondError(c, 500, "No user found: "+err.Error())
+		api.RespondErrorT(c, 500, "oauth.user_not_found", err)
 		return
 	}
 
 	err = accounts.UnbindExternalAccount(user.UUID)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to unbind external account: "+err.Error())
+		api.RespondErrorT(c, 500, "oauth.unbind_failed", err)
 		return
 	}
 
@@ -45,7 +45,7 @@ func GetOidcProvider(c *gin.Context) {
 		// 如果指定了provider，返回单个提供者的配置
 		config, err := database.GetOidcConfigByName(provider)
 		if err != nil {
-			api.RespondError(c, 404, "Provider not found: "+err.Error())
+			api.RespondErrorT(c, 404, "common.provider_not_found", err)
 			return
 		}
 		api.RespondSuccess(c, config)
@@ -54,7 +54,7 @@ func GetOidcProvider(c *gin.Context) {
 	// 否则返回所有提供者的配置
 	providers := factory.GetProviderConfigs()
 	if len(providers) == 0 {
-		api.RespondError(c, 404, "No OIDC providers found")
+		api.RespondErrorT(c, 404, "oauth.no_providers")
 		return
 	}
 	api.RespondSuccess(c, providers)
@@ -63,21 +63,21 @@ func GetOidcProvider(c *gin.Context) {
 func SetOidcProvider(c *gin.Context) {
 	var oidcConfig models.OidcProvider
 	if err := c.ShouldBindJSON(&oidcConfig); err != nil {
-		api.RespondError(c, 400, "Invalid configuration: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_config", err)
 		return
 	}
 	if oidcConfig.Name == "" {
-		api.RespondError(c, 400, "Provider name is required")
+		api.RespondErrorT(c, 400, "common.provider_name_required")
 		return
 	}
 	_, exists := factory.GetConstructor(oidcConfig.Name)
 	if !exists {
-		api.RespondError(c, 404, "Provider not found: "+oidcConfig.Name)
+		api.RespondErrorT(c, 404, "common.provider_not_found", oidcConfig.Name)
 		return
 	}
 
 	if err := database.SaveOidcConfig(&oidcConfig); err != nil {
-		api.RespondError(c, 500, "Failed to save OIDC provider configuration: "+err.Error())
+		api.RespondErrorT(c, 500, "oauth.save_provider_failed", err)
 		return
 	}
 	cfg, _ := config.Get()
@@ -85,7 +85,7 @@ func SetOidcProvider(c *gin.Context) {
 	if cfg.OAuthProvider == oidcConfig.Name {
 		err := oauth.LoadProvider(oidcConfig.Name, oidcConfig.Addition)
 		if err != nil {
-			api.RespondError(c, 500, "Failed to load OIDC provider: "+err.Error())
+			api.RespondErrorT(c, 500, "oauth.load_provider_failed", err)
 			return
 		}
 	}
@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/accounts"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/webauthn"
+)
+
+// POST /api/admin/2fa/passkey/register/begin
+func BeginPasskeyRegistration(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	user, err := accounts.GetUserByUUID(uuid.(string))
+	if err != nil {
+		api.RespondErrorT(c, 404, "user.not_found")
+		return
+	}
+	sessionID, challenge, err := webauthn.Begin(user.UUID)
+	if err != nil {
+		api.RespondErrorT(c, 500, "passkey.create_challenge_failed", err)
+		return
+	}
+	existing, _ := accounts.GetWebAuthnCredentials(user.UUID)
+	exclude := make([]gin.H, 0, len(existing))
+	for _, cred := range existing {
+		exclude = append(exclude, gin.H{"type": "public-key", "id": cred.CredentialID})
+	}
+	params := make([]gin.H, 0, len(webauthn.SupportedAlgorithms))
+	for _, alg := range webauthn.SupportedAlgorithms {
+		params = append(params, gin.H{"type": "public-key", "alg": alg})
+	}
+	rp := api.GetRelyingParty(c)
+	api.RespondSuccess(c, gin.H{
+		"session_id": sessionID,
+		"public_key": gin.H{
+			"rp": gin.H{"id": rp.RPID, "name": "Komari"},
+			"user": gin.H{
+				"id":          base64.RawURLEncoding.EncodeToString([]byte(user.UUID)),
+				"name":        user.Username,
+				"displayName": user.Username,
+			},
+			"challenge":          base64.RawURLEncoding.EncodeToString(challenge),
+			"pubKeyCredParams":   params,
+			"timeout":            webauthn.Timeout.Milliseconds(),
+			"attestation":        "none",
+			"excludeCredentials": exclude,
+			"authenticatorSelection": gin.H{
+				"residentKey":        "required",
+				"requireResidentKey": true,
+				"userVerification":   "required",
+			},
+		},
+	})
+}
+
+// POST /api/admin/2fa/passkey/register/finish
+func FinishPasskeyRegistration(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	var req api.PasskeyFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	owner, challenge, ok := webauthn.Finish(req.SessionID)
+	if !ok || owner != uuid.(string) {
+		api.RespondErrorT(c, 400, "passkey.session_expired")
+		return
+	}
+	clientData, err1 := api.DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	attestation, err2 := api.DecodeBase64URL(req.Credential.Response.AttestationObject)
+	if err1 != nil || err2 != nil {
+		api.RespondErrorT(c, 400, "passkey.invalid_encoding")
+		return
+	}
+	cred, err := webauthn.VerifyRegistration(api.GetRelyingParty(c), challenge, clientData, attestation)
+	if err != nil {
+		api.RespondErrorT(c, 400, "passkey.verify_failed_with", err)
+		return
+	}
+	name := req.Name
+	if name == "" {
+		name = "Passkey"
+	}
+	record := models.WebAuthnCredential{
+		UserUUID:     owner,
+		CredentialID: base64.RawURLEncoding.EncodeToString(cred.ID),
+		PublicKey:    cred.PublicKey,
+		SignCount:    cred.SignCount,
+		Name:         name,
+	}
+	if err := accounts.AddWebAuthnCredential(&record); err != nil {
+		api.RespondErrorT(c, 500, "passkey.save_failed", err)
+		return
+	}
+	auditlog.Log(c.ClientIP(), owner, fmt.Sprintf("registered passkey: %s", name), "info")
+	api.RespondSuccess(c, record)
+}
+
+// GET /api/admin/2fa/passkey
+func ListPasskeys(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	creds, err := accounts.GetWebAuthnCredentials(uuid.(string))
+	if err != nil {
+		api.RespondErrorT(c, 500, "passkey.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, creds)
+}
+
+// POST /api/admin/2fa/passkey/edit
+func EditPasskey(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	var req struct {
+		Id   uint   `json:"id" binding:"required"`
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if err := accounts.RenameWebAuthnCredential(uuid.(string), req.Id, req.Name); err != nil {
+		api.RespondErrorT(c, 500, "passkey.rename_failed", err)
+		return
+	}
+	api.RespondSuccess(c, nil)
+}
+
+// POST /api/admin/2fa/passkey/remove
+func RemovePasskey(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	var req struct {
+		Id uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if err := accounts.DeleteWebAuthnCredential(uuid.(string), req.Id); err != nil {
+		api.RespondErrorT(c, 500, "passkey.remove_failed", err)
+		return
+	}
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("removed passkey: %d", req.Id), "warn")
+	api.RespondSuccess(c, nil)
+}
+
+// POST /api/admin/2fa/recovery/generate
+// 生成的恢复码只返回这一次，旧的恢复码全部失效
+func GenerateRecoveryCodes(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	codes, err := accounts.GenerateRecoveryCodes(uuid.(string))
+	if err != nil {
+		api.RespondErrorT(c, 500, "passkey.generate_recovery_codes_failed", err)
+		return
+	}
+	auditlog.Log(c.ClientIP(), uuid.(string), "generated new recovery codes", "warn")
+	api.RespondSuccess(c, codes)
+}
+
+// GET /api/admin/2fa/recovery
+func GetRecoveryCodeStatus(c *gin.Context) {
+	uuid, _ := c.Get("uuid")
+	count, err := accounts.CountRecoveryCodes(uuid.(string))
+	if err != nil {
+		api.RespondErrorT(c, 500, "passkey.count_recovery_codes_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{"remaining": count})
+}
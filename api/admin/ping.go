@@ -1,7 +1,9 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
@@ -10,6 +12,7 @@ import (
 )
 
 // POST body: clients []string, target, task_type string, interval int
+// 可选：count, timeout, ip_family, http_method, expected_status, alert_loss, alert_p95, alert_window
 func AddPingTask(c *gin.Context) {
 	var req struct {
 		Clients  []string `json:"clients" binding:"required"`
@@ -17,15 +20,44 @@ func AddPingTask(c *gin.Context) {
 		Target   string   `json:"target" binding:"required"`
 		TaskType string   `json:"type" binding:"required"`     // icmp, tcp, http
 		Interval int      `json:"interval" binding:"required"` // 间隔时间，单位秒
+
+		Count          int     `json:"count"`
+		Timeout        int     `json:"timeout"`
+		IPFamily       string  `json:"ip_family"`
+		HttpMethod     string  `json:"http_method"`
+		ExpectedStatus int     `json:"expected_status"`
+		AlertLoss      float64 `json:"alert_loss"`
+		AlertP95       int     `json:"alert_p95"`
+		AlertWindow    int     `json:"alert_window"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	task := models.PingTask{
+		Clients:        req.Clients,
+		Name:           req.Name,
+		Type:           req.TaskType,
+		Target:         req.Target,
+		Interval:       req.Interval,
+		Count:          req.Count,
+		Timeout:        req.Timeout,
+		IPFamily:       req.IPFamily,
+		HttpMethod:     req.HttpMethod,
+		ExpectedStatus: req.ExpectedStatus,
+		AlertLoss:      req.AlertLoss,
+		AlertP95:       req.AlertP95,
+		AlertWindow:    req.AlertWindow,
+	}
+	normalizePingTask(&task)
+	if err := validatePingTask(task); err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
-	if taskID, err := tasks.AddPingTask(req.Clients, req.Name, req.Target, req.TaskType, req.Interval); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+	if taskID, err := tasks.AddPingTaskWithOptions(task); err != nil {
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		api.RespondSuccess(c, gin.H{"task_id": taskID})
 	}
@@ -37,12 +69,12 @@ func DeletePingTask(c *gin.Context) {
 		ID []uint `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := tasks.DeletePingTask(req.ID); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		api.RespondSuccess(c, nil)
 	}
@@ -55,12 +87,20 @@ func EditPingTask(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "Invalid request data")
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_data")
 		return
 	}
 
+	for _, task := range req.Tasks {
+		normalizePingTask(task)
+		if err := validatePingTask(*task); err != nil {
+			api.RespondErrorOf(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
 	if err := tasks.EditPingTask(req.Tasks); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 	} else {
 		// for _, task := range req.Tasks {
 		// 	tasks.DeletePingRecords([]uint{task.Id})
@@ -72,9 +112,49 @@ func EditPingTask(c *gin.Context) {
 func GetAllPingTasks(c *gin.Context) {
 	tasks, err := tasks.GetAllPingTasks()
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	api.RespondSuccess(c, tasks)
 }
+
+// normalizePingTask 为未填写的包数与告警窗口填充默认值，新增与编辑共用
+func normalizePingTask(task *models.PingTask) {
+	if task.Count == 0 {
+		task.Count = 1
+	}
+	if task.AlertWindow == 0 {
+		task.AlertWindow = 5
+	}
+}
+
+// validatePingTask 校验探测参数，零值表示使用默认值
+func validatePingTask(task models.PingTask) error {
+	if task.Interval < 1 {
+		return errors.New("interval must be positive")
+	}
+	if task.Count < 1 || task.Count > 100 {
+		return errors.New("count must be between 1 and 100")
+	}
+	if task.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	switch task.IPFamily {
+	case "", "ipv4", "ipv6":
+	default:
+		return errors.New("ip_family must be empty, ipv4 or ipv6")
+	}
+	switch strings.ToUpper(task.HttpMethod) {
+	case "", http.MethodGet, http.MethodHead, http.MethodPost:
+	default:
+		return errors.New("http_method must be GET, HEAD or POST")
+	}
+	if task.ExpectedStatus != 0 && (task.ExpectedStatus < 100 || task.ExpectedStatus > 599) {
+		return errors.New("expected_status must be a valid HTTP status code")
+	}
+	if task.AlertLoss < 0 || task.AlertLoss > 100 || task.AlertP95 < 0 || task.AlertWindow < 0 {
+		return errors.New("invalid alert rule")
+	}
+	return nil
+}
@@ -12,7 +12,7 @@ func GetSessions(c *gin.Context) {
 
 	ss, err := accounts.GetAllSessions()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve sessions: "+err.Error())
+		api.RespondErrorT(c, 500, "session.list_failed", err)
 		return
 	}
 	current, _ := c.Cookie("session_token")
@@ -24,12 +24,12 @@ func DeleteSession(c *gin.Context) {
 		Session string `json:"session" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, 400, "Invalid request: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_request", err)
 		return
 	}
 	err := accounts.DeleteSession(req.Session)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to delete session: "+err.Error())
+		api.RespondErrorT(c, 500, "session.delete_failed", err)
 		return
 	}
 	uuid, _ := c.Get("uuid")
@@ -41,7 +41,7 @@ func DeleteAllSession(c *gin.Context) {
 
 	err := accounts.DeleteAllSessions()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to delete all sessions: "+err.Error())
+		api.RespondErrorT(c, 500, "session.delete_all_failed", err)
 		return
 	}
 	uuid, _ := c.Get("uuid")
@@ -2,6 +2,7 @@ package admin
 
 import (
 	"database/sql"
+	"net/url"
 
 	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/auditlog"
@@ -9,6 +10,13 @@ import (
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils/billing"
+	"github.com/komari-monitor/komari/utils/exposure"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/ipfilter"
+	"github.com/komari-monitor/komari/utils/messageSender"
+	"github.com/komari-monitor/komari/utils/notifier"
+	"github.com/komari-monitor/komari/utils/themes"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,13 +45,64 @@ func GetSettings(c *gin.Context) {
 func EditSettings(c *gin.Context) {
 	cfg := make(map[string]interface{})
 	if err := c.ShouldBindJSON(&cfg); err != nil {
-		api.RespondError(c, 400, "Invalid or missing request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
 		return
 	}
 
 	cfg["id"] = 1 // Only one record
+	// 防止管理员把自己当前的 IP 排除在管理路由之外
+	if wouldLockOutAdmin(cfg, c.ClientIP()) {
+		api.RespondErrorT(c, 400, "settings.ip_access_blocks_self")
+		return
+	}
+	if tmpl, ok := cfg["notification_template"].(string); ok {
+		if err := messageSender.ParseTemplate(tmpl); err != nil {
+			api.RespondErrorT(c, 400, "settings.invalid_notification_template", err)
+			return
+		}
+	}
+	if t, ok := cfg["expire_notification_time"].(string); ok {
+		if _, _, err := notifier.ParseCheckTime(t); err != nil {
+			api.RespondErrorOf(c, 400, err)
+			return
+		}
+	}
+	if steps, ok := cfg["expire_notification_steps"].(string); ok {
+		if _, err := notifier.ParseLeadDays(steps); err != nil {
+			api.RespondErrorOf(c, 400, err)
+			return
+		}
+	}
+	if rates, ok := cfg["exchange_rates"].(string); ok {
+		if _, err := billing.ParseRates(rates); err != nil {
+			api.RespondErrorT(c, 400, "settings.invalid_exchange_rates", err)
+			return
+		}
+	}
+	if policy, ok := cfg["exposure_policy"].(string); ok {
+		if _, err := exposure.ParseOverrides(policy); err != nil {
+			api.RespondErrorT(c, 400, "settings.invalid_exposure_policy", err)
+			return
+		}
+	}
+	if locale, ok := cfg["notification_locale"].(string); ok && !i18n.Supported(locale) {
+		api.RespondErrorT(c, 400, "common.invalid_language", locale)
+		return
+	}
+	if keys, ok := cfg["theme_trusted_keys"].(string); ok {
+		if _, err := themes.ParseTrustedKeys(keys); err != nil {
+			api.RespondErrorOf(c, 400, err)
+			return
+		}
+	}
+	if indexUrl, ok := cfg["theme_index_url"].(string); ok && indexUrl != "" {
+		if u, err := url.Parse(indexUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			api.RespondErrorT(c, 400, "settings.invalid_theme_index_url")
+			return
+		}
+	}
 	if err := config.Update(cfg); err != nil {
-		api.RespondError(c, 500, "Failed to update settings: "+err.Error())
+		api.RespondErrorT(c, 500, "settings.update_failed", err)
 		return
 	}
 
@@ -63,6 +122,24 @@ func EditSettings(c *gin.Context) {
 	api.RespondSuccess(c, nil)
 }
 
+func wouldLockOutAdmin(cfg map[string]interface{}, ip string) bool {
+	current, _ := config.Get()
+	allow, deny := current.AdminAllowCidrs, current.AdminDenyCidrs
+	if v, ok := cfg["admin_allow_cidrs"].(string); ok {
+		allow = v
+	}
+	if v, ok := cfg["admin_deny_cidrs"].(string); ok {
+		deny = v
+	}
+	allowList, err1 := ipfilter.Parse(allow)
+	denyList, err2 := ipfilter.Parse(deny)
+	if err1 != nil || err2 != nil {
+		// 格式错误交给 config.Update 报告
+		return false
+	}
+	return !ipfilter.Allowed(ip, allowList, denyList)
+}
+
 func contains(slice []string, item string) bool {
 	for _, v := range slice {
 		if v == item {
@@ -79,3 +156,17 @@ func ClearAllRecords(c *gin.Context) {
 	auditlog.Log(c.ClientIP(), uuid.(string), "clear all records", "info")
 	api.RespondSuccess(c, nil)
 }
+
+// GET /api/admin/settings/exposure 返回可配置的字段与当前生效的可见性策略
+func GetExposurePolicy(c *gin.Context) {
+	cfg, err := config.Get()
+	if err != nil {
+		api.RespondErrorT(c, 500, "common.config_read_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{
+		"fields":    exposure.Fields,
+		"audiences": []string{"guest", "viewer", "admin"},
+		"policy":    exposure.Load(cfg),
+	})
+}
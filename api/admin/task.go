@@ -9,14 +9,14 @@ import (
 func GetTasks(c *gin.Context) {
 	dbTasks, err := tasks.GetAllTasks()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve tasks: "+err.Error())
+		api.RespondErrorT(c, 500, "task.list_failed", err)
 		return
 	}
 	var responseTasks []gin.H
 	for _, t := range dbTasks {
 		results, err := tasks.GetTaskResultsByTaskId(t.TaskId)
 		if err != nil {
-			api.RespondError(c, 500, "Failed to retrieve task results: "+err.Error())
+			api.RespondErrorT(c, 500, "task.list_results_failed", err)
 			return
 		}
 
@@ -24,6 +24,7 @@ func GetTasks(c *gin.Context) {
 		for _, r := range results {
 			filteredResults = append(filteredResults, gin.H{
 				"client":      r.Client,
+				"command":     r.Command,
 				"result":      r.Result,
 				"exit_code":   r.ExitCode,
 				"finished_at": r.FinishedAt,
@@ -32,10 +33,11 @@ func GetTasks(c *gin.Context) {
 		}
 
 		responseTasks = append(responseTasks, gin.H{
-			"task_id": t.TaskId,
-			"clients": t.Clients,
-			"command": t.Command,
-			"results": filteredResults,
+			"task_id":    t.TaskId,
+			"clients":    t.Clients,
+			"command":    t.Command,
+			"snippet_id": t.Snippet,
+			"results":    filteredResults,
 		})
 	}
 	api.RespondSuccess(c, responseTasks)
@@ -44,27 +46,28 @@ func GetTasks(c *gin.Context) {
 func GetTaskById(c *gin.Context) {
 	taskId := c.Param("task_id")
 	if taskId == "" {
-		api.RespondError(c, 400, "Task ID is required")
+		api.RespondErrorT(c, 400, "task.task_id_required")
 		return
 	}
 	task, err := tasks.GetTaskByTaskId(taskId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve task: "+err.Error())
+		api.RespondErrorT(c, 500, "task.get_failed", err)
 		return
 	}
 	if task == nil {
-		api.RespondError(c, 404, "Task not found")
+		api.RespondErrorT(c, 404, "task.not_found")
 		return
 	}
 	results, err := tasks.GetTaskResultsByTaskId(taskId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve task results: "+err.Error())
+		api.RespondErrorT(c, 500, "task.list_results_failed", err)
 		return
 	}
 	var filteredResults []gin.H
 	for _, r := range results {
 		filteredResults = append(filteredResults, gin.H{
 			"client":      r.Client,
+			"command":     r.Command,
 			"result":      r.Result,
 			"exit_code":   r.ExitCode,
 			"finished_at": r.FinishedAt,
@@ -72,26 +75,27 @@ func GetTaskById(c *gin.Context) {
 		})
 	}
 	api.RespondSuccess(c, gin.H{
-		"task_id": task.TaskId,
-		"clients": task.Clients,
-		"command": task.Command,
-		"results": filteredResults,
+		"task_id":    task.TaskId,
+		"clients":    task.Clients,
+		"command":    task.Command,
+		"snippet_id": task.Snippet,
+		"results":    filteredResults,
 	})
 }
 
 func GetTasksByClientId(c *gin.Context) {
 	clientId := c.Param("uuid")
 	if clientId == "" {
-		api.RespondError(c, 400, "Client ID is required")
+		api.RespondErrorT(c, 400, "task.client_id_required")
 		return
 	}
 	tasks, err := tasks.GetTasksByClientId(clientId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve tasks: "+err.Error())
+		api.RespondErrorT(c, 500, "task.list_failed", err)
 		return
 	}
 	if len(tasks) == 0 {
-		api.RespondError(c, 404, "No tasks found for this client")
+		api.RespondErrorT(c, 404, "task.client_tasks_not_found")
 		return
 	}
 	api.RespondSuccess(c, tasks)
@@ -101,16 +105,16 @@ func GetSpecificTaskResult(c *gin.Context) {
 	taskId := c.Param("task_id")
 	clientId := c.Param("uuid")
 	if taskId == "" || clientId == "" {
-		api.RespondError(c, 400, "Task ID and Client ID are required")
+		api.RespondErrorT(c, 400, "task.task_and_client_required")
 		return
 	}
 	result, err := tasks.GetSpecificTaskResult(taskId, clientId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve task result: "+err.Error())
+		api.RespondErrorT(c, 500, "task.get_result_failed", err)
 		return
 	}
 	if result == nil {
-		api.RespondError(c, 404, "No result found for this task and client")
+		api.RespondErrorT(c, 404, "task.result_not_found")
 		return
 	}
 	api.RespondSuccess(c, result)
@@ -120,16 +124,16 @@ func GetSpecificTaskResult(c *gin.Context) {
 func GetTaskResultsByTaskId(c *gin.Context) {
 	taskId := c.Param("task_id")
 	if taskId == "" {
-		api.RespondError(c, 400, "Task ID is required")
+		api.RespondErrorT(c, 400, "task.task_id_required")
 		return
 	}
 	results, err := tasks.GetTaskResultsByTaskId(taskId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve task results: "+err.Error())
+		api.RespondErrorT(c, 500, "task.list_results_failed", err)
 		return
 	}
 	if len(results) == 0 {
-		api.RespondError(c, 404, "No results found for this task")
+		api.RespondErrorT(c, 404, "task.results_not_found")
 		return
 	}
 	api.RespondSuccess(c, results)
@@ -138,16 +142,16 @@ func GetTaskResultsByTaskId(c *gin.Context) {
 func GetAllTaskResultByUUID(c *gin.Context) {
 	clientId := c.Param("uuid")
 	if clientId == "" {
-		api.RespondError(c, 400, "Client ID is required")
+		api.RespondErrorT(c, 400, "task.client_id_required")
 		return
 	}
 	results, err := tasks.GetAllTasksResultByUUID(clientId)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to retrieve tasks: "+err.Error())
+		api.RespondErrorT(c, 500, "task.list_failed", err)
 		return
 	}
 	if len(results) == 0 {
-		api.RespondError(c, 404, "No tasks found for this client")
+		api.RespondErrorT(c, 404, "task.client_tasks_not_found")
 		return
 	}
 	api.RespondSuccess(c, results)
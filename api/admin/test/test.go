@@ -2,11 +2,14 @@ package test
 
 import (
 	"net"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
 	"github.com/komari-monitor/komari/utils/geoip"
 	"github.com/komari-monitor/komari/utils/messageSender"
 )
@@ -17,7 +20,7 @@ func TestSendMessage(c *gin.Context) {
 		Message: "This is a test message from Komari.",
 	})
 	if err != nil {
-		api.RespondError(c, 500, "Failed to send message: "+err.Error())
+		api.RespondErrorT(c, 500, "test.send_failed", err)
 		return
 	}
 	api.RespondSuccess(c, nil)
@@ -34,17 +37,99 @@ func TestGeoIp(c *gin.Context) {
 	}
 	conf, err := config.Get()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to get configuration: "+err.Error())
+		api.RespondErrorT(c, 500, "common.config_read_failed", err)
 		return
 	}
 	if !conf.GeoIpEnabled {
-		api.RespondError(c, 400, "GeoIP is not enabled in the configuration.")
+		api.RespondErrorT(c, 400, "test.geoip_disabled")
 		return
 	}
 	GeoIpRecord, err := geoip.GetGeoInfo(net.ParseIP(ip))
 	if err != nil {
-		api.RespondError(c, 500, "Failed to get GeoIP record: "+err.Error())
+		api.RespondErrorT(c, 500, "test.geoip_lookup_failed", err)
 		return
 	}
 	api.RespondSuccess(c, GeoIpRecord)
 }
+
+// POST body: template string, format string, event string, channel string, clients []string
+// clients 为空时使用示例客户端渲染
+func PreviewTemplate(c *gin.Context) {
+	var req struct {
+		Template string   `json:"template"`
+		Format   string   `json:"format"`
+		Event    string   `json:"event"`
+		Channel  string   `json:"channel"`
+		Message  string   `json:"message"`
+		Clients  []string `json:"clients"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_request_body_with", err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = messageSender.FormatText
+	}
+	if !messageSender.ValidFormat(req.Format) {
+		api.RespondErrorT(c, 400, "common.invalid_format", req.Format)
+		return
+	}
+	event := models.EventMessage{
+		Event:   req.Event,
+		Time:    time.Now(),
+		Message: req.Message,
+		Emoji:   "🔔",
+	}
+	if event.Event == "" {
+		event.Event = messageevent.Offline
+	}
+	if event.Message == "" {
+		event.Message = "This is a preview message from Komari."
+	}
+	for _, uuid := range req.Clients {
+		client, err := clients.GetClientByUUID(uuid)
+		if err != nil {
+			api.RespondErrorT(c, 404, "common.client_not_found_with", uuid)
+			return
+		}
+		event.Clients = append(event.Clients, client)
+	}
+	if len(event.Clients) == 0 {
+		event.Clients = []models.Client{sampleClient()}
+	}
+	if req.Template == "" {
+		cfg, err := config.Get()
+		if err != nil {
+			api.RespondErrorT(c, 500, "common.config_read_failed", err)
+			return
+		}
+		req.Template, req.Format = messageSender.ResolveTemplate(cfg, event.Event, req.Channel)
+	}
+	message, err := messageSender.RenderTemplate(req.Template, event, req.Channel)
+	if err != nil {
+		api.RespondErrorT(c, 400, "test.render_template_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{"message": message, "format": req.Format})
+}
+
+func sampleClient() models.Client {
+	return models.Client{
+		UUID:         "00000000-0000-0000-0000-000000000000",
+		Name:         "Sample Server",
+		CpuName:      "Intel(R) Xeon(R) CPU",
+		CpuCores:     4,
+		OS:           "Debian GNU/Linux 12",
+		IPv4:         "192.0.2.1",
+		IPv6:         "2001:db8::1",
+		Region:       "🇺🇸",
+		MemTotal:     8 << 30,
+		DiskTotal:    100 << 30,
+		Price:        5,
+		BillingCycle: 30,
+		Currency:     "$",
+		ExpiredAt:    models.FromTime(time.Now().AddDate(0, 1, 0)),
+		Group:        "Sample",
+		Tags:         "sample;preview",
+	}
+}
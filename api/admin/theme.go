@@ -1,9 +1,7 @@
 package admin
 
 import (
-	"archive/zip"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,9 +12,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/themes"
 )
 
 // UploadTheme 上传主题
@@ -24,60 +27,32 @@ func UploadTheme(c *gin.Context) {
 	// 读取上传的文件内容
 	data, err := io.ReadAll(c.Request.Body)
 	if err != nil || len(data) == 0 {
-		api.RespondError(c, http.StatusBadRequest, "请选择要上传的主题文件")
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.upload_empty")
 		return
 	}
 
-	// 临时文件名
-	tempFile := filepath.Join(os.TempDir(), "uploaded_theme.zip")
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "保存文件失败: "+err.Error())
-		return
+	// 签名可通过请求头或查询参数提供
+	signature := c.GetHeader("X-Theme-Signature")
+	if signature == "" {
+		signature = c.Query("signature")
 	}
-	defer os.Remove(tempFile)
-
-	// 检查文件扩展名（这里假定上传的就是zip）
-	if !strings.HasSuffix(strings.ToLower(tempFile), ".zip") {
-		api.RespondError(c, http.StatusBadRequest, "只支持ZIP格式的主题文件")
-		return
-	}
-
-	// 解压ZIP文件并验证
-	themeInfo, err := extractAndValidateTheme(tempFile)
+	themeInfo, err := installTheme(data, signature, "", "")
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
-	api.RespondSuccessMessage(c, "主题上传成功", themeInfo)
+	api.RespondSuccessMessage(c, "theme.uploaded", themeInfo)
 }
 
 // ListThemes 列出所有主题
 func ListThemes(c *gin.Context) {
-	dataDir := "./data/theme"
-
-	// 确保主题目录存在
-	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-		api.RespondSuccess(c, []models.Theme{})
-		return
-	}
-
-	entries, err := os.ReadDir(dataDir)
+	themes, err := themes.Installed()
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "读取主题目录失败: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.list_failed", err)
 		return
 	}
 
-	var themes []models.Theme
-	for _, entry := range entries {
-		if entry.IsDir() {
-			themeConfigPath := filepath.Join(dataDir, entry.Name(), "komari-theme.json")
-			if themeInfo, err := loadThemeConfig(themeConfigPath); err == nil {
-				themes = append(themes, themeInfo)
-			}
-		}
-	}
-
 	api.RespondSuccess(c, themes)
 }
 
@@ -88,37 +63,42 @@ func DeleteTheme(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_params", err)
 		return
 	}
 
 	if req.Short == "default" {
-		api.RespondError(c, http.StatusBadRequest, "默认主题不能删除")
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.default_undeletable")
 		return
 	}
 
-	themeDir := filepath.Join("./data/theme", req.Short)
+	if !themes.IsValidShort(req.Short) {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.name_invalid")
+		return
+	}
+	themeDir := filepath.Join(themes.Dir, req.Short)
 
 	// 检查主题是否存在
 	if _, err := os.Stat(themeDir); os.IsNotExist(err) {
-		api.RespondError(c, http.StatusNotFound, "主题不存在")
+		api.RespondErrorT(c, http.StatusNotFound, "theme.not_found")
 		return
 	}
 
 	// 删除主题目录
 	if err := os.RemoveAll(themeDir); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "删除主题失败: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.delete_failed", err)
 		return
 	}
+	themes.RemoveBackup(req.Short)
 
-	api.RespondSuccessMessage(c, "主题删除成功", nil)
+	api.RespondSuccessMessage(c, "theme.deleted", nil)
 }
 
 // SetTheme 设置主题
 func SetTheme(c *gin.Context) {
 	themeName := c.Query("theme")
 	if themeName == "" {
-		api.RespondError(c, http.StatusBadRequest, "主题名称不能为空")
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.name_required")
 		return
 	}
 
@@ -128,7 +108,7 @@ func SetTheme(c *gin.Context) {
 		themeConfigPath := filepath.Join(themeDir, "komari-theme.json")
 
 		if _, err := os.Stat(themeConfigPath); os.IsNotExist(err) {
-			api.RespondError(c, http.StatusNotFound, "主题不存在")
+			api.RespondErrorT(c, http.StatusNotFound, "theme.not_found")
 			return
 		}
 	}
@@ -139,150 +119,26 @@ func SetTheme(c *gin.Context) {
 	}
 
 	if err := config.Update(updateData); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "更新主题设置失败: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.set_failed", err)
 		return
 	}
 
-	api.RespondSuccessMessage(c, "主题设置成功", gin.H{"theme": themeName})
+	api.RespondSuccessMessage(c, "theme.set", gin.H{"theme": themeName})
 }
 
-// extractAndValidateTheme 解压并验证主题
-func extractAndValidateTheme(zipPath string) (models.Theme, error) {
-	var themeInfo models.Theme
-
-	// 打开ZIP文件
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return themeInfo, fmt.Errorf("无法打开ZIP文件: %v", err)
-	}
-	defer r.Close()
-
-	// 查找komari-theme.json文件
-	var themeConfigFile *zip.File
-	for _, f := range r.File {
-		if f.Name == "komari-theme.json" {
-			themeConfigFile = f
-			break
-		}
-	}
-
-	if themeConfigFile == nil {
-		return themeInfo, fmt.Errorf("主题配置文件 komari-theme.json 不存在")
-	}
-
-	// 读取主题配置
-	rc, err := themeConfigFile.Open()
+// installTheme 按站点的签名配置校验并安装主题包
+func installTheme(data []byte, signature, sha256, short string) (models.Theme, error) {
+	cfg, err := config.Get()
 	if err != nil {
-		return themeInfo, fmt.Errorf("无法读取主题配置文件: %v", err)
+		return models.Theme{}, i18n.NewError("common.config_read_failed", err)
 	}
-	defer rc.Close()
-
-	configData, err := io.ReadAll(rc)
+	opts, err := themes.OptionsFromConfig(cfg, signature)
 	if err != nil {
-		return themeInfo, fmt.Errorf("读取主题配置失败: %v", err)
-	}
-
-	if err := json.Unmarshal(configData, &themeInfo); err != nil {
-		return themeInfo, fmt.Errorf("主题配置格式错误: %v", err)
-	}
-
-	// 验证必填字段
-	if themeInfo.Name == "" || themeInfo.Short == "" {
-		return themeInfo, fmt.Errorf("主题配置缺少必填字段（name、short）")
-	}
-
-	// 验证Short字段格式（只允许字母、数字、下划线、连字符）
-	if !isValidThemeShort(themeInfo.Short) {
-		return themeInfo, fmt.Errorf("主题short字段格式无效，只允许字母、数字、下划线和连字符")
-	}
-
-	// 创建主题目录
-	themeDir := filepath.Join("./data/theme", themeInfo.Short)
-
-	// 如果目录已存在，先删除
-	if _, err := os.Stat(themeDir); err == nil {
-		if err := os.RemoveAll(themeDir); err != nil {
-			return themeInfo, fmt.Errorf("删除原有主题失败: %v", err)
-		}
+		return models.Theme{}, err
 	}
-
-	if err := os.MkdirAll(themeDir, 0755); err != nil {
-		return themeInfo, fmt.Errorf("创建主题目录失败: %v", err)
-	}
-
-	// 解压文件到主题目录
-	for _, f := range r.File {
-		path := filepath.Join(themeDir, f.Name)
-
-		// 安全检查，防止路径遍历攻击
-		if !strings.HasPrefix(path, filepath.Clean(themeDir)+string(os.PathSeparator)) {
-			continue
-		}
-
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(path, f.FileInfo().Mode())
-			continue
-		}
-
-		// 创建目录
-		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-			return themeInfo, fmt.Errorf("创建目录失败: %v", err)
-		}
-
-		// 解压文件
-		rc, err := f.Open()
-		if err != nil {
-			return themeInfo, fmt.Errorf("打开压缩文件失败: %v", err)
-		}
-
-		outFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.FileInfo().Mode())
-		if err != nil {
-			rc.Close()
-			return themeInfo, fmt.Errorf("创建文件失败: %v", err)
-		}
-
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
-
-		if err != nil {
-			return themeInfo, fmt.Errorf("解压文件失败: %v", err)
-		}
-	}
-
-	return themeInfo, nil
-}
-
-// loadThemeConfig 加载主题配置
-func loadThemeConfig(configPath string) (models.Theme, error) {
-	var themeInfo models.Theme
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return themeInfo, err
-	}
-
-	if err := json.Unmarshal(data, &themeInfo); err != nil {
-		return themeInfo, err
-	}
-
-	return themeInfo, nil
-}
-
-// isValidThemeShort 验证主题short字段格式
-func isValidThemeShort(short string) bool {
-	if short == "" || short == "default" {
-		return false
-	}
-
-	for _, r := range short {
-		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
-			(r >= '0' && r <= '9') || r == '_' || r == '-') {
-			return false
-		}
-	}
-
-	return true
+	opts.SHA256 = sha256
+	opts.Short = short
+	return themes.Install(data, opts)
 }
 
 // downloadThemeFromURL 从URL下载主题文件
@@ -290,24 +146,24 @@ func downloadThemeFromURL(url string) ([]byte, error) {
 	// 发送HTTP GET请求
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("下载主题文件失败: %v", err)
+		return nil, i18n.NewError("theme.download_failed", err)
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态码
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("下载主题文件失败，HTTP状态码: %d", resp.StatusCode)
+		return nil, i18n.NewError("theme.download_http_status", resp.StatusCode)
 	}
 
 	// 读取响应内容
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取主题文件内容失败: %v", err)
+		return nil, i18n.NewError("theme.download_read_failed", err)
 	}
 
 	// 检查文件大小
 	if len(data) == 0 {
-		return nil, errors.New("下载的主题文件为空")
+		return nil, i18n.NewError("theme.download_empty")
 	}
 
 	return data, nil
@@ -325,7 +181,7 @@ func downloadThemeFromURL(url string) ([]byte, error) {
 //   - 错误信息（如果有）
 func getGitHubReleaseDownloadURL(owner, repo string) (string, error) {
 	if owner == "" || repo == "" {
-		return "", errors.New("GitHub仓库所有者和仓库名称不能为空")
+		return "", i18n.NewError("theme.github.repo_required")
 	}
 
 	// 构建GitHub API URL
@@ -335,13 +191,13 @@ func getGitHubReleaseDownloadURL(owner, repo string) (string, error) {
 	// 发送HTTP GET请求
 	resp, err := http.Get(apiURL)
 	if err != nil {
-		return "", fmt.Errorf("获取GitHub release信息失败: %v", err)
+		return "", i18n.NewError("theme.github.release_failed", err)
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态码
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("获取GitHub release信息失败，HTTP状态码: %d", resp.StatusCode)
+		return "", i18n.NewError("theme.github.release_http_status", resp.StatusCode)
 	}
 
 	// 解析JSON响应
@@ -353,12 +209,19 @@ func getGitHubReleaseDownloadURL(owner, repo string) (string, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&releaseInfo); err != nil {
-		return "", fmt.Errorf("解析GitHub API响应失败: %v", err)
+		return "", i18n.NewError("theme.github.response_invalid", err)
 	}
 
 	// 检查是否有可下载的资源
 	if len(releaseInfo.Assets) == 0 {
-		return "", errors.New("GitHub release中没有可下载的资源")
+		return "", i18n.NewError("theme.github.no_assets")
+	}
+
+	// 优先返回 zip 资源，避免取到签名等附属文件
+	for _, asset := range releaseInfo.Assets {
+		if strings.HasSuffix(strings.ToLower(asset.BrowserDownloadURL), ".zip") {
+			return asset.BrowserDownloadURL, nil
+		}
 	}
 
 	// 返回第一个资源的下载链接
@@ -424,30 +287,35 @@ func isGitHubRepoURL(urlStr string) (bool, string, string) {
 // 4. 如果主题URL是GitHub仓库地址，自动获取最新release
 func UpdateTheme(c *gin.Context) {
 	var req struct {
-		Short    string `json:"short" binding:"required"` // 主题短名称
-		URL      string `json:"url"`                      // 新的URL地址（可选）
-		GitOwner string `json:"git_owner"`                // GitHub仓库所有者（可选）
-		GitRepo  string `json:"git_repo"`                 // GitHub仓库名称（可选）
+		Short     string `json:"short" binding:"required"` // 主题短名称
+		URL       string `json:"url"`                      // 新的URL地址（可选）
+		GitOwner  string `json:"git_owner"`                // GitHub仓库所有者（可选）
+		GitRepo   string `json:"git_repo"`                 // GitHub仓库名称（可选）
+		Signature string `json:"signature"`                // 主题包签名（可选）
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_params", err)
+		return
+	}
+
+	if !themes.IsValidShort(req.Short) {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.name_invalid")
 		return
 	}
 
 	// 检查主题是否存在
-	themeDir := filepath.Join("./data/theme", req.Short)
-	themeConfigPath := filepath.Join(themeDir, "komari-theme.json")
+	themeConfigPath := filepath.Join(themes.Dir, req.Short, "komari-theme.json")
 
 	if _, err := os.Stat(themeConfigPath); os.IsNotExist(err) {
-		api.RespondError(c, http.StatusNotFound, "主题不存在")
+		api.RespondErrorT(c, http.StatusNotFound, "theme.not_found")
 		return
 	}
 
 	// 加载现有主题配置
-	themeInfo, err := loadThemeConfig(themeConfigPath)
+	themeInfo, err := themes.LoadConfig(themeConfigPath)
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "读取主题配置失败: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.manifest_read_failed", err)
 		return
 	}
 
@@ -494,14 +362,14 @@ func UpdateTheme(c *gin.Context) {
 			// 相当于: DOWNLOAD_URL=$(curl -s https://api.github.com/repos/owner/repo/releases/latest | jq -r ".assets[0].browser_download_url")
 			gitHubURL, err := getGitHubReleaseDownloadURL(req.GitOwner, req.GitRepo)
 			if err != nil {
-				api.RespondError(c, http.StatusBadRequest, "从GitHub获取下载链接失败: "+err.Error())
+				api.RespondErrorT(c, http.StatusBadRequest, "theme.github.resolve_failed", err)
 				return
 			}
 
 			// 使用获取到的链接下载主题
 			themeData, err = downloadThemeFromURL(gitHubURL)
 			if err != nil {
-				api.RespondError(c, http.StatusBadRequest, "从GitHub下载主题失败: "+err.Error())
+				api.RespondErrorT(c, http.StatusBadRequest, "theme.github.download_failed", err)
 				return
 			}
 			// 保存下载链接，稍后更新到主题配置中
@@ -515,14 +383,14 @@ func UpdateTheme(c *gin.Context) {
 				// 这里也应用了自动检测GitHub仓库并下载最新release的功能
 				gitHubURL, err := getGitHubReleaseDownloadURL(owner, repo)
 				if err != nil {
-					api.RespondError(c, http.StatusBadRequest, "从GitHub获取下载链接失败: "+err.Error())
+					api.RespondErrorT(c, http.StatusBadRequest, "theme.github.resolve_failed", err)
 					return
 				}
 
 				// 使用获取到的链接下载主题
 				themeData, err = downloadThemeFromURL(gitHubURL)
 				if err != nil {
-					api.RespondError(c, http.StatusBadRequest, "从GitHub下载主题失败: "+err.Error())
+					api.RespondErrorT(c, http.StatusBadRequest, "theme.github.download_failed", err)
 					return
 				}
 				// 保存GitHub仓库URL，而不是release下载链接，以便将来可以获取最新版本
@@ -533,7 +401,7 @@ func UpdateTheme(c *gin.Context) {
 				// 新URL不是GitHub仓库地址，直接尝试下载
 				themeData, err = downloadThemeFromURL(req.URL)
 				if err != nil {
-					api.RespondError(c, http.StatusBadRequest, "从新URL下载主题失败: "+err.Error())
+					api.RespondErrorT(c, http.StatusBadRequest, "theme.url_download_failed", err)
 					return
 				}
 				// downloadURL = req.URL
@@ -543,7 +411,7 @@ func UpdateTheme(c *gin.Context) {
 
 	// 如果没有成功下载主题数据
 	if themeData == nil || len(themeData) == 0 {
-		api.RespondError(c, http.StatusBadRequest, "无法下载主题，请提供有效的URL或GitHub仓库信息")
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.update_source_required")
 		return
 	}
 
@@ -553,18 +421,10 @@ func UpdateTheme(c *gin.Context) {
 	// 3. 用户提供的新URL下载
 	// 4. 用户提供的GitHub仓库信息，获取最新release下载
 
-	// 临时文件名
-	tempFile := filepath.Join(os.TempDir(), "downloaded_theme.zip")
-	if err := os.WriteFile(tempFile, themeData, 0644); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "保存文件失败: "+err.Error())
-		return
-	}
-	defer os.Remove(tempFile)
-
-	// 解压ZIP文件并验证
-	updatedThemeInfo, err := extractAndValidateTheme(tempFile)
+	// 校验并安装，失败时保留原有版本
+	updatedThemeInfo, err := installTheme(themeData, req.Signature, "", req.Short)
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, err.Error())
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -573,26 +433,128 @@ func UpdateTheme(c *gin.Context) {
 	// 	updatedThemeInfo.URL = downloadURL
 
 	// 	// 更新主题配置文件
-	// 	updatedConfigPath := filepath.Join("./data/theme", updatedThemeInfo.Short, "komari-theme.json")
+	// 	updatedConfigPath := filepath.Join(themes.Dir, updatedThemeInfo.Short, "komari-theme.json")
 	// 	updatedConfigData, err := json.MarshalIndent(updatedThemeInfo, "", "  ")
 	// 	if err != nil {
-	// 		api.RespondError(c, http.StatusInternalServerError, "生成主题配置失败: "+err.Error())
+	// 		api.RespondErrorT(c, http.StatusInternalServerError, "theme.config_generate_failed", err)
 	// 		return
 	// 	}
 
 	// 	if err := os.WriteFile(updatedConfigPath, updatedConfigData, 0644); err != nil {
-	// 		api.RespondError(c, http.StatusInternalServerError, "更新主题配置文件失败: "+err.Error())
+	// 		api.RespondErrorT(c, http.StatusInternalServerError, "theme.config_write_failed", err)
 	// 		return
 	// 	}
 	// }
 
-	api.RespondSuccessMessage(c, "主题更新成功", updatedThemeInfo)
+	api.RespondSuccessMessage(c, "theme.updated", updatedThemeInfo)
+}
+
+// RollbackTheme 将主题恢复到更新前的版本
+func RollbackTheme(c *gin.Context) {
+	var req struct {
+		Short string `json:"short" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_params", err)
+		return
+	}
+	themeInfo, err := themes.Rollback(req.Short)
+	if err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("rollback theme: %s to %s", req.Short, themeInfo.Version), "warn")
+	api.RespondSuccessMessage(c, "theme.rolled_back", themeInfo)
+}
+
+// ThemeMarket 获取主题索引，并标注已安装版本、可用更新与新版本的更新记录
+func ThemeMarket(c *gin.Context) {
+	cfg, err := config.Get()
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "common.config_read_failed", err)
+		return
+	}
+	index, err := themes.FetchIndex(cfg.ThemeIndexUrl)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusBadGateway, "theme.index_fetch_failed", err)
+		return
+	}
+	installed, _ := themes.Installed()
+	versions := map[string]string{}
+	for _, t := range installed {
+		versions[t.Short] = t.Version
+	}
+
+	type marketTheme struct {
+		themes.IndexEntry
+		Installed       string             `json:"installed"` // 已安装版本，未安装为空
+		UpdateAvailable bool               `json:"update_available"`
+		Compatible      bool               `json:"compatible"`
+		Changes         []themes.Changelog `json:"changes"` // 比已安装版本新的更新记录
+		Rollback        string             `json:"rollback,omitempty"`
+	}
+	result := make([]marketTheme, 0, len(index.Themes))
+	for _, e := range index.Themes {
+		item := marketTheme{
+			IndexEntry:      e,
+			Installed:       versions[e.Short],
+			UpdateAvailable: e.HasUpdate(versions[e.Short]),
+			Compatible:      themes.CheckCompatible(models.Theme{Short: e.Short, MinVersion: e.MinVersion}) == nil,
+			Changes:         e.ChangesSince(versions[e.Short]),
+		}
+		if backup, ok := themes.Backup(e.Short); ok {
+			item.Rollback = backup.Version
+		}
+		result = append(result, item)
+	}
+	api.RespondSuccess(c, result)
+}
+
+// InstallMarketTheme 从主题索引安装或更新主题，使用索引提供的 SHA256 与签名校验
+func InstallMarketTheme(c *gin.Context) {
+	var req struct {
+		Short string `json:"short" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_params", err)
+		return
+	}
+	cfg, err := config.Get()
+	if err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "common.config_read_failed", err)
+		return
+	}
+	index, err := themes.FetchIndex(cfg.ThemeIndexUrl)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusBadGateway, "theme.index_fetch_failed", err)
+		return
+	}
+	entry, ok := index.Find(req.Short)
+	if !ok || entry.DownloadURL == "" {
+		api.RespondErrorT(c, http.StatusNotFound, "theme.index_not_found")
+		return
+	}
+	data, err := themes.Download(entry.DownloadURL)
+	if err != nil {
+		api.RespondErrorT(c, http.StatusBadGateway, "theme.download_failed", err)
+		return
+	}
+	themeInfo, err := installTheme(data, entry.Signature, entry.SHA256, entry.Short)
+	if err != nil {
+		api.RespondErrorOf(c, http.StatusBadRequest, err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("install theme from index: %s %s", themeInfo.Short, themeInfo.Version), "info")
+	api.RespondSuccessMessage(c, "theme.installed", themeInfo)
 }
 
+// UpdateThemeSettings 保存主题配置，托管配置按主题声明校验并规范化
 func UpdateThemeSettings(c *gin.Context) {
 	theme := c.Query("theme")
 	if theme == "" || theme == "default" {
-		api.RespondError(c, http.StatusBadRequest, "主题名称不能为空或不能是默认主题")
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.name_required_not_default")
 		return
 	}
 
@@ -600,20 +562,93 @@ func UpdateThemeSettings(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		api.RespondErrorT(c, http.StatusBadRequest, "common.invalid_params", err)
 		return
 	}
+	if items, ok := themes.Schema(theme); ok {
+		exists, err := clientExists()
+		if err != nil {
+			api.RespondErrorT(c, http.StatusInternalServerError, "client.get_failed", err)
+			return
+		}
+		req, err = themes.Validate(items, req, exists)
+		if err != nil {
+			api.RespondErrorOf(c, http.StatusBadRequest, err)
+			return
+		}
+	}
 	db := dbcore.GetDBInstance()
 
 	data, err := json.Marshal(&req)
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "生成主题配置失败: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.settings_encode_failed", err)
 		return
 	}
 
 	var themeCfg models.ThemeConfiguration
-	db.Where("short = ?", theme).
+	if err := db.Where("short = ?", theme).
 		Assign(models.ThemeConfiguration{Short: theme, Data: string(data)}).
-		FirstOrCreate(&themeCfg)
-	api.RespondSuccess(c, nil)
+		FirstOrCreate(&themeCfg).Error; err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "theme.settings_save_failed", err)
+		return
+	}
+	api.RespondSuccess(c, req)
+}
+
+// clientExists 返回校验客户端 UUID 是否存在的函数
+func clientExists() (func(string) bool, error) {
+	list, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(list))
+	for _, cl := range list {
+		known[cl.UUID] = true
+	}
+	return func(uuid string) bool { return known[uuid] }, nil
+}
+
+// maxThemeAssetSize 主题配置图片的大小上限
+const maxThemeAssetSize = 5 << 20
+
+var themeAssetTypes = map[string]string{
+	"image/png":    ".png",
+	"image/jpeg":   ".jpg",
+	"image/gif":    ".gif",
+	"image/webp":   ".webp",
+	"image/x-icon": ".ico",
+}
+
+// UploadThemeAsset 上传主题配置中使用的图片，返回可直接保存到 image 配置项的地址
+func UploadThemeAsset(c *gin.Context) {
+	theme := c.Query("theme")
+	if !themes.IsValidShort(theme) {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.name_invalid")
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxThemeAssetSize+1))
+	if err != nil || len(data) == 0 {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.asset_empty")
+		return
+	}
+	if len(data) > maxThemeAssetSize {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.asset_too_large")
+		return
+	}
+	ext, ok := themeAssetTypes[http.DetectContentType(data)]
+	if !ok {
+		api.RespondErrorT(c, http.StatusBadRequest, "theme.asset_type_unsupported")
+		return
+	}
+	dir := filepath.Join(themes.AssetsDir, theme)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "common.create_dir_failed", err)
+		return
+	}
+	name := utils.GenerateRandomString(16) + ext
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		api.RespondErrorT(c, http.StatusInternalServerError, "common.save_file_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{"url": "/theme-assets/" + theme + "/" + name})
 }
@@ -0,0 +1,322 @@
+package admin
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
+	transferDB "github.com/komari-monitor/komari/database/filetransfer"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/filetransfer"
+	"github.com/komari-monitor/komari/ws"
+)
+
+// startTransfer 向在线客户端下发传输任务，离线或下发失败的客户端记为失败，可稍后续传
+func startTransfer(t *models.FileTransfer, targets []string) []string {
+	var started []string
+	for _, uuid := range targets {
+		conn := ws.GetConnectedClients()[uuid]
+		if conn == nil {
+			transferDB.Finish(t.TransferId, uuid, 0, "", "Client offline!")
+			continue
+		}
+		if err := filetransfer.Start(conn, t, uuid); err != nil {
+			transferDB.Finish(t.TransferId, uuid, 0, "", err.Error())
+			continue
+		}
+		started = append(started, uuid)
+	}
+	return started
+}
+
+// checkTransferTargets 检查客户端是否存在以及路径和大小是否符合其传输限制
+func checkTransferTargets(targets []string, p string, size int64) error {
+	for _, uuid := range targets {
+		if _, err := clients.GetClientByUUID(uuid); err != nil {
+			return fmt.Errorf("client not found: %s", uuid)
+		}
+		policy, err := transferDB.GetPolicy(uuid)
+		if err != nil {
+			return err
+		}
+		if err := filetransfer.CheckPath(p, policy); err != nil {
+			return fmt.Errorf("%s: %v", uuid, err)
+		}
+		if size > filetransfer.MaxSize(policy) {
+			return fmt.Errorf("%s: file exceeds the size limit of %d bytes", uuid, filetransfer.MaxSize(policy))
+		}
+	}
+	return nil
+}
+
+// UploadFile 上传文件到一个或多个客户端
+// multipart 表单：
+// - file: 文件
+// - clients: 客户端 UUID，以逗号分隔或重复提交
+// - path: 客户端上的目标路径
+// - mode: 可选，八进制权限
+// - owner: 可选，user 或 user:group
+func UploadFile(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		api.RespondErrorT(c, 400, "transfer.missing_file", err)
+		return
+	}
+	defer file.Close()
+	var targets []string
+	for _, v := range c.PostFormArray("clients") {
+		for _, uuid := range strings.Split(v, ",") {
+			if uuid = strings.TrimSpace(uuid); uuid != "" {
+				targets = append(targets, uuid)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		api.RespondErrorT(c, 400, "transfer.no_clients")
+		return
+	}
+	target := c.PostForm("path")
+	mode := c.PostForm("mode")
+	owner := c.PostForm("owner")
+	if err := filetransfer.ValidateMode(mode); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	if err := filetransfer.ValidateOwner(owner); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	if err := checkTransferTargets(targets, target, header.Size); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+
+	transferId := utils.GenerateRandomString(16)
+	size, sum, err := filetransfer.Store(transferId, file, header.Size)
+	if err != nil {
+		filetransfer.Remove(transferId)
+		api.RespondErrorT(c, 500, "common.save_file_failed", err)
+		return
+	}
+	t := models.FileTransfer{
+		TransferId: transferId,
+		Direction:  models.TransferUpload,
+		Clients:    targets,
+		Path:       target,
+		FileName:   path.Base(header.Filename),
+		Size:       size,
+		SHA256:     sum,
+		Mode:       mode,
+		Owner:      owner,
+	}
+	if err := transferDB.CreateTransfer(t); err != nil {
+		filetransfer.Remove(transferId)
+		api.RespondErrorT(c, 500, "transfer.create_failed", err)
+		return
+	}
+	started := startTransfer(&t, targets)
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("upload file %s (%d bytes, sha256 %s) to %s on %s, transfer id: %s", t.FileName, size, sum, target, strings.Join(targets, ","), transferId), "warn")
+	api.RespondSuccess(c, gin.H{
+		"transfer_id": transferId,
+		"sha256":      sum,
+		"size":        size,
+		"clients":     started,
+	})
+}
+
+// DownloadFile 从客户端下载文件到面板
+// 接受数据类型：
+// - client: string
+// - path: string
+func DownloadFile(c *gin.Context) {
+	var req struct {
+		Client string `json:"client" binding:"required"`
+		Path   string `json:"path" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	if err := checkTransferTargets([]string{req.Client}, req.Path, 0); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	t := models.FileTransfer{
+		TransferId: utils.GenerateRandomString(16),
+		Direction:  models.TransferDownload,
+		Clients:    []string{req.Client},
+		Path:       req.Path,
+		FileName:   path.Base(strings.ReplaceAll(req.Path, `\`, "/")),
+	}
+	if err := transferDB.CreateTransfer(t); err != nil {
+		api.RespondErrorT(c, 500, "transfer.create_failed", err)
+		return
+	}
+	started := startTransfer(&t, t.Clients)
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("download file %s from %s, transfer id: %s", req.Path, req.Client, t.TransferId), "warn")
+	api.RespondSuccess(c, gin.H{
+		"transfer_id": t.TransferId,
+		"clients":     started,
+	})
+}
+
+// ListTransfers 列出文件传输任务，可通过 client 参数筛选
+func ListTransfers(c *gin.Context) {
+	list, err := transferDB.ListTransfers(c.Query("client"))
+	if err != nil {
+		api.RespondErrorT(c, 500, "transfer.list_failed", err)
+		return
+	}
+	api.RespondSuccess(c, list)
+}
+
+// GetTransfer 获取传输任务及各客户端进度
+func GetTransfer(c *gin.Context) {
+	t, err := transferDB.GetTransfer(c.Param("id"))
+	if err != nil {
+		api.RespondErrorT(c, 404, "transfer.not_found")
+		return
+	}
+	api.RespondSuccess(c, t)
+}
+
+// ResumeTransfer 对未完成的客户端重新下发传输，上传由 Agent 从本地已写入的位置继续，
+// 下载从面板已接收的位置继续
+// 接受数据类型：
+// - clients: []string 可选，为空时续传全部未完成的客户端
+func ResumeTransfer(c *gin.Context) {
+	var req struct {
+		Clients []string `json:"clients"`
+	}
+	c.ShouldBindJSON(&req)
+	t, err := transferDB.GetTransfer(c.Param("id"))
+	if err != nil {
+		api.RespondErrorT(c, 404, "transfer.not_found")
+		return
+	}
+	var targets []string
+	for _, r := range t.Results {
+		if r.Status == models.TransferSuccess {
+			continue
+		}
+		if len(req.Clients) > 0 && !contains(req.Clients, r.Client) {
+			continue
+		}
+		targets = append(targets, r.Client)
+	}
+	if len(targets) == 0 {
+		api.RespondErrorT(c, 400, "transfer.nothing_to_resume")
+		return
+	}
+	for _, uuid := range targets {
+		transferDB.Restart(t.TransferId, uuid)
+	}
+	started := startTransfer(t, targets)
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), "resume file transfer: "+t.TransferId, "warn")
+	api.RespondSuccess(c, gin.H{
+		"transfer_id": t.TransferId,
+		"clients":     started,
+	})
+}
+
+// GetTransferFile 获取从客户端下载完成的文件
+// Query: client 客户端 UUID
+func GetTransferFile(c *gin.Context) {
+	t, err := transferDB.GetTransferInfo(c.Param("id"))
+	if err != nil || t.Direction != models.TransferDownload {
+		api.RespondErrorT(c, 404, "transfer.not_found")
+		return
+	}
+	client := c.Query("client")
+	if client == "" && len(t.Clients) == 1 {
+		client = t.Clients[0]
+	}
+	result, err := transferDB.GetResult(t.TransferId, client)
+	if err != nil || result.Status != models.TransferSuccess {
+		api.RespondErrorT(c, 404, "transfer.file_unavailable")
+		return
+	}
+	name := filetransfer.DownloadedPath(t.TransferId, client)
+	if _, err := os.Stat(name); err != nil {
+		api.RespondErrorT(c, 404, "transfer.file_unavailable")
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), fmt.Sprintf("fetch downloaded file %s of %s, transfer id: %s", t.Path, client, t.TransferId), "info")
+	c.FileAttachment(name, t.FileName)
+}
+
+// DeleteTransfer 删除传输任务及暂存的文件
+func DeleteTransfer(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := transferDB.GetTransferInfo(id); err != nil {
+		api.RespondErrorT(c, 404, "transfer.not_found")
+		return
+	}
+	if err := filetransfer.Remove(id); err != nil {
+		api.RespondErrorT(c, 500, "transfer.remove_files_failed", err)
+		return
+	}
+	if err := transferDB.DeleteTransfer(id); err != nil {
+		api.RespondErrorT(c, 500, "transfer.delete_failed", err)
+		return
+	}
+	uuid, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), uuid.(string), "delete file transfer: "+id, "info")
+	api.RespondSuccess(c, nil)
+}
+
+// GetTransferPolicy 获取客户端的文件传输限制
+func GetTransferPolicy(c *gin.Context) {
+	policy, err := transferDB.GetPolicy(c.Param("uuid"))
+	if err != nil {
+		api.RespondErrorT(c, 500, "transfer.get_policy_failed", err)
+		return
+	}
+	api.RespondSuccess(c, gin.H{
+		"client":           policy.Client,
+		"disabled":         policy.Disabled,
+		"max_size":         policy.MaxSize,
+		"allowed_paths":    policy.AllowedPaths,
+		"default_max_size": filetransfer.DefaultMaxSize,
+	})
+}
+
+// EditTransferPolicy 修改客户端的文件传输限制
+// 接受数据类型：
+// - disabled: bool
+// - max_size: int64，0 表示使用默认值
+// - allowed_paths: []string，为空时不限制路径
+func EditTransferPolicy(c *gin.Context) {
+	uuid := c.Param("uuid")
+	if _, err := clients.GetClientByUUID(uuid); err != nil {
+		api.RespondErrorT(c, 404, "common.client_not_found")
+		return
+	}
+	var policy models.FileTransferPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
+		return
+	}
+	policy.Client = uuid
+	if err := filetransfer.ValidatePolicy(policy); err != nil {
+		api.RespondErrorOf(c, 400, err)
+		return
+	}
+	if err := transferDB.SavePolicy(policy); err != nil {
+		api.RespondErrorT(c, 500, "transfer.save_policy_failed", err)
+		return
+	}
+	user, _ := c.Get("uuid")
+	auditlog.Log(c.ClientIP(), user.(string), "edit file transfer policy of client: "+uuid, "warn")
+	api.RespondSuccess(c, nil)
+}
@@ -16,14 +16,14 @@ func UploadFavicon(c *gin.Context) {
 	data, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		if strings.Contains(err.Error(), "request body too large") {
-			api.RespondError(c, http.StatusRequestEntityTooLarge, "File too large. Maximum size is 5MB")
+			api.RespondErrorT(c, http.StatusRequestEntityTooLarge, "favicon.too_large")
 		} else {
-			api.RespondError(c, http.StatusBadRequest, err.Error())
+			api.RespondErrorOf(c, http.StatusBadRequest, err)
 		}
 		return
 	}
 	if err := os.WriteFile("./data/favicon.ico", data, 0644); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, "Failed to save favicon: "+err.Error())
+		api.RespondErrorT(c, http.StatusInternalServerError, "favicon.save_failed", err)
 		return
 	}
 	uuid, _ := c.Get("uuid")
@@ -34,9 +34,9 @@ func UploadFavicon(c *gin.Context) {
 func DeleteFavicon(c *gin.Context) {
 	if err := os.Remove("./data/favicon.ico"); err != nil {
 		if os.IsNotExist(err) {
-			api.RespondError(c, http.StatusNotFound, "Favicon not found")
+			api.RespondErrorT(c, http.StatusNotFound, "favicon.not_found")
 		} else {
-			api.RespondError(c, http.StatusInternalServerError, "Failed to delete favicon: "+err.Error())
+			api.RespondErrorT(c, http.StatusInternalServerError, "favicon.delete_failed", err)
 		}
 		return
 	}
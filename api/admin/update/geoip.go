@@ -9,7 +9,7 @@ import (
 
 func UpdateMmdbGeoIP(c *gin.Context) {
 	if err := geoip.UpdateDatabase(); err != nil {
-		api.RespondError(c, 500, "Failed to update GeoIP database "+err.Error())
+		api.RespondErrorT(c, 500, "geoip.update_failed", err)
 		return
 	}
 	uuid, _ := c.Get("uuid")
@@ -15,23 +15,23 @@ func UpdateUser(c *gin.Context) {
 		SsoType  *string `json:"sso_type"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		api.RespondError(c, 400, "Invalid or missing request body: "+err.Error())
+		api.RespondErrorT(c, 400, "common.invalid_body", err)
 		return
 	}
 	if req.Password == nil && req.Name == nil {
-		api.RespondError(c, 400, "At least one field (username or password) must be provided")
+		api.RespondErrorT(c, 400, "user.update_field_required")
 		return
 	}
 	if req.Name != nil && len(*req.Name) < 3 {
-		api.RespondError(c, 400, "Username must be at least 3 characters long")
+		api.RespondErrorT(c, 400, "user.username_too_short")
 		return
 	}
 	if req.Password != nil && len(*req.Password) < 6 {
-		api.RespondError(c, 400, "Password must be at least 6 characters long")
+		api.RespondErrorT(c, 400, "user.password_too_short")
 		return
 	}
 	if err := accounts.UpdateUser(req.Uuid, req.Name, req.Password, req.SsoType); err != nil {
-		api.RespondError(c, 500, "Failed to update user: "+err.Error())
+		api.RespondErrorT(c, 500, "user.update_failed", err)
 		return
 	}
 	uuid, _ := c.Get("uuid")
@@ -2,7 +2,6 @@ package admin
 
 import (
 	"archive/zip"
-	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -23,7 +22,7 @@ var restoreMutex sync.Mutex
 func UploadBackup(c *gin.Context) {
 	// 尝试获取锁，如果已有恢复操作在进行，则立即返回错误
 	if !restoreMutex.TryLock() {
-		api.RespondError(c, http.StatusConflict, "Another restore operation is already in progress")
+		api.RespondErrorT(c, http.StatusConflict, "backup.restore_in_progress")
 		return
 	}
 	defer restoreMutex.Unlock()
@@ -31,27 +30,27 @@ func UploadBackup(c *gin.Context) {
 	// 获取上传的文件
 	file, header, err := c.Request.FormFile("backup")
 	if err != nil {
-		api.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Error getting uploaded file: %v", err))
+		api.RespondErrorT(c, http.StatusBadRequest, "backup.get_upload_failed", err)
 		return
 	}
 	defer file.Close()
 
 	// 检查文件是否为zip格式
 	if !strings.HasSuffix(strings.ToLower(header.Filename), ".zip") {
-		api.RespondError(c, http.StatusBadRequest, "Uploaded file must be a ZIP archive")
+		api.RespondErrorT(c, http.StatusBadRequest, "backup.zip_required")
 		return
 	}
 
 	// 确保data目录存在
 	if err := os.MkdirAll("./data", 0755); err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error creating data directory: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.create_data_dir_failed", err)
 		return
 	}
 
 	// 创建临时文件保存上传的zip（先校验，再落地到固定位置）
 	tempFile, err := os.CreateTemp("", "backup-upload-*.zip")
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error creating temporary file: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.create_temp_file_failed", err)
 		return
 	}
 	tempFilePath := tempFile.Name()
@@ -61,7 +60,7 @@ func UploadBackup(c *gin.Context) {
 	_, err = io.Copy(tempFile, file)
 	if err != nil {
 		tempFile.Close()
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error saving uploaded file: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.save_upload_failed", err)
 		return
 	}
 	tempFile.Close() // 关闭文件以便后续操作
@@ -77,11 +76,11 @@ func UploadBackup(c *gin.Context) {
 		}
 		zr.Close()
 		if !hasMarkup {
-			api.RespondError(c, http.StatusBadRequest, "Invalid backup file: missing komari-backup-markup file")
+			api.RespondErrorT(c, http.StatusBadRequest, "backup.markup_missing")
 			return
 		}
 	} else {
-		api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error opening zip file: %v", err))
+		api.RespondErrorT(c, http.StatusInternalServerError, "backup.open_zip_failed", err)
 		return
 	}
 
@@ -93,18 +92,18 @@ func UploadBackup(c *gin.Context) {
 		// fallback：拷贝
 		in, err2 := os.Open(tempFilePath)
 		if err2 != nil {
-			api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error preparing backup file: %v", err))
+			api.RespondErrorT(c, http.StatusInternalServerError, "backup.prepare_failed", err)
 			return
 		}
 		defer in.Close()
 		out, err2 := os.Create(finalPath)
 		if err2 != nil {
-			api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error creating target backup file: %v", err2))
+			api.RespondErrorT(c, http.StatusInternalServerError, "backup.create_target_failed", err2)
 			return
 		}
 		if _, err2 = io.Copy(out, in); err2 != nil {
 			out.Close()
-			api.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("Error writing target backup file: %v", err2))
+			api.RespondErrorT(c, http.StatusInternalServerError, "backup.write_target_failed", err2)
 			return
 		}
 		out.Close()
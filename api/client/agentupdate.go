@@ -0,0 +1,31 @@
+package client
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/komari-monitor/komari/database/agentupdate"
+	"github.com/komari-monitor/komari/utils/agentupdate"
+)
+
+// DownloadAgentBinary 供 Agent 下载升级用的二进制文件
+func DownloadAgentBinary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"status": "error", "error": "Invalid ID"})
+		return
+	}
+	b, err := db.GetBinary(uint(id))
+	if err != nil {
+		c.JSON(404, gin.H{"status": "error", "error": "Binary not found"})
+		return
+	}
+	name := agentupdate.BinaryPath(*b)
+	if _, err := os.Stat(name); err != nil {
+		c.JSON(404, gin.H{"status": "error", "error": "Binary not found"})
+		return
+	}
+	c.Header("X-Checksum-Sha256", b.SHA256)
+	c.FileAttachment(name, "komari-agent-"+b.OS+"-"+b.Arch)
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/cmd/flags"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/utils"
@@ -11,12 +12,12 @@ import (
 func RegisterClient(c *gin.Context) {
 	auth := c.GetHeader("Authorization")
 	if auth == "" {
-		api.RespondError(c, 403, "Invalid AutoDiscovery Key")
+		api.RespondErrorT(c, 403, "auto_discovery.invalid_key")
 		return
 	}
 	cfg, err := config.Get()
 	if err != nil {
-		api.RespondError(c, 500, "Failed to get configuration: "+err.Error())
+		api.RespondErrorT(c, 500, "common.config_read_failed", err)
 		return
 	}
 
@@ -24,7 +25,7 @@ func RegisterClient(c *gin.Context) {
 		len(cfg.AutoDiscoveryKey) < 12 ||
 		"Bearer "+cfg.AutoDiscoveryKey != auth {
 
-		api.RespondError(c, 403, "Invalid AutoDiscovery Key")
+		api.RespondErrorT(c, 403, "auto_discovery.invalid_key")
 		return
 	}
 	name := c.Query("name")
@@ -34,8 +35,17 @@ func RegisterClient(c *gin.Context) {
 	name = "Auto-" + name
 	uuid, token, err := clients.CreateClientWithName(name)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to create client: "+err.Error())
+		api.RespondErrorT(c, 500, "auto_discovery.create_client_failed", err)
 		return
 	}
-	api.RespondSuccess(c, gin.H{"uuid": uuid, "token": token})
+	resp := gin.H{"uuid": uuid, "token": token}
+	if flags.AgentMTLS {
+		issued, err := clients.IssueClientCertificate(uuid)
+		if err != nil {
+			api.RespondErrorT(c, 500, "auto_discovery.issue_certificate_failed", err)
+			return
+		}
+		resp["tls"] = certificateResponse(issued)
+	}
+	api.RespondSuccess(c, resp)
 }
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/cmd/flags"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/utils/agentca"
+)
+
+// GET /api/clients/ca
+func GetAgentCA(c *gin.Context) {
+	if !flags.AgentMTLS {
+		api.RespondErrorT(c, 404, "certificate.mtls_disabled")
+		return
+	}
+	pem, err := agentca.CertificatePEM()
+	if err != nil {
+		api.RespondErrorT(c, 500, "certificate.load_ca_failed", err)
+		return
+	}
+	c.Data(200, "application/x-pem-file", pem)
+}
+
+// GET /api/clients/crl
+func GetAgentCRL(c *gin.Context) {
+	if !flags.AgentMTLS {
+		api.RespondErrorT(c, 404, "certificate.mtls_disabled")
+		return
+	}
+	crl, err := clients.GetCertificateRevocationList()
+	if err != nil {
+		api.RespondErrorT(c, 500, "certificate.crl_failed", err)
+		return
+	}
+	c.Data(200, "application/x-pem-file", crl)
+}
+
+// POST /api/clients/certificate
+// Agent 使用 token 或仍然有效的证书换取新证书，旧证书在过期前继续有效
+func RenewCertificate(c *gin.Context) {
+	if !flags.AgentMTLS {
+		api.RespondErrorT(c, 404, "certificate.mtls_disabled")
+		return
+	}
+	uuid, err := api.GetClientUUID(c)
+	if err != nil || uuid == "" {
+		api.RespondErrorT(c, 400, "certificate.invalid_token")
+		return
+	}
+	issued, err := clients.IssueClientCertificate(uuid)
+	if err != nil {
+		api.RespondErrorT(c, 500, "certificate.issue_failed", err)
+		return
+	}
+	api.RespondSuccess(c, certificateResponse(issued))
+}
+
+func certificateResponse(issued agentca.Issued) gin.H {
+	ca, _ := agentca.CertificatePEM()
+	return gin.H{
+		"serial":      issued.Serial,
+		"not_after":   issued.NotAfter,
+		"certificate": string(issued.Certificate),
+		"private_key": string(issued.PrivateKey),
+		"ca":          string(ca),
+	}
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/patrickmn/go-cache"
+)
+
+// maxPushedSamples 每个客户端一分钟内最多接受的推送次数
+const maxPushedSamples = 60
+
+// PushMetrics 供脚本通过 HTTP 推送自定义指标，与上报中的 custom 字段格式相同，例如
+// {"queue_depth": {"type": "gauge", "value": 12, "unit": "jobs"}}
+func PushMetrics(c *gin.Context) {
+	uuid, err := api.GetClientUUID(c)
+	if err != nil || uuid == "" {
+		c.JSON(401, gin.H{"status": "error", "error": "Invalid token"})
+		return
+	}
+	var metrics map[string]common.CustomMetric
+	if err := c.ShouldBindJSON(&metrics); err != nil {
+		c.JSON(400, gin.H{"status": "error", "error": "Invalid request body"})
+		return
+	}
+	if len(metrics) == 0 {
+		c.JSON(400, gin.H{"status": "error", "error": "No metrics provided"})
+		return
+	}
+	if err := clients.VerifyCustomMetrics(metrics); err != nil {
+		c.JSON(400, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+	api.PushedMetricsMutex.Lock()
+	defer api.PushedMetricsMutex.Unlock()
+	var pushed []common.Report
+	if x, ok := api.PushedMetrics.Get(uuid); ok {
+		pushed = x.([]common.Report)
+	}
+	// 只统计最近一分钟的推送，更早的会在下次聚合时丢弃
+	now := time.Now()
+	recent := 0
+	for _, r := range pushed {
+		if now.Sub(r.UpdatedAt) < time.Minute {
+			recent++
+		}
+	}
+	if recent >= maxPushedSamples {
+		c.JSON(429, gin.H{"status": "error", "error": "Too many metric pushes, retry later"})
+		return
+	}
+	pushed = append(pushed, common.Report{UUID: uuid, Custom: metrics, UpdatedAt: now})
+	api.PushedMetrics.Set(uuid, pushed, cache.DefaultExpiration)
+	c.JSON(200, gin.H{"status": "success"})
+}
@@ -16,18 +16,16 @@ import (
 	"github.com/komari-monitor/komari/common"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/database/traffic"
+	"github.com/komari-monitor/komari/utils/agentupdate"
+	"github.com/komari-monitor/komari/utils/filetransfer"
 	"github.com/komari-monitor/komari/utils/notifier"
 	"github.com/komari-monitor/komari/ws"
 	"github.com/patrickmn/go-cache"
 )
 
-const (
-	// 如果超过这个时间没有收到任何消息，则认为连接已死
-	// 因为目前server没有存agent的信息上报间隔。只有写一个默认的
-	readWait = 11 * time.Second
-)
-
 func UploadReport(c *gin.Context) {
 	bodyBytes, err := io.ReadAll(c.Request.Body)
 	if err != nil {
@@ -49,7 +47,11 @@ func UploadReport(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	if uuid, err := api.GetClientUUID(c); err == nil && uuid != "" {
+		report.UUID = uuid
+	}
 	report.UpdatedAt = time.Now()
+	prepareCustomMetrics(report.UUID, &report)
 	err = SaveClientReport(report.UUID, report)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%v", err)})
@@ -58,6 +60,8 @@ func UploadReport(c *gin.Context) {
 	// Update report with method and token
 
 	ws.SetLatestReport(report.UUID, &report)
+	// HTTP 上报没有长连接，按上报间隔维持在线状态
+	ws.KeepAlivePresence(report.UUID, 0, clients.ReadWait(report.UUID))
 
 	c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // Restore the body for further use
 	c.JSON(200, gin.H{"status": "success"})
@@ -96,22 +100,10 @@ func WebSocketReport(c *gin.Context) {
 		conn.WriteJSON(gin.H{"status": "error", "error": "Invalid JSON"})
 		return
 	}
-	// it should ok,token was verfied in the middleware
-	token := ""
-	var errMsg string
-
-	// 优先检查查询参数中的 token
-	token = c.Query("token")
-
-	// 如果 token 为空，返回错误
-	if token == "" {
-		conn.WriteJSON(gin.H{"status": "error", "error": errMsg})
-		return
-	}
-
-	uuid, err := clients.GetClientUUIDByToken(token)
-	if err != nil {
-		conn.WriteJSON(gin.H{"status": "error", "error": errMsg})
+	// it should ok, token or client certificate was verified in the middleware
+	uuid, err := api.GetClientUUID(c)
+	if err != nil || uuid == "" {
+		conn.WriteJSON(gin.H{"status": "error", "error": "Invalid token"})
 		return
 	}
 
@@ -133,8 +125,15 @@ func WebSocketReport(c *gin.Context) {
 	// 首先处理第一次ws conn收到的消息
 	processMessage(conn, message, uuid)
 
+	// 存在单独或分组配置时下发，未配置的客户端保持 Agent 本地设置
+	if _, source, err := clients.GetEffectiveConfig(uuid); err == nil && source != clients.ConfigSourceDefault {
+		if err := api.PushCollectConfig(uuid); err != nil {
+			log.Printf("Failed to push config to client %s: %v", uuid, err)
+		}
+	}
+
 	for {
-		conn.SetReadDeadline(time.Now().Add(readWait))
+		conn.SetReadDeadline(time.Now().Add(clients.ReadWait(uuid)))
 
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -168,6 +167,7 @@ func processMessage(conn *ws.SafeConn, message []byte, uuid string) {
 			return
 		}
 		report.UpdatedAt = time.Now()
+		prepareCustomMetrics(uuid, &report)
 		err = SaveClientReport(uuid, report)
 		if err != nil {
 			conn.WriteJSON(gin.H{"status": "error", "error": fmt.Sprintf("%v", err)})
@@ -180,6 +180,11 @@ func processMessage(conn *ws.SafeConn, message []byte, uuid string) {
 			PingResult int       `json:"value"`
 			PingType   string    `json:"ping_type"`
 			FinishedAt time.Time `json:"finished_at"`
+			// 多包探测的统计，旧版本 Agent 不上报
+			Min    int     `json:"min"`
+			Max    int     `json:"max"`
+			Jitter int     `json:"jitter"`
+			Loss   float32 `json:"loss"`
 		}
 		err = json.Unmarshal(message, &reqBody)
 		if err != nil {
@@ -191,14 +196,45 @@ func processMessage(conn *ws.SafeConn, message []byte, uuid string) {
 			TaskId: reqBody.PingTaskID,
 			Value:  reqBody.PingResult,
 			Time:   models.FromTime(reqBody.FinishedAt),
+			Min:    reqBody.Min,
+			Max:    reqBody.Max,
+			Jitter: reqBody.Jitter,
+			Loss:   reqBody.Loss,
+		}
+		// 旧版本 Agent 只上报单个值
+		if pingResult.Min == 0 && pingResult.Max == 0 && pingResult.Value >= 0 {
+			pingResult.Min, pingResult.Max = pingResult.Value, pingResult.Value
 		}
 		tasks.SavePingRecord(pingResult)
+	case "file_chunk_request", "file_chunk", "file_result":
+		filetransfer.HandleMessage(conn, uuid, message)
+	case "update_result":
+		var result agentupdate.ResultMessage
+		if err := json.Unmarshal(message, &result); err != nil {
+			conn.WriteJSON(gin.H{"status": "error", "error": "Invalid update result format"})
+			return
+		}
+		agentupdate.HandleResult(uuid, result)
 	default:
 		log.Printf("Unknown message type: %s", msgType.Type)
 		conn.WriteJSON(gin.H{"status": "error", "error": "Unknown message type"})
 	}
 }
 
+// prepareCustomMetrics 校验上报中的自定义指标，并按管理员设置填充是否公开
+func prepareCustomMetrics(uuid string, report *common.Report) {
+	if err := clients.VerifyCustomMetrics(report.Custom); err != nil {
+		// 自定义指标有误时只丢弃自定义指标，不影响常规数据
+		log.Printf("Client %s reported invalid custom metrics: %v", uuid, err)
+		report.Custom = nil
+		return
+	}
+	for name, m := range report.Custom {
+		m.Public = records.IsCustomMetricPublic(uuid, name)
+		report.Custom[name] = m
+	}
+}
+
 func SaveClientReport(uuid string, report common.Report) error {
 	reports, _ := api.Records.Get(uuid)
 	if reports == nil {
@@ -209,6 +245,7 @@ func SaveClientReport(uuid string, report common.Report) error {
 	}
 	reports = append(reports.([]common.Report), report)
 	api.Records.Set(uuid, reports, cache.DefaultExpiration)
+	traffic.Accumulate(uuid, report.Network.TotalUp, report.Network.TotalDown, time.Now())
 
 	return nil
 }
@@ -4,14 +4,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/database/tasks"
 )
 
 func TaskResult(c *gin.Context) {
-	token := c.Query("token")
-	clientId, _ := clients.GetClientUUIDByToken(token)
+	clientId, _ := api.GetClientUUID(c)
 	if clientId == "" {
 		c.JSON(400, gin.H{"status": "error", "message": "Invalid or missing token"})
 		return
@@ -3,9 +3,12 @@ package client
 import (
 	"net"
 
+	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/utils/geoip"
+	"github.com/komari-monitor/komari/utils/notifier"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,8 +32,7 @@ func UploadBasicInfo(c *gin.Context) {
 		return
 	}
 
-	token := c.Query("token")
-	uuid, err := clients.GetClientUUIDByToken(token)
+	uuid, err := api.GetClientUUID(c)
 	if uuid == "" || err != nil {
 		c.JSON(400, gin.H{"status": "error", "error": "Invalid token"})
 		return
@@ -52,26 +54,22 @@ func UploadBasicInfo(c *gin.Context) {
 
 		switch ipType {
 		case 0:
-			cbi["ipv4"] = ip
+			cbi["ipv4"] = ip.String()
 		case 1:
-			cbi["ipv6"] = ip
+			cbi["ipv6"] = ip.String()
 		default:
 			break
 		}
 	}
 
+	ipv4, _ := cbi["ipv4"].(string)
+	ipv6, _ := cbi["ipv6"].(string)
+	existing, existErr := clients.GetClientByUUID(uuid)
 	if cfg, err := config.Get(); err == nil && cfg.GeoIpEnabled {
-		if ipv4, ok := cbi["ipv4"].(string); ok && ipv4 != "" {
-			ip4 := net.ParseIP(ipv4)
-			ip4_record, _ := geoip.GetGeoInfo(ip4)
-			if ip4_record != nil {
-				cbi["region"] = geoip.GetRegionUnicodeEmoji(ip4_record.ISOCode)
-			}
-		} else if ipv6, ok := cbi["ipv6"].(string); ok && ipv6 != "" {
-			ip6 := net.ParseIP(ipv6)
-			ip6_record, _ := geoip.GetGeoInfo(ip6)
-			if ip6_record != nil {
-				cbi["region"] = geoip.GetRegionUnicodeEmoji(ip6_record.ISOCode)
+		// 仅在 IP 变化或尚无地理信息时重新解析
+		if existErr != nil || existing.IPv4 != ipv4 || existing.IPv6 != ipv6 || existing.Region == "" || (existing.City == "" && existing.ASN == 0) {
+			for k, v := range geoip.ClientGeoUpdates(ipv4, ipv6) {
+				cbi[k] = v
 			}
 		}
 	}
@@ -81,5 +79,11 @@ func UploadBasicInfo(c *gin.Context) {
 		return
 	}
 
+	var previous *models.Client
+	if existErr == nil {
+		previous = &existing
+	}
+	notifier.RecordClientIP(uuid, previous, ipv4, ipv6)
+
 	c.JSON(200, gin.H{"status": "success"})
 }
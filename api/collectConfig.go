@@ -0,0 +1,20 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/ws"
+)
+
+// PushCollectConfig 通过上报 WebSocket 向在线的客户端下发当前生效的采集配置
+func PushCollectConfig(uuid string) error {
+	conn := ws.GetConnectedClients()[uuid]
+	if conn == nil {
+		return nil
+	}
+	cfg, _, err := clients.GetEffectiveConfig(uuid)
+	if err != nil {
+		return err
+	}
+	return conn.WriteJSON(gin.H{"message": "config", "config": cfg})
+}
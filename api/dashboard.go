@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/accounts"
+	"github.com/komari-monitor/komari/database/dashboards"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+)
+
+// DashboardCookiePrefix 面板访问凭据 Cookie 名前缀，后接 slug
+const DashboardCookiePrefix = "komari_dashboard_"
+
+// DashboardOnlyKey 私有站点下仅凭面板授权放行的 RPC 请求，只能调用面板使用的只读方法
+const DashboardOnlyKey = "dashboard_only"
+
+// dashboardRPCMethods 公开面板页面使用的只读 RPC 方法
+var dashboardRPCMethods = map[string]bool{
+	"getMe":                true,
+	"getPublicInfo":        true,
+	"getVersion":           true,
+	"getNodes":             true,
+	"getNodesLatestStatus": true,
+	"getNodeRecentStatus":  true,
+	"getRecords":           true,
+	"getThemeSettings":     true,
+}
+
+// DashboardRPCAllowed 判断方法是否为面板可用的只读方法，兼容 common: 前缀
+func DashboardRPCAllowed(method string) bool {
+	return dashboardRPCMethods[strings.TrimPrefix(method, "common:")]
+}
+
+var (
+	ErrDashboardNotFound     = errors.New("dashboard not found")
+	ErrDashboardUnauthorized = errors.New("dashboard access denied")
+)
+
+// DashboardSlug 提取请求关联的面板 slug。
+// 依次读取 query dashboard、请求头 X-Komari-Dashboard，以及来自 /s/<slug> 页面的 Referer。
+func DashboardSlug(c *gin.Context) string {
+	if slug := c.Query("dashboard"); slug != "" {
+		return slug
+	}
+	if slug := c.GetHeader("X-Komari-Dashboard"); slug != "" {
+		return slug
+	}
+	if ref, err := url.Parse(c.GetHeader("Referer")); err == nil && ref.Host == c.Request.Host {
+		if rest, ok := strings.CutPrefix(ref.Path, "/s/"); ok {
+			slug, _, _ := strings.Cut(rest, "/")
+			return slug
+		}
+	}
+	return ""
+}
+
+// ResolveDashboard 返回请求关联的面板，未关联面板时返回 nil, nil
+func ResolveDashboard(c *gin.Context) (*models.Dashboard, error) {
+	slug := DashboardSlug(c)
+	if slug == "" {
+		return nil, nil
+	}
+	d, err := dashboards.GetDashboardBySlug(slug)
+	if err != nil {
+		return nil, ErrDashboardNotFound
+	}
+	if !DashboardAuthorized(c, d) {
+		return nil, ErrDashboardUnauthorized
+	}
+	return d, nil
+}
+
+// DashboardAuthorized 检查请求能否访问面板：公开面板、已登录的管理员、持有分享 token 或已授权的 Cookie
+func DashboardAuthorized(c *gin.Context, d *models.Dashboard) bool {
+	if d.Access == models.DashboardAccessPublic || d.Access == "" {
+		return true
+	}
+	if session, err := c.Cookie("session_token"); err == nil {
+		if _, err := accounts.GetSession(session); err == nil {
+			return true
+		}
+	}
+	if d.Access == models.DashboardAccessToken && d.ShareToken != "" {
+		token := c.Query("token")
+		if token == "" {
+			token = c.GetHeader("X-Komari-Dashboard-Token")
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(d.ShareToken)) == 1 {
+			return true
+		}
+	}
+	if v, err := c.Cookie(DashboardCookiePrefix + d.Slug); err == nil {
+		return subtle.ConstantTimeCompare([]byte(v), []byte(dashboards.AccessKey(d))) == 1
+	}
+	return false
+}
+
+// SetDashboardCookie 授权访问后写入面板凭据，有效期 30 天
+func SetDashboardCookie(c *gin.Context, d *models.Dashboard) {
+	c.SetCookie(DashboardCookiePrefix+d.Slug, dashboards.AccessKey(d), 30*24*3600, "/", "", utils.GetScheme(c) == "https", true)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/dashboards"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDashboardContext(target string, header map[string]string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		c.Request.Header.Set(k, v)
+	}
+	return c
+}
+
+func TestDashboardSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		target string
+		header map[string]string
+		want   string
+	}{
+		{"查询参数", "/api/rpc2?dashboard=foo", nil, "foo"},
+		{"请求头", "/api/rpc2", map[string]string{"X-Komari-Dashboard": "bar"}, "bar"},
+		{"同源 Referer", "/api/rpc2", map[string]string{"Referer": "http://example.com/s/baz/instance"}, "baz"},
+		{"跨站 Referer", "/api/rpc2", map[string]string{"Referer": "http://evil.com/s/baz"}, ""},
+		{"主站页面", "/api/rpc2", map[string]string{"Referer": "http://example.com/"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DashboardSlug(newDashboardContext(tt.target, tt.header)))
+		})
+	}
+}
+
+func TestDashboardAuthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	d := &models.Dashboard{Slug: "team", Access: models.DashboardAccessToken, ShareToken: "secret"}
+	tests := []struct {
+		name   string
+		d      *models.Dashboard
+		target string
+		header map[string]string
+		want   bool
+	}{
+		{"公开面板", &models.Dashboard{Slug: "pub", Access: models.DashboardAccessPublic}, "/s/pub", nil, true},
+		{"正确的分享 token", d, "/s/team?token=secret", nil, true},
+		{"错误的分享 token", d, "/s/team?token=wrong", nil, false},
+		{"请求头携带 token", d, "/api/rpc2", map[string]string{"X-Komari-Dashboard-Token": "secret"}, true},
+		{"有效的 Cookie", d, "/api/rpc2", map[string]string{"Cookie": DashboardCookiePrefix + "team=" + dashboards.AccessKey(d)}, true},
+		{"无凭据", d, "/api/rpc2", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DashboardAuthorized(newDashboardContext(tt.target, tt.header), tt.d))
+		})
+	}
+}
+
+func TestDashboardRPCAllowed(t *testing.T) {
+	assert.True(t, DashboardRPCAllowed("getNodes"))
+	assert.True(t, DashboardRPCAllowed("common:getRecords"))
+	assert.False(t, DashboardRPCAllowed("admin:getClients"))
+	assert.False(t, DashboardRPCAllowed("rpc:listMethods"))
+	assert.False(t, DashboardRPCAllowed("getNodesX"))
+}
+
+func TestSetDashboardCookieSecure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	d := &models.Dashboard{Slug: "team", Access: models.DashboardAccessPassword}
+	for _, proto := range []string{"http", "https"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/s/team", nil)
+		c.Request.Header.Set("X-Forwarded-Proto", proto)
+		SetDashboardCookie(c, d)
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, proto == "https", cookies[0].Secure)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/accounts"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/exposure"
+)
+
+// RequestView 返回 REST 请求的字段可见性规则。
+// 已登录的管理员不受限制，来自公开面板页面的请求按面板的节点选择与隐藏字段过滤。
+func RequestView(c *gin.Context) exposure.View {
+	cfg, _ := config.Get()
+	isAdmin := false
+	if session, err := c.Cookie("session_token"); err == nil {
+		if _, err := accounts.GetUserBySession(session); err == nil {
+			isAdmin = true
+		}
+	}
+	d, _ := ResolveDashboard(c)
+	return exposure.NewView(cfg, isAdmin, d)
+}
+
+// HiddenClients 返回对当前请求不可见的客户端 UUID，管理员直接访问时为空
+func HiddenClients(view exposure.View) map[string]bool {
+	if view.Unrestricted() {
+		return map[string]bool{}
+	}
+	var list []models.Client
+	db := dbcore.GetDBInstance()
+	_ = db.Select("uuid", "hidden", "group", "tags").Find(&list).Error
+	return view.HiddenSet(list)
+}
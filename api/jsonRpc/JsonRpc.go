@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/accounts"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
@@ -38,7 +39,12 @@ func OnRpcRequest(c *gin.Context) {
 		}
 		permissionGroup := detectPermissionGroup(c, cfg)
 		meta := buildContextMeta(c, permissionGroup)
+		dashboardOnly := isDashboardOnly(c, permissionGroup)
 		defer conn.Close()
+		if err := attachDashboard(c, meta); err != nil {
+			conn.WriteJSON(rpc.ErrorResponse(nil, rpc.PermissionDenied, err.Error(), nil))
+			return
+		}
 		for {
 			var req rpc.JsonRpcRequest
 			err := conn.ReadJSON(&req)
@@ -57,6 +63,10 @@ func OnRpcRequest(c *gin.Context) {
 				conn.WriteJSON(jerr.ResponseWithID(req.ID))
 				continue
 			}
+			if dashboardOnly && !api.DashboardRPCAllowed(req.Method) {
+				conn.WriteJSON(rpc.ErrorResponse(req.ID, 401, "Unauthorized", nil))
+				continue
+			}
 			dispatchByPermissionWithMeta(conn, permissionGroup, meta, &req)
 		}
 		return
@@ -79,9 +89,18 @@ func OnRpcRequest(c *gin.Context) {
 	}
 	permissionGroup := detectPermissionGroup(c, cfg)
 	meta := buildContextMeta(c, permissionGroup)
+	if err := attachDashboard(c, meta); err != nil {
+		c.JSON(http.StatusForbidden, rpc.ErrorResponse(nil, rpc.PermissionDenied, err.Error(), nil))
+		return
+	}
+	dashboardOnly := isDashboardOnly(c, permissionGroup)
 	// 批量
 	responses := make([]*rpc.JsonRpcResponse, 0, len(requests))
 	for _, rreq := range requests {
+		if dashboardOnly && !api.DashboardRPCAllowed(rreq.Method) {
+			responses = append(responses, rpc.ErrorResponse(rreq.ID, 401, "Unauthorized", nil))
+			continue
+		}
 		// 权限与命名空间
 		fc := strings.Split(rreq.Method, ":")
 		if len(fc) == 1 {
@@ -121,9 +140,13 @@ func OnRpcRequest(c *gin.Context) {
 func detectPermissionGroup(c *gin.Context, cfg models.Config) string {
 	permissionGroup := "guest"
 	token := c.Query("Authorization")
-	if _, err := clients.GetClientUUIDByToken(token); err == nil {
+	if _, err := clients.GetClientUUIDByToken(token); err == nil && api.IsIPAllowed(api.ScopeAgent, c.ClientIP()) {
 		permissionGroup = "client"
 	}
+	// 管理员身份同样受管理路由的 IP 访问控制约束
+	if !api.IsIPAllowed(api.ScopeAdmin, c.ClientIP()) {
+		return permissionGroup
+	}
 	if session_token, _ := c.Cookie("session_token"); session_token != "" {
 		if _, err := accounts.GetUserBySession(session_token); err == nil {
 			permissionGroup = "admin"
@@ -166,6 +189,21 @@ func buildContextMeta(c *gin.Context, permissionGroup string) *rpc.ContextMeta {
 	return meta
 }
 
+// isDashboardOnly 私有站点下仅凭面板授权放行的非管理员请求
+func isDashboardOnly(c *gin.Context, permissionGroup string) bool {
+	return c.GetBool(api.DashboardOnlyKey) && permissionGroup != "admin"
+}
+
+// attachDashboard 解析请求关联的公开面板并写入 meta，无权访问时返回错误
+func attachDashboard(c *gin.Context, meta *rpc.ContextMeta) error {
+	d, err := api.ResolveDashboard(c)
+	if err != nil {
+		return err
+	}
+	meta.Dashboard = d
+	return nil
+}
+
 // dispatchByPermissionWithMeta 与原函数类似，但会携带 meta 上下文给 handler
 func dispatchByPermissionWithMeta(conn *ws.SafeConn, permissionGroup string, meta *rpc.ContextMeta, req *rpc.JsonRpcRequest) {
 	fc := strings.Split(req.Method, ":")
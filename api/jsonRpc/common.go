@@ -3,9 +3,6 @@ package jsonRpc
 import (
 	"context"
 	"fmt"
-	"math"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/komari-monitor/komari/api"
@@ -17,6 +14,7 @@ import (
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/database/tasks"
 	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/exposure"
 	"github.com/komari-monitor/komari/utils/rpc"
 	"github.com/komari-monitor/komari/ws"
 
@@ -34,6 +32,8 @@ type pingStat struct {
 	Loss   float64 `json:"loss"` // 丢包率 %
 	Min    int     `json:"min"`
 	Max    int     `json:"max"`
+	P95    int     `json:"p95"`
+	Jitter int     `json:"jitter"`
 }
 
 // getPingStatsForNode 计算并缓存节点最近 1 小时 ping 统计
@@ -85,82 +85,21 @@ func getPingStatsForNode(uuid string, pingTasks []models.PingTask) map[string]pi
 		if len(records) == 0 {
 			continue
 		}
-		latest := -1
-		var latestTs time.Time
-		values := make([]int, 0, len(records))
-		sum := 0
-		valid := 0
-		total := 0
-		lossCount := 0
-		minLat := 0
-		maxLat := 0
-		for _, r := range records {
-			total++
-			if r.Value < 0 { // 丢包
-				lossCount++
-				continue
-			}
-			values = append(values, r.Value)
-			sum += r.Value
-			valid++
-			if minLat == 0 || r.Value < minLat {
-				minLat = r.Value
-			}
-			if r.Value > maxLat {
-				maxLat = r.Value
-			}
-			ts := r.Time.ToTime()
-			if latestTs.IsZero() || ts.After(latestTs) {
-				latestTs = ts
-				latest = r.Value
-			}
-		}
-		avg := 0
-		if valid > 0 {
-			avg = sum / valid
-		}
-		p50, p99 := 0, 0
-		if len(values) > 0 {
-			sort.Ints(values)
-			percentile := func(vals []int, pct float64) int {
-				if len(vals) == 0 {
-					return 0
-				}
-				if pct <= 0 {
-					return vals[0]
-				}
-				if pct >= 1 {
-					return vals[len(vals)-1]
-				}
-				pos := (float64(len(vals) - 1)) * pct
-				lo := int(math.Floor(pos))
-				hi := int(math.Ceil(pos))
-				if lo == hi {
-					return vals[lo]
-				}
-				frac := pos - float64(lo)
-				v := float64(vals[lo]) + (float64(vals[hi])-float64(vals[lo]))*frac
-				return int(math.Round(v))
-			}
-			p50 = percentile(values, 0.50)
-			p99 = percentile(values, 0.99)
-		}
+		w := utils.SummarizePing(records)
 		tail := 0.0
-		if p50 > 0 && p99 >= p50 {
-			tail = float64(p99-p50) / float64(p50)
-		}
-		lossRate := 0.0
-		if total > 0 {
-			lossRate = float64(lossCount) / float64(total) * 100
+		if w.P50 > 0 && w.P99 >= w.P50 {
+			tail = float64(w.P99-w.P50) / float64(w.P50)
 		}
 		result[fmt.Sprintf("%d", t.Id)] = pingStat{
 			Name:   t.Name,
-			Latest: latest,
-			Avg:    avg,
+			Latest: w.Latest,
+			Avg:    w.Avg,
 			Tail:   tail,
-			Loss:   lossRate,
-			Min:    minLat,
-			Max:    maxLat,
+			Loss:   w.Loss,
+			Min:    w.Min,
+			Max:    w.Max,
+			P95:    w.P95,
+			Jitter: w.Jitter,
 		}
 	}
 	pingStatsCache.Set(key, result, cache.DefaultExpiration)
@@ -218,6 +157,12 @@ func init() {
 	Register("getNodeRecentStatus", getNodeRecentStatus)
 }
 
+// viewOf 返回本次调用的字段可见性规则
+func viewOf(meta *rpc.ContextMeta) exposure.View {
+	cfg, _ := config.Get()
+	return exposure.NewView(cfg, meta.Permission == "admin", meta.Dashboard)
+}
+
 func getNodes(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpcError) {
 	var params struct {
 		UUID string `json:"uuid"`
@@ -227,35 +172,8 @@ func getNodes(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpcEr
 	if err != nil {
 		return nil, rpc.MakeError(rpc.InternalError, "Failed to get client info", cinfo)
 	}
-	meta := rpc.MetaFromContext(ctx)
-
-	cfg, _ := config.Get()
-	if meta.Permission != "admin" {
-		// 过滤 Hidden 节点并隐藏敏感字段
-		filtered := make([]models.Client, 0, len(cinfo))
-		for _, node := range cinfo {
-			if node.Hidden { // 非 admin 不显示隐藏节点
-				continue
-			}
-			if cfg.SendIpAddrToGuest {
-				if node.IPv4 != "" {
-					node.IPv4 = strings.Split(node.IPv4, ".")[0] + ".*.*.*"
-				}
-				if node.IPv6 != "" {
-					node.IPv6 = strings.Split(node.IPv6, ":")[0] + ":*:*:*:*:*:*:*"
-				}
-			} else {
-				node.IPv4 = ""
-				node.IPv6 = ""
-			}
-
-			node.Remark = ""
-			node.Version = ""
-			node.Token = ""
-			filtered = append(filtered, node)
-		}
-		cinfo = filtered
-	}
+	// 过滤不可见节点并按字段策略清除敏感字段
+	cinfo = viewOf(rpc.MetaFromContext(ctx)).Clients(cinfo)
 	if params.UUID != "" {
 		for _, node := range cinfo {
 			if node.UUID == params.UUID {
@@ -272,7 +190,14 @@ func getNodes(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpcEr
 	return nodesMap, nil
 }
 
-func getPublicInfo(_ context.Context, _ *rpc.JsonRpcRequest) (any, *rpc.JsonRpcError) {
+func getPublicInfo(ctx context.Context, _ *rpc.JsonRpcRequest) (any, *rpc.JsonRpcError) {
+	if meta := rpc.MetaFromContext(ctx); meta != nil && meta.Dashboard != nil {
+		info, err := database.GetDashboardPublicInfo(meta.Dashboard)
+		if err != nil {
+			return nil, rpc.MakeError(rpc.InternalError, "Failed to get public info", err.Error())
+		}
+		return info, nil
+	}
 	info, err := database.GetPublicInfo()
 	if err != nil {
 		return nil, rpc.MakeError(rpc.InternalError, "Failed to get public info", err.Error())
@@ -295,18 +220,14 @@ func getNodesLatestStatus(ctx context.Context, req *rpc.JsonRpcRequest) (any, *r
 		onlineSet[uuid] = true
 	}
 
-	// Hidden 过滤
-	if meta.Permission != "admin" {
+	// Hidden 与面板节点选择过滤
+	view := viewOf(meta)
+	if !view.Unrestricted() {
 		cinfo, err := clients.GetAllClientBasicInfo()
 		if err != nil {
 			return nil, rpc.MakeError(rpc.InternalError, "Failed to get client info", err.Error())
 		}
-		hidden := make(map[string]bool, len(cinfo))
-		for _, c := range cinfo {
-			if c.Hidden {
-				hidden[c.UUID] = true
-			}
-		}
+		hidden := view.HiddenSet(cinfo)
 		for uuid := range latest {
 			if hidden[uuid] {
 				delete(latest, uuid)
@@ -358,6 +279,8 @@ func getNodesLatestStatus(ctx context.Context, req *rpc.JsonRpcRequest) (any, *r
 			return
 		}
 		stats := getPingStatsForNode(uuid, pingTasks)
+		filtered := view.Report(rep)
+		rep = &filtered
 		rl := recordLike{
 			Client:         uuid,
 			Time:           models.FromTime(rep.UpdatedAt),
@@ -470,20 +393,16 @@ func getNodeRecentStatus(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rp
 	}
 	meta := rpc.MetaFromContext(ctx)
 	// 登录状态检查
-	isLogin := false
-	if meta.Permission == "admin" {
-		isLogin = true
-	}
+	view := viewOf(meta)
+	isLogin := view.Unrestricted()
 
 	// 仅在未登录时需要 Hidden 信息做过滤
 	hiddenMap := map[string]bool{}
 	if !isLogin {
-		var hiddenClients []models.Client
+		var allClients []models.Client
 		db := dbcore.GetDBInstance()
-		_ = db.Select("uuid").Where("hidden = ?", true).Find(&hiddenClients).Error
-		for _, cli := range hiddenClients {
-			hiddenMap[cli.UUID] = true
-		}
+		_ = db.Select("uuid", "hidden", "group", "tags").Find(&allClients).Error
+		hiddenMap = view.HiddenSet(allClients)
 
 		if hiddenMap[params.UUID] {
 			return nil, rpc.MakeError(rpc.InvalidParams, "UUID is required", params) //防止未登录用户获取隐藏客户端数据
@@ -530,6 +449,7 @@ func getNodeRecentStatus(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rp
 
 	resp.Records = make([]flatRecord, 0, len(reports))
 	for _, r := range reports {
+		r = view.Report(&r)
 		fr := flatRecord{
 			Client:         params.UUID,
 			Time:           models.FromTime(r.UpdatedAt),
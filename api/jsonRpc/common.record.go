@@ -12,6 +12,7 @@ import (
 	"github.com/komari-monitor/komari/database/models"
 	recordsdb "github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils/exposure"
 	"github.com/komari-monitor/komari/utils/rpc"
 )
 
@@ -22,13 +23,14 @@ func init() {
 func getRecords(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpcError) {
 	meta := rpc.MetaFromContext(ctx)
 	var params struct {
-		Type     string `json:"type"`      // "load" | "ping"; default "load"
+		Type     string `json:"type"`      // "load" | "ping" | "custom"; default "load"
 		UUID     string `json:"uuid"`      // client uuid; empty = all clients
 		Hours    int    `json:"hours"`     // time window in hours; default 1 if start/end not provided
 		Start    string `json:"start"`     // RFC3339 start time (optional)
 		End      string `json:"end"`       // RFC3339 end time (optional)
 		LoadType string `json:"load_type"` // for type=load: cpu|gpu|ram|swap|load|temp|disk|network|process|connections|all
 		TaskID   int    `json:"task_id"`   // for type=ping: optional task id; -1 or omitted means all
+		Metric   string `json:"metric"`    // for type=custom: optional metric name; empty means all
 		MaxCount int    `json:"maxCount"`  // max number of points; -1 unlimited; default 4000
 	}
 	req.BindParams(&params)
@@ -70,18 +72,15 @@ func getRecords(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpc
 	}
 
 	// Hidden filtering for non-admin
-	isAdmin := meta.Permission == "admin"
+	view := viewOf(meta)
+	isAdmin := view.Unrestricted()
 	hidden := map[string]bool{}
 	if !isAdmin {
 		cinfo, err := clients.GetAllClientBasicInfo()
 		if err != nil {
 			return nil, rpc.MakeError(rpc.InternalError, "Failed to get client info", err.Error())
 		}
-		for _, c := range cinfo {
-			if c.Hidden {
-				hidden[c.UUID] = true
-			}
-		}
+		hidden = view.HiddenSet(cinfo)
 		if params.UUID != "" && hidden[params.UUID] {
 			return nil, rpc.MakeError(rpc.InvalidParams, "UUID not found", params.UUID)
 		}
@@ -101,6 +100,7 @@ func getRecords(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpc
 				if hidden[r.Client] {
 					continue
 				}
+				view.Record(&r)
 				filtered = append(filtered, r)
 			}
 			recs = filtered
@@ -402,7 +402,16 @@ func getRecords(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpc
 				"p99_p50_ratio": ratio,
 			}
 			if params.UUID == "" && taskId != -1 { // retain existing behavior of exposing clients only when filtering by task
-				info["clients"] = t.Clients
+				visible := make([]string, 0, len(t.Clients))
+				for _, c := range t.Clients {
+					if !hidden[c] {
+						visible = append(visible, c)
+					}
+				}
+				info["clients"] = visible
+			}
+			if view.Allows(exposure.FieldPingTarget) {
+				info["target"] = t.Target
 			}
 			toList = append(toList, info)
 		}
@@ -502,11 +511,86 @@ func getRecords(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpc
 			return response.Records[i].Time.ToTime().Before(response.Records[j].Time.ToTime())
 		})
 		return response, nil
+	case "custom":
+		return getCustomRecords(view, hidden, params.UUID, params.Metric, startTime, endTime, params.MaxCount)
 	default:
-		return nil, rpc.MakeError(rpc.InvalidParams, "Invalid type, expected 'load' or 'ping'", params.Type)
+		return nil, rpc.MakeError(rpc.InvalidParams, "Invalid type, expected 'load', 'ping' or 'custom'", params.Type)
 	}
 }
 
+// getCustomRecords returns custom metric records grouped by client, together with the metric definitions.
+// Non-admin viewers only see public metrics of visible clients.
+func getCustomRecords(view exposure.View, hidden map[string]bool, uuid, metric string, start, end time.Time, maxCount int) (any, *rpc.JsonRpcError) {
+	defs, err := recordsdb.ListCustomMetrics(uuid)
+	if err != nil {
+		return nil, rpc.MakeError(rpc.InternalError, "Failed to fetch custom metrics", err.Error())
+	}
+	metrics := make([]models.CustomMetric, 0, len(defs))
+	visible := make(map[string]bool)
+	for _, m := range view.CustomMetrics(defs) {
+		if hidden[m.Client] || (metric != "" && m.Name != metric) {
+			continue
+		}
+		metrics = append(metrics, m)
+		visible[m.Client+"/"+m.Name] = true
+	}
+
+	var names []string
+	if metric != "" {
+		names = []string{metric}
+	}
+	recs, err := recordsdb.GetCustomRecords(uuid, names, start, end)
+	if err != nil {
+		return nil, rpc.MakeError(rpc.InternalError, "Failed to fetch records", err.Error())
+	}
+	series := make(map[string][]models.CustomRecord)
+	for _, r := range recs {
+		key := r.Client + "/" + r.Name
+		if visible[key] {
+			series[key] = append(series[key], r)
+		}
+	}
+
+	if maxCount == 0 {
+		maxCount = 4000
+	}
+	total := 0
+	groupsMeta := make([]struct {
+		name   string
+		length int
+	}, 0, len(series))
+	for key, arr := range series {
+		sort.Slice(arr, func(i, j int) bool { return arr[i].Time.ToTime().Before(arr[j].Time.ToTime()) })
+		total += len(arr)
+		groupsMeta = append(groupsMeta, struct {
+			name   string
+			length int
+		}{name: key, length: len(arr)})
+	}
+	if maxCount != -1 && total > maxCount {
+		targets := allocateTargets(groupsMeta, maxCount)
+		total = 0
+		for key, k := range targets {
+			series[key] = downsampleCustomRecords(series[key], k)
+			total += len(series[key])
+		}
+	}
+
+	grouped := make(map[string][]models.CustomRecord)
+	for _, arr := range series {
+		if len(arr) > 0 {
+			grouped[arr[0].Client] = append(grouped[arr[0].Client], arr...)
+		}
+	}
+	return struct {
+		Count   int                              `json:"count"`
+		Records map[string][]models.CustomRecord `json:"records"`
+		Metrics []models.CustomMetric            `json:"metrics"`
+		From    models.LocalTime                 `json:"from"`
+		To      models.LocalTime                 `json:"to"`
+	}{Count: total, Records: grouped, Metrics: metrics, From: models.FromTime(start), To: models.FromTime(end)}, nil
+}
+
 // ---------- helpers for load records ----------
 
 // getLoadRecordsCombined fetches records for a client or all clients within a time range,
@@ -692,6 +776,31 @@ func downsampleModelRecords(in []models.Record, k int) []models.Record {
 	return out
 }
 
+func downsampleCustomRecords(in []models.CustomRecord, k int) []models.CustomRecord {
+	n := len(in)
+	if k <= 0 || n == 0 {
+		return []models.CustomRecord{}
+	}
+	if k >= n {
+		return in
+	}
+	out := make([]models.CustomRecord, 0, k)
+	if k == 1 {
+		out = append(out, in[n-1])
+		return out
+	}
+	for i := 0; i < k; i++ {
+		idx := int(math.Round(float64(i) * float64(n-1) / float64(k-1)))
+		if idx < 0 {
+			idx = 0
+		} else if idx >= n {
+			idx = n - 1
+		}
+		out = append(out, in[idx])
+	}
+	return out
+}
+
 func downsampleFlatRecords(in []flatRecord, k int) []flatRecord {
 	n := len(in)
 	if k <= 0 || n == 0 {
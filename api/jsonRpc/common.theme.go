@@ -0,0 +1,76 @@
+package jsonRpc
+
+import (
+	"context"
+
+	"github.com/komari-monitor/komari/database"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/utils/rpc"
+	"github.com/komari-monitor/komari/utils/themes"
+)
+
+func init() {
+	RegisterWithGroupAndMeta("getThemeSettings", "common", getThemeSettings, &rpc.MethodMeta{
+		Name:    "getThemeSettings",
+		Summary: "Get resolved settings of a theme, with declared defaults filled in.",
+		Params: []rpc.ParamMeta{
+			{
+				Name:        "theme",
+				Description: "Theme short name; defaults to the active theme (or the dashboard theme)",
+				Required:    false,
+				Type:        "string",
+			},
+		},
+		Returns: "{ theme: string, settings: { [key]: any } }",
+	})
+}
+
+func getThemeSettings(ctx context.Context, req *rpc.JsonRpcRequest) (any, *rpc.JsonRpcError) {
+	var params struct {
+		Theme string `json:"theme"`
+	}
+	req.BindParams(&params)
+
+	meta := rpc.MetaFromContext(ctx)
+	theme := params.Theme
+	if theme == "" {
+		cfg, err := config.Get()
+		if err != nil {
+			return nil, rpc.MakeError(rpc.InternalError, "Failed to get config", err.Error())
+		}
+		theme = cfg.Theme
+		if meta.Dashboard != nil && meta.Dashboard.Theme != "" {
+			theme = meta.Dashboard.Theme
+		}
+	}
+	if theme != "default" && !themes.IsValidShort(theme) {
+		return nil, rpc.MakeError(rpc.InvalidParams, "Invalid theme", theme)
+	}
+	settings := database.LoadThemeSettings(theme)
+
+	// 客户端选择项中去掉当前受众不可见的节点
+	if view := viewOf(meta); !view.Unrestricted() {
+		if items, ok := themes.Schema(theme); ok {
+			cinfo, err := clients.GetAllClientBasicInfo()
+			if err != nil {
+				return nil, rpc.MakeError(rpc.InternalError, "Failed to get client info", err.Error())
+			}
+			hidden := view.HiddenSet(cinfo)
+			for _, item := range items {
+				if item.Type != themes.SettingClients {
+					continue
+				}
+				list, _ := settings[item.Key].([]any)
+				visible := make([]any, 0, len(list))
+				for _, uuid := range list {
+					if s, ok := uuid.(string); ok && !hidden[s] {
+						visible = append(visible, s)
+					}
+				}
+				settings[item.Key] = visible
+			}
+		}
+	}
+	return map[string]any{"theme": theme, "settings": settings}, nil
+}
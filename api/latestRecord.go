@@ -2,43 +2,28 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/komari-monitor/komari/database/accounts"
-	"github.com/komari-monitor/komari/database/dbcore"
-	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/common"
 )
 
 func GetClientRecentRecords(c *gin.Context) {
 	uuid := c.Param("uuid")
 
 	if uuid == "" {
-		RespondError(c, 400, "UUID is required")
+		RespondErrorT(c, 400, "common.uuid_required")
 		return
 	}
 
-	// 登录状态检查
-	isLogin := false
-	session, _ := c.Cookie("session_token")
-	_, err := accounts.GetUserBySession(session)
-	if err == nil {
-		isLogin = true
+	view := RequestView(c)
+	if HiddenClients(view)[uuid] {
+		RespondErrorT(c, 400, "common.uuid_required") //防止未登录用户获取隐藏客户端数据
+		return
 	}
 
-	// 仅在未登录时需要 Hidden 信息做过滤
-	hiddenMap := map[string]bool{}
-	if !isLogin {
-		var hiddenClients []models.Client
-		db := dbcore.GetDBInstance()
-		_ = db.Select("uuid").Where("hidden = ?", true).Find(&hiddenClients).Error
-		for _, cli := range hiddenClients {
-			hiddenMap[cli.UUID] = true
-		}
-
-		if hiddenMap[uuid] {
-			RespondError(c, 400, "UUID is required") //防止未登录用户获取隐藏客户端数据
-			return
-		}
+	raw, _ := Records.Get(uuid)
+	reports, _ := raw.([]common.Report)
+	filtered := make([]common.Report, 0, len(reports))
+	for i := range reports {
+		filtered = append(filtered, view.Report(&reports[i]))
 	}
-
-	records, _ := Records.Get(uuid)
-	RespondSuccess(c, records)
+	RespondSuccess(c, filtered)
 }
@@ -16,56 +16,77 @@ type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	TwoFa    string `json:"2fa_code"`
+	// 丢失 2FA 设备时可以使用一次性恢复码代替
+	RecoveryCode string `json:"recovery_code"`
 }
 
 func Login(c *gin.Context) {
 	conf, _ := config.Get()
 	if conf.DisablePasswordLogin {
-		RespondError(c, http.StatusForbidden, "Password login is disabled")
+		RespondErrorT(c, http.StatusForbidden, "login.password_disabled")
 		return
 	}
 
 	bodyBytes, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		RespondError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_body_with", err)
 		return
 	}
 	var data LoginRequest
 	err = json.Unmarshal(bodyBytes, &data)
 	if err != nil {
-		RespondError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_body_with", err)
 		return
 	}
 	if data.Username == "" || data.Password == "" {
-		RespondError(c, http.StatusBadRequest, "Invalid request body: Username and password are required")
+		RespondErrorT(c, http.StatusBadRequest, "login.credentials_required")
+		return
+	}
+
+	if !checkLoginAllowed(c, data.Username) {
 		return
 	}
 
 	uuid, success := accounts.CheckPassword(data.Username, data.Password)
 	if !success {
-		RespondError(c, http.StatusUnauthorized, "Invalid credentials")
+		recordLoginFailure(c, data.Username, "invalid credentials")
+		RespondErrorT(c, http.StatusUnauthorized, "login.invalid_credentials")
 		return
 	}
 	// 2FA
 	user, _ := accounts.GetUserByUUID(uuid)
+	loginMethod := "password"
 	if user.TwoFactor != "" { // 开启了2FA
-		if data.TwoFa == "" {
-			RespondError(c, http.StatusUnauthorized, "2FA code is required")
-			return
-		}
-		if ok, err := accounts.Verify2Fa(uuid, data.TwoFa); err != nil || !ok {
-			RespondError(c, http.StatusUnauthorized, "Invalid 2FA code")
+		switch {
+		case data.TwoFa != "":
+			if ok, err := accounts.Verify2Fa(uuid, data.TwoFa); err != nil || !ok {
+				recordLoginFailure(c, data.Username, "invalid 2FA code")
+				RespondErrorT(c, http.StatusUnauthorized, "twofa.invalid_code")
+				return
+			}
+			loginMethod = "password+totp"
+		case data.RecoveryCode != "":
+			if !accounts.UseRecoveryCode(uuid, data.RecoveryCode) {
+				recordLoginFailure(c, data.Username, "invalid recovery code")
+				RespondErrorT(c, http.StatusUnauthorized, "login.invalid_recovery_code")
+				return
+			}
+			loginMethod = "password+recovery_code"
+			auditlog.Log(c.ClientIP(), uuid, "used a recovery code to log in", "warn")
+		default:
+			RespondErrorT(c, http.StatusUnauthorized, "login.2fa_required")
 			return
 		}
 	}
+	recordLoginSuccess(c, data.Username)
 	// Create session
-	session, err := accounts.CreateSession(uuid, 2592000, c.Request.UserAgent(), c.ClientIP(), "password")
+	session, err := accounts.CreateSession(uuid, 2592000, c.Request.UserAgent(), c.ClientIP(), loginMethod)
 	if err != nil {
-		RespondError(c, http.StatusInternalServerError, "Failed to create session: "+err.Error())
+		RespondErrorT(c, http.StatusInternalServerError, "login.create_session_failed", err)
 		return
 	}
 	c.SetCookie("session_token", session, 2592000, "/", "", false, true)
-	auditlog.Log(c.ClientIP(), uuid, "logged in (password)", "login")
+	auditlog.Log(c.ClientIP(), uuid, "logged in ("+loginMethod+")", "login")
 	RespondSuccess(c, gin.H{"set-cookie": gin.H{"session_token": session}})
 }
 func Logout(c *gin.Context) {
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/utils/bruteforce"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/messageSender"
+)
+
+var loginGuard = bruteforce.New()
+
+func loginGuardKeys(ip, username string) []string {
+	keys := []string{"ip:" + ip}
+	if username != "" {
+		keys = append(keys, "user:"+strings.ToLower(username))
+	}
+	return keys
+}
+
+// checkLoginAllowed 若当前 IP 或用户名仍在退避/锁定期内，返回 429 并终止请求
+func checkLoginAllowed(c *gin.Context, username string) bool {
+	wait := loginGuard.Wait(loginGuardKeys(c.ClientIP(), username)...)
+	if wait <= 0 {
+		return true
+	}
+	seconds := retryAfter(c, wait)
+	RespondErrorT(c, http.StatusTooManyRequests, "login.too_many_attempts", seconds)
+	return false
+}
+
+// retryAfter 写入 Retry-After 头并返回需要等待的秒数
+func retryAfter(c *gin.Context, wait time.Duration) int {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	return seconds
+}
+
+// recordLoginFailure 记录失败、写入审计日志，并在触发锁定时发送登录通知
+func recordLoginFailure(c *gin.Context, username, reason string) {
+	recordGuardFailure(c, loginGuardKeys(c.ClientIP(), username),
+		fmt.Sprintf("login failed: %s, username: %s", reason, username), "username: "+username)
+}
+
+func recordGuardFailure(c *gin.Context, keys []string, failure, target string) {
+	cfg, _ := config.Get()
+	ip := c.ClientIP()
+	lockout := time.Duration(cfg.LoginLockoutMinutes) * time.Minute
+	if lockout <= 0 {
+		lockout = 15 * time.Minute
+	}
+	wait, locked := loginGuard.Fail(cfg.LoginFailureLimit, lockout, keys...)
+	auditlog.Log(ip, "", failure, "warn")
+	if !locked {
+		return
+	}
+	auditlog.Log(ip, "", fmt.Sprintf("login locked for %s: %s, %s", wait.Round(time.Second), ip, target), "warn")
+	if cfg.LoginNotification {
+		go messageSender.SendEvent(models.EventMessage{
+			Event:   messageevent.Login,
+			Time:    time.Now(),
+			Message: i18n.T(cfg.NotificationLocale, "notify.login_locked", wait.Round(time.Second), ip, target) + "\n" + c.Request.UserAgent(),
+			Emoji:   "🚫",
+		})
+	}
+}
+
+// recordLoginSuccess 登录成功后清除计数
+func recordLoginSuccess(c *gin.Context, username string) {
+	loginGuard.Success(loginGuardKeys(c.ClientIP(), username)...)
+}
+
+// 面板密码按 面板+IP 计数，不影响管理员登录，也不会因他人输错而锁定整个面板
+func dashboardGuardKeys(c *gin.Context, slug string) []string {
+	return []string{"dashboard:" + slug + ":" + c.ClientIP()}
+}
+
+// DashboardPasswordWait 返回面板密码仍需等待的秒数，为 0 表示允许尝试
+func DashboardPasswordWait(c *gin.Context, slug string) int {
+	wait := loginGuard.Wait(dashboardGuardKeys(c, slug)...)
+	if wait <= 0 {
+		return 0
+	}
+	return retryAfter(c, wait)
+}
+
+// RecordDashboardPasswordFailure 记录面板密码错误
+func RecordDashboardPasswordFailure(c *gin.Context, slug string) {
+	recordGuardFailure(c, dashboardGuardKeys(c, slug), "dashboard password failed: "+slug, "dashboard: "+slug)
+}
+
+// RecordDashboardPasswordSuccess 面板密码正确后清除计数
+func RecordDashboardPasswordSuccess(c *gin.Context, slug string) {
+	loginGuard.Success(dashboardGuardKeys(c, slug)...)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/komari-monitor/komari/database/accounts"
 	"github.com/komari-monitor/komari/database/auditlog"
 	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/utils"
 	"github.com/komari-monitor/komari/utils/oauth"
 )
@@ -21,6 +22,10 @@ func OAuth(c *gin.Context) {
 	}
 
 	authURL, state := oauth.CurrentProvider().GetAuthorizationURL(utils.GetCallbackURL(c))
+	if authURL == "" {
+		c.JSON(502, gin.H{"status": "error", "error": "OAuth provider is unavailable"})
+		return
+	}
 
 	c.SetCookie("oauth_state", state, 3600, "/", "", false, true)
 
@@ -92,6 +97,13 @@ func OAuthCallback(c *gin.Context) {
 
 	// 尝试获取用户
 	user, err := accounts.GetUserBySSO(sso_id)
+	if err != nil && oidcUser.AutoProvision {
+		// 首次登录自动创建账号
+		user, err = provisionExternalAccount(oidcUser.Username, sso_id)
+		if err == nil {
+			auditlog.Log(c.ClientIP(), user.UUID, "auto provisioned account (OAuth)"+fmt.Sprintf(",sso_id: %s", sso_id), "login")
+		}
+	}
 	if err != nil {
 		c.JSON(401, gin.H{
 			"status":  "error",
@@ -112,3 +124,26 @@ func OAuthCallback(c *gin.Context) {
 	auditlog.Log(c.ClientIP(), user.UUID, "logged in (OAuth)", "login")
 	c.Redirect(302, "/admin")
 }
+
+// provisionExternalAccount 为外部账号创建本地用户，用户名冲突时追加随机后缀
+func provisionExternalAccount(username, ssoID string) (models.User, error) {
+	if username == "" {
+		username = ssoID
+	}
+	// 按字符截断，避免截断多字节字符
+	if runes := []rune(username); len(runes) > 40 {
+		username = string(runes[:40])
+	}
+	user, err := accounts.CreateAccount(username, utils.GeneratePassword())
+	if err != nil {
+		user, err = accounts.CreateAccount(username+"_"+utils.GenerateRandomString(6), utils.GeneratePassword())
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+	if err := accounts.BindingExternalAccount(user.UUID, ssoID); err != nil {
+		return models.User{}, err
+	}
+	user.SSOID = ssoID
+	return user, nil
+}
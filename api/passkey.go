@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/database/accounts"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/webauthn"
+)
+
+// PasskeyCredential 浏览器 PublicKeyCredential.toJSON() 的结果，二进制字段均为 base64url
+type PasskeyCredential struct {
+	ID       string `json:"id"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// PasskeyFinishRequest 完成注册或登录仪式的请求
+type PasskeyFinishRequest struct {
+	SessionID  string            `json:"session_id" binding:"required"`
+	Name       string            `json:"name"`
+	Credential PasskeyCredential `json:"credential"`
+}
+
+// DecodeBase64URL 兼容带或不带填充的 base64url
+func DecodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// GetRelyingParty 根据请求推断 WebAuthn 依赖方，RPID 为不含端口的主机名
+func GetRelyingParty(c *gin.Context) webauthn.RelyingParty {
+	host := c.Request.Host
+	rpID := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		rpID = h
+	}
+	return webauthn.RelyingParty{RPID: rpID, Origin: utils.GetScheme(c) + "://" + host}
+}
+
+// POST /api/login/passkey/begin
+func BeginPasskeyLogin(c *gin.Context) {
+	sessionID, challenge, err := webauthn.Begin("")
+	if err != nil {
+		RespondErrorT(c, http.StatusInternalServerError, "passkey.create_challenge_failed", err)
+		return
+	}
+	RespondSuccess(c, gin.H{
+		"session_id": sessionID,
+		"public_key": gin.H{
+			"challenge":        base64.RawURLEncoding.EncodeToString(challenge),
+			"rpId":             GetRelyingParty(c).RPID,
+			"timeout":          webauthn.Timeout.Milliseconds(),
+			"userVerification": "required",
+		},
+	})
+}
+
+// POST /api/login/passkey/finish
+func FinishPasskeyLogin(c *gin.Context) {
+	var req PasskeyFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondErrorT(c, http.StatusBadRequest, "common.invalid_request_body_with", err)
+		return
+	}
+	if !checkLoginAllowed(c, "") {
+		return
+	}
+	_, challenge, ok := webauthn.Finish(req.SessionID)
+	if !ok {
+		RespondErrorT(c, http.StatusBadRequest, "passkey.session_expired")
+		return
+	}
+	cred, err := accounts.GetWebAuthnCredentialByID(strings.TrimRight(req.Credential.ID, "="))
+	if err != nil {
+		recordLoginFailure(c, "", "unknown passkey")
+		RespondErrorT(c, http.StatusUnauthorized, "passkey.unknown")
+		return
+	}
+	clientData, err1 := DecodeBase64URL(req.Credential.Response.ClientDataJSON)
+	authData, err2 := DecodeBase64URL(req.Credential.Response.AuthenticatorData)
+	signature, err3 := DecodeBase64URL(req.Credential.Response.Signature)
+	if err1 != nil || err2 != nil || err3 != nil {
+		RespondErrorT(c, http.StatusBadRequest, "passkey.invalid_encoding")
+		return
+	}
+	signCount, err := webauthn.VerifyAssertion(GetRelyingParty(c), challenge,
+		webauthn.Credential{PublicKey: cred.PublicKey, SignCount: cred.SignCount},
+		clientData, authData, signature, true)
+	if err != nil {
+		recordLoginFailure(c, "", "passkey verification failed: "+err.Error())
+		RespondErrorT(c, http.StatusUnauthorized, "passkey.verify_failed")
+		return
+	}
+	recordLoginSuccess(c, "")
+	accounts.UpdateWebAuthnSignCount(cred.Id, signCount)
+
+	session, err := accounts.CreateSession(cred.UserUUID, 2592000, c.Request.UserAgent(), c.ClientIP(), "passkey")
+	if err != nil {
+		RespondErrorT(c, http.StatusInternalServerError, "login.create_session_failed", err)
+		return
+	}
+	c.SetCookie("session_token", session, 2592000, "/", "", false, true)
+	auditlog.Log(c.ClientIP(), cred.UserUUID, "logged in (passkey: "+cred.Name+")", "login")
+	RespondSuccess(c, gin.H{"set-cookie": gin.H{"session_token": session}})
+}
@@ -8,7 +8,7 @@ import (
 func GetPublicSettings(c *gin.Context) {
 	p, e := database.GetPublicInfo()
 	if e != nil {
-		RespondError(c, 500, e.Error())
+		RespondErrorOf(c, 500, e)
 		return
 	}
 	RespondSuccess(c, p)
@@ -7,44 +7,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/api"
-	"github.com/komari-monitor/komari/database/accounts"
-	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
 	records "github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils/exposure"
 )
 
 func GetRecordsByUUID(c *gin.Context) {
 	uuid := c.Query("uuid")
 	loadType := c.Query("load_type")
 
-	// 登录状态检查
-	isLogin := false
-	session, _ := c.Cookie("session_token")
-	_, err := accounts.GetUserBySession(session)
-	if err == nil {
-		isLogin = true
-	}
-
-	// 仅在未登录时需要 Hidden 信息做过滤
-	hiddenMap := map[string]bool{}
-	if !isLogin {
-		var hiddenClients []models.Client
-		db := dbcore.GetDBInstance()
-		_ = db.Select("uuid").Where("hidden = ?", true).Find(&hiddenClients).Error
-		for _, cli := range hiddenClients {
-			hiddenMap[cli.UUID] = true
-		}
-
-		if hiddenMap[uuid] {
-			api.RespondError(c, 400, "UUID is required") //防止未登录用户获取隐藏客户端数据
-			return
-		}
+	// 按登录状态与访问的面板过滤隐藏节点
+	view := api.RequestView(c)
+	if api.HiddenClients(view)[uuid] {
+		api.RespondErrorT(c, 400, "common.uuid_required") //防止未登录用户获取隐藏客户端数据
+		return
 	}
 
 	hours := c.Query("hours")
 	if uuid == "" {
-		api.RespondError(c, 400, "UUID is required")
+		api.RespondErrorT(c, 400, "common.uuid_required")
 		return
 	}
 	if hours == "" {
@@ -53,7 +35,7 @@ func GetRecordsByUUID(c *gin.Context) {
 
 	hoursInt, err := strconv.Atoi(hours)
 	if err != nil {
-		api.RespondError(c, 400, "Invalid hours parameter")
+		api.RespondErrorT(c, 400, "record.invalid_hours")
 		return
 	}
 
@@ -65,15 +47,18 @@ func GetRecordsByUUID(c *gin.Context) {
 	}
 
 	if !validLoadTypes[loadType] {
-		api.RespondError(c, 400, "Invalid load_type parameter")
+		api.RespondErrorT(c, 400, "record.invalid_load_type")
 		return
 	}
 
 	clientRecords, err := records.GetRecordsByClientAndTime(uuid, time.Now().Add(-time.Duration(hoursInt)*time.Hour), time.Now())
 	if err != nil {
-		api.RespondError(c, 500, "Failed to fetch records: "+err.Error())
+		api.RespondErrorT(c, 500, "record.fetch_failed", err)
 		return
 	}
+	for i := range clientRecords {
+		view.Record(&clientRecords[i])
+	}
 
 	// 准备基本响应
 	response := gin.H{
@@ -195,17 +180,13 @@ func GetPingRecords(c *gin.Context) {
 
 	// 必须提供 uuid 或 task_id 其中至少一个
 	if uuid == "" && taskIdStr == "" {
-		api.RespondError(c, 400, "UUID or task_id is required")
+		api.RespondErrorT(c, 400, "record.uuid_or_task_required")
 		return
 	}
 
-	// 登录状态检查
-	isLogin := false
-	session, _ := c.Cookie("session_token")
-	_, err := accounts.GetUserBySession(session)
-	if err == nil {
-		isLogin = true
-	}
+	// 按登录状态与访问的面板过滤隐藏节点
+	view := api.RequestView(c)
+	isLogin := view.Unrestricted()
 
 	type RecordsResp struct {
 		TaskId uint   `json:"task_id,omitempty"`
@@ -226,26 +207,15 @@ func GetPingRecords(c *gin.Context) {
 		Tasks     []gin.H           `json:"tasks,omitempty"`
 	}
 	var records []models.PingRecord
-	hiddenMap := map[string]bool{}
+	hiddenMap := api.HiddenClients(view)
 	response := &Resp{
 		Count:   0,
 		Records: []RecordsResp{},
 	}
 
-	// 仅在未登录时需要 Hidden 信息做过滤
-	if !isLogin {
-		var hiddenClients []models.Client
-		db := dbcore.GetDBInstance()
-		_ = db.Select("uuid").Where("hidden = ?", true).Find(&hiddenClients).Error
-		for _, cli := range hiddenClients {
-			hiddenMap[cli.UUID] = true
-		}
-		if uuid != "" {
-			if hiddenMap[uuid] {
-				api.RespondSuccess(c, response) // 对于尝试获取隐藏uuid一键哈气
-				return
-			}
-		}
+	if uuid != "" && hiddenMap[uuid] {
+		api.RespondSuccess(c, response) // 对于尝试获取隐藏uuid一键哈气
+		return
 	}
 
 	hours := c.Query("hours")
@@ -267,7 +237,7 @@ func GetPingRecords(c *gin.Context) {
 	if taskIdStr != "" {
 		taskId, err = strconv.Atoi(taskIdStr)
 		if err != nil {
-			api.RespondError(c, 400, "Invalid task_id parameter")
+			api.RespondErrorT(c, 400, "record.invalid_task_id")
 			return
 		}
 	}
@@ -275,7 +245,7 @@ func GetPingRecords(c *gin.Context) {
 	// 查询记录，现在支持 uuid + task_id 组合查询
 	records, err = tasks.GetPingRecords(uuid, taskId, startTime, endTime)
 	if err != nil {
-		api.RespondError(c, 500, "Failed to fetch ping records: "+err.Error())
+		api.RespondErrorT(c, 500, "record.fetch_ping_failed", err)
 		return
 	}
 
@@ -357,7 +327,7 @@ func GetPingRecords(c *gin.Context) {
 		// 获取所有 pingTasks
 		pingTasks, err := tasks.GetAllPingTasks()
 		if err != nil {
-			api.RespondError(c, 500, "Failed to fetch ping tasks: "+err.Error())
+			api.RespondErrorT(c, 500, "record.fetch_ping_tasks_failed", err)
 			return
 		}
 
@@ -434,7 +404,16 @@ func GetPingRecords(c *gin.Context) {
 			
 			// 如果是仅 task_id 查询，添加客户端列表信息
 			if uuid == "" && taskId != -1 {
-				taskInfo["clients"] = t.Clients
+				visible := make([]string, 0, len(t.Clients))
+				for _, client := range t.Clients {
+					if !hiddenMap[client] {
+						visible = append(visible, client)
+					}
+				}
+				taskInfo["clients"] = visible
+			}
+			if view.Allows(exposure.FieldPingTarget) {
+				taskInfo["target"] = t.Target
 			}
 			
 			tasksList = append(tasksList, taskInfo)
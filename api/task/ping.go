@@ -19,7 +19,7 @@ type PublicPingTask struct {
 func GetPublicPingTasks(c *gin.Context) {
 	tasks, err := tasks.GetAllPingTasks()
 	if err != nil {
-		api.RespondError(c, http.StatusInternalServerError, err.Error())
+		api.RespondErrorOf(c, http.StatusInternalServerError, err)
 		return
 	}
 
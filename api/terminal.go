@@ -10,6 +10,7 @@ import (
 	"github.com/komari-monitor/komari/database/auditlog"
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
 	"github.com/komari-monitor/komari/ws"
 )
 
@@ -62,7 +63,7 @@ func RequestTerminal(c *gin.Context) {
 	})
 
 	if ws.GetConnectedClients()[uuid] == nil {
-		conn.WriteMessage(1, []byte("Client offline!\n被控端离线!"))
+		conn.WriteMessage(1, []byte(i18n.T(RequestLanguage(c), "terminal.client_offline")))
 		conn.Close()
 		TerminalSessionsMutex.Lock()
 		delete(TerminalSessions, id)
@@ -7,10 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/komari-monitor/komari/common"
-	"github.com/komari-monitor/komari/database/accounts"
 	"github.com/komari-monitor/komari/database/config"
-	"github.com/komari-monitor/komari/database/dbcore"
-	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/ws"
 )
 
@@ -37,28 +34,9 @@ func GetClients(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	// 初始化用户信息
-	var (
-		isLogin    = false
-		hiddenMap  = map[string]bool{}
-		session, _ = c.Cookie("session_token")
-	)
-
-	// 登录状态检查
-	_, err = accounts.GetUserBySession(session)
-	if err == nil {
-		isLogin = true
-	}
-
-	// 仅在未登录时需要 Hidden 信息做过滤
-	if !isLogin {
-		var hiddenClients []models.Client
-		db := dbcore.GetDBInstance()
-		_ = db.Select("uuid").Where("hidden = ?", true).Find(&hiddenClients).Error
-		for _, cli := range hiddenClients {
-			hiddenMap[cli.UUID] = true
-		}
-	}
+	// 按登录状态与访问的面板决定可见的节点与字段
+	view := RequestView(c)
+	hiddenMap := HiddenClients(view)
 
 	// 请求
 	for {
@@ -89,7 +67,7 @@ func GetClients(c *gin.Context) {
 
 		// 在线客户端uuid列表（WebSocket 与非 WebSocket）
 		for _, key := range ws.GetAllOnlineUUIDs() {
-			if !isLogin && hiddenMap[key] {
+			if hiddenMap[key] {
 				continue
 			}
 			if uuID != "" && key != uuID {
@@ -100,18 +78,19 @@ func GetClients(c *gin.Context) {
 
 		//过往节点数据信息
 		for key, report := range ws.GetLatestReport() {
-			if !isLogin && hiddenMap[key] {
+			if hiddenMap[key] {
 				continue
 			}
 			if uuID != "" && key != uuID {
 				continue
 			}
 
-			report.UUID = "" // 不暴露 uuid
-			if report.CPU.Usage == 0 {
-				report.CPU.Usage = 0.01
+			filtered := view.Report(report)
+			filtered.UUID = "" // 不暴露 uuid
+			if filtered.CPU.Usage == 0 {
+				filtered.CPU.Usage = 0.01
 			}
-			resp.Data[key] = *report
+			resp.Data[key] = filtered
 		}
 
 		err = conn.WriteJSON(gin.H{"status": "success", "data": resp})
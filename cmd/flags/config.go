@@ -11,4 +11,16 @@ var (
 	DatabaseName string // MySQL/其他数据库名称
 
 	Listen string
+	// 受信任的反向代理（逗号分隔的 IP / CIDR），只有来自这些地址的 X-Forwarded-For 等头会被采信
+	TrustedProxies string
+
+	// HTTPS
+	TLSCert       string   // 证书文件路径，修改后自动重载
+	TLSKey        string   // 私钥文件路径
+	ACMEDomains   []string // 使用 ACME 自动申请证书的域名
+	ACMEEmail     string
+	ACMEDirectory string // ACME 目录地址，默认 Let's Encrypt
+	ACMECACert    string // 额外信任的 ACME 服务器 CA，用于 Pebble 等测试服务器
+	HTTPListen    string // 明文 HTTP 监听地址，用于跳转 HTTPS 与 HTTP-01 验证
+	AgentMTLS     bool   // 启用内置 CA，Agent 可使用客户端证书认证
 )
@@ -14,6 +14,7 @@ import (
 	apiClient "github.com/komari-monitor/komari/api/client"
 	"github.com/komari-monitor/komari/common"
 	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
@@ -202,7 +203,7 @@ func (s *nezhaCompatServer) ReportSystemState(stream proto.NezhaService_ReportSy
 			return err
 		}
 		// refresh presence TTL on every frame
-		ws.KeepAlivePresence(uuid, connID, 30*time.Second)
+		ws.KeepAlivePresence(uuid, connID, clients.ReadWait(uuid))
 		if err := ingestState(uuid, st); err != nil {
 			// still ack to avoid client stuck; log error
 			log.Printf("Nezha ingest state error: %v", err)
@@ -239,7 +240,7 @@ func (s *nezhaCompatServer) RequestTask(stream proto.NezhaService_RequestTaskSer
 				return
 			}
 			// refresh presence TTL when result received
-			ws.KeepAlivePresence(uuid, connID, 30*time.Second)
+			ws.KeepAlivePresence(uuid, connID, clients.ReadWait(uuid))
 		}
 	}()
 	// send heartbeat tasks periodically
@@ -367,37 +368,36 @@ func (s *nezhaCompatServer) ReportGeoIP(ctx context.Context, in *proto.GeoIP) (*
 		"updated_at": time.Now(),
 	}
 	var iso string
+	var previous *models.Client
+	var exist models.Client
+	if err := dbcore.GetDBInstance().Where("uuid = ?", uuid).First(&exist).Error; err == nil {
+		previous = &exist
+	}
 	if in != nil && in.Ip != nil {
-		if v4 := strings.TrimSpace(in.Ip.Ipv4); v4 != "" {
+		v4 := strings.TrimSpace(in.Ip.Ipv4)
+		v6 := strings.TrimSpace(in.Ip.Ipv6)
+		if v4 != "" {
 			updates["ipv4"] = v4
-			if cfg, err := config.Get(); err == nil && cfg.GeoIpEnabled {
-				if ip := net.ParseIP(v4); ip != nil {
-					if gi, _ := geoip.GetGeoInfo(ip); gi != nil {
-						iso = gi.ISOCode
-					}
-				}
-			}
 		}
-		if v6 := strings.TrimSpace(in.Ip.Ipv6); v6 != "" {
+		if v6 != "" {
 			updates["ipv6"] = v6
-			if iso == "" { // 优先使用 v4 的国家码
-				if cfg, err := config.Get(); err == nil && cfg.GeoIpEnabled {
-					if ip := net.ParseIP(v6); ip != nil {
-						if gi, _ := geoip.GetGeoInfo(ip); gi != nil {
-							iso = gi.ISOCode
-						}
-					}
+		}
+		if cfg, err := config.Get(); err == nil && cfg.GeoIpEnabled {
+			// 优先使用 v4 的地理信息，国家码回传给 Agent，其余字段写入客户端
+			if gi := geoip.LookupClientGeo(v4, v6); gi != nil {
+				iso = gi.ISOCode
+				for k, v := range geoip.GeoUpdates(gi) {
+					updates[k] = v
 				}
 			}
 		}
 	}
-	if iso != "" {
-		// UI 使用旗帜 emoji
-		updates["region"] = geoip.GetRegionUnicodeEmoji(iso)
-	}
 	if len(updates) > 0 {
 		_ = dbcore.GetDBInstance().Model(&models.Client{}).Where("uuid = ?", uuid).Updates(updates).Error
 	}
+	if in != nil && in.Ip != nil {
+		notifier.RecordClientIP(uuid, previous, strings.TrimSpace(in.Ip.Ipv4), strings.TrimSpace(in.Ip.Ipv6))
+	}
 	// 回写 GeoIP（包含国家码与面板启动时间）
 	resp := &proto.GeoIP{Use6: in.GetUse6(), Ip: in.GetIp(), CountryCode: iso, DashboardBootTime: s.bootTime}
 	return resp, nil
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -33,9 +35,14 @@ import (
 	d_notification "github.com/komari-monitor/komari/database/notification"
 	"github.com/komari-monitor/komari/database/records"
 	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/database/traffic"
 	"github.com/komari-monitor/komari/public"
 	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/agentca"
+	"github.com/komari-monitor/komari/utils/agentupdate"
+	"github.com/komari-monitor/komari/utils/certs"
 	"github.com/komari-monitor/komari/utils/cloudflared"
+	"github.com/komari-monitor/komari/utils/filetransfer"
 	"github.com/komari-monitor/komari/utils/geoip"
 	"github.com/komari-monitor/komari/utils/messageSender"
 	"github.com/komari-monitor/komari/utils/notifier"
@@ -60,9 +67,29 @@ func init() {
 	// 从环境变量获取监听地址
 	listenAddr := GetEnv("KOMARI_LISTEN", "0.0.0.0:25774")
 	ServerCmd.PersistentFlags().StringVarP(&flags.Listen, "listen", "l", listenAddr, "监听地址 [env: KOMARI_LISTEN]")
+	ServerCmd.PersistentFlags().StringVar(&flags.TrustedProxies, "trusted-proxies", GetEnv("KOMARI_TRUSTED_PROXIES", ""), "受信任的反向代理 IP/CIDR，逗号分隔；为空时信任所有代理，设为 none 则不信任任何转发头 [env: KOMARI_TRUSTED_PROXIES]")
+	pf := ServerCmd.PersistentFlags()
+	pf.StringVar(&flags.TLSCert, "tls-cert", GetEnv("KOMARI_TLS_CERT", ""), "TLS 证书文件，文件更新后自动重载 [env: KOMARI_TLS_CERT]")
+	pf.StringVar(&flags.TLSKey, "tls-key", GetEnv("KOMARI_TLS_KEY", ""), "TLS 私钥文件 [env: KOMARI_TLS_KEY]")
+	pf.StringSliceVar(&flags.ACMEDomains, "acme-domains", splitEnvList(GetEnv("KOMARI_ACME_DOMAINS", "")), "通过 ACME 自动申请证书的域名，逗号分隔 [env: KOMARI_ACME_DOMAINS]")
+	pf.StringVar(&flags.ACMEEmail, "acme-email", GetEnv("KOMARI_ACME_EMAIL", ""), "ACME 账户邮箱 [env: KOMARI_ACME_EMAIL]")
+	pf.StringVar(&flags.ACMEDirectory, "acme-directory", GetEnv("KOMARI_ACME_DIRECTORY", ""), "ACME 目录地址，默认 Let's Encrypt [env: KOMARI_ACME_DIRECTORY]")
+	pf.StringVar(&flags.ACMECACert, "acme-ca-cert", GetEnv("KOMARI_ACME_CA_CERT", ""), "额外信任的 ACME 服务器 CA 证书，例如 Pebble [env: KOMARI_ACME_CA_CERT]")
+	pf.BoolVar(&flags.AgentMTLS, "agent-mtls", strings.EqualFold(GetEnv("KOMARI_AGENT_MTLS", "false"), "true"), "启用 Agent 双向 TLS：注册时签发客户端证书，并接受证书代替 token 认证，需要启用 HTTPS [env: KOMARI_AGENT_MTLS]")
+	pf.StringVar(&flags.HTTPListen, "http-listen", GetEnv("KOMARI_HTTP_LISTEN", ""), "启用 HTTPS 时的明文 HTTP 监听地址，用于跳转 HTTPS 与 HTTP-01 验证，例如 0.0.0.0:80 [env: KOMARI_HTTP_LISTEN]")
 	RootCmd.AddCommand(ServerCmd)
 }
 
+func splitEnvList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func RunServer() {
 	// #region 初始化
 	if err := os.MkdirAll("./data", os.ModePerm); err != nil {
@@ -135,13 +162,19 @@ func RunServer() {
 	}
 
 	r := gin.Default()
+	if err := setTrustedProxies(r, flags.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	// 动态 CORS 中间件
 
 	DynamicCorsEnabled = conf.AllowCors
 	config.Subscribe(func(event config.ConfigEvent) {
 		DynamicCorsEnabled = event.New.AllowCors
-		if event.New.GeoIpProvider != event.Old.GeoIpProvider {
+		if event.New.GeoIpProvider != event.Old.GeoIpProvider ||
+			event.New.GeoIpCountryDb != event.Old.GeoIpCountryDb ||
+			event.New.GeoIpCityDb != event.Old.GeoIpCityDb ||
+			event.New.GeoIpAsnDb != event.Old.GeoIpAsnDb {
 			go geoip.InitGeoIp()
 		}
 		if event.New.NotificationMethod != event.Old.NotificationMethod {
@@ -164,6 +197,7 @@ func RunServer() {
 		c.Next()
 	})
 
+	r.Use(api.IPAccessMiddleware())
 	r.Use(api.PrivateSiteMiddleware())
 
 	r.Use(func(c *gin.Context) {
@@ -178,6 +212,8 @@ func RunServer() {
 	})
 	// #region 公开路由
 	r.POST("/api/login", api.Login)
+	r.POST("/api/login/passkey/begin", api.BeginPasskeyLogin)
+	r.POST("/api/login/passkey/finish", api.FinishPasskeyLogin)
 	r.GET("/api/me", api.GetMe)
 	r.GET("/api/clients", api.GetClients)
 	r.GET("/api/nodes", api.GetNodesInformation)
@@ -196,13 +232,18 @@ func RunServer() {
 
 	// #region Agent
 	r.POST("/api/clients/register", client.RegisterClient)
+	r.GET("/api/clients/ca", client.GetAgentCA)
+	r.GET("/api/clients/crl", client.GetAgentCRL)
 	tokenAuthrized := r.Group("/api/clients", api.TokenAuthMiddleware())
 	{
 		tokenAuthrized.GET("/report", client.WebSocketReport) // websocket
 		tokenAuthrized.POST("/uploadBasicInfo", client.UploadBasicInfo)
 		tokenAuthrized.POST("/report", client.UploadReport)
+		tokenAuthrized.POST("/metrics", client.PushMetrics)
 		tokenAuthrized.GET("/terminal", client.EstablishConnection)
 		tokenAuthrized.POST("/task/result", client.TaskResult)
+		tokenAuthrized.POST("/certificate", client.RenewCertificate)
+		tokenAuthrized.GET("/agent/binary/:id", client.DownloadAgentBinary)
 	}
 	// #region 管理员
 	adminAuthrized := r.Group("/api/admin", api.AdminAuthMiddleware())
@@ -214,6 +255,7 @@ func RunServer() {
 		{
 			testGroup.GET("/geoip", test.TestGeoIp)
 			testGroup.POST("/sendMessage", test.TestSendMessage)
+			testGroup.POST("/previewTemplate", test.PreviewTemplate)
 		}
 		// update
 		updateGroup := adminAuthrized.Group("/update")
@@ -233,15 +275,42 @@ func RunServer() {
 			taskGroup.GET("/:task_id/result/:uuid", admin.GetSpecificTaskResult)
 			taskGroup.GET("/client/:uuid", admin.GetTasksByClientId)
 		}
+		// agent update
+		agentGroup := adminAuthrized.Group("/agent")
+		{
+			agentGroup.GET("/versions", admin.GetAgentVersions)
+			agentGroup.GET("/binaries", admin.ListAgentBinaries)
+			agentGroup.POST("/binaries", admin.AddAgentBinary)
+			agentGroup.POST("/binaries/:id/remove", admin.DeleteAgentBinary)
+			agentGroup.GET("/rollouts", admin.ListAgentRollouts)
+			agentGroup.POST("/rollouts", admin.CreateAgentRollout)
+			agentGroup.GET("/rollouts/:id", admin.GetAgentRollout)
+			agentGroup.POST("/rollouts/:id/:action", admin.ControlAgentRollout)
+		}
+		// file transfer
+		transferGroup := adminAuthrized.Group("/transfer")
+		{
+			transferGroup.GET("", admin.ListTransfers)
+			transferGroup.POST("/upload", admin.UploadFile)
+			transferGroup.POST("/download", admin.DownloadFile)
+			transferGroup.GET("/:id", admin.GetTransfer)
+			transferGroup.GET("/:id/file", admin.GetTransferFile)
+			transferGroup.POST("/:id/resume", admin.ResumeTransfer)
+			transferGroup.POST("/:id/remove", admin.DeleteTransfer)
+		}
 		// settings
 		settingsGroup := adminAuthrized.Group("/settings")
 		{
 			settingsGroup.GET("/", admin.GetSettings)
 			settingsGroup.POST("/", admin.EditSettings)
+			settingsGroup.GET("/exposure", admin.GetExposurePolicy)
 			settingsGroup.POST("/oidc", admin.SetOidcProvider)
 			settingsGroup.GET("/oidc", admin.GetOidcProvider)
 			settingsGroup.POST("/message-sender", admin.SetMessageSenderProvider)
 			settingsGroup.GET("/message-sender", admin.GetMessageSenderProvider)
+			settingsGroup.GET("/message-template", admin.ListNotificationTemplates)
+			settingsGroup.POST("/message-template", admin.SaveNotificationTemplate)
+			settingsGroup.POST("/message-template/delete", admin.DeleteNotificationTemplates)
 		}
 		// themes
 		themeGroup := adminAuthrized.Group("/theme")
@@ -252,16 +321,62 @@ func RunServer() {
 			themeGroup.GET("/set", admin.SetTheme)
 			themeGroup.POST("/update", admin.UpdateTheme)
 			themeGroup.POST("/settings", admin.UpdateThemeSettings)
+			themeGroup.PUT("/settings/upload", admin.UploadThemeAsset)
+			themeGroup.POST("/rollback", admin.RollbackTheme)
+			themeGroup.GET("/market", admin.ThemeMarket)
+			themeGroup.POST("/market/install", admin.InstallMarketTheme)
+		}
+		// public dashboards
+		dashboardGroup := adminAuthrized.Group("/dashboard")
+		{
+			dashboardGroup.GET("/", admin.GetAllDashboards)
+			dashboardGroup.POST("/add", admin.AddDashboard)
+			dashboardGroup.POST("/edit", admin.EditDashboard)
+			dashboardGroup.POST("/delete", admin.DeleteDashboard)
+			dashboardGroup.POST("/rotate-token", admin.RotateDashboardToken)
+		}
+		// threshold actions
+		actionGroup := adminAuthrized.Group("/action")
+		{
+			actionGroup.GET("/", admin.GetAllThresholdActions)
+			actionGroup.POST("/add", admin.AddThresholdAction)
+			actionGroup.POST("/edit", admin.EditThresholdAction)
+			actionGroup.POST("/delete", admin.DeleteThresholdAction)
+		}
+		// billing
+		billingGroup := adminAuthrized.Group("/billing")
+		{
+			billingGroup.GET("/summary", admin.GetBillingSummary)
+			billingGroup.GET("/rates", admin.GetExchangeRates)
+			billingGroup.GET("/ledger", admin.GetRenewalLedger)
+			billingGroup.GET("/export", admin.ExportBilling)
 		}
 		// clients
 		clientGroup := adminAuthrized.Group("/client")
 		{
 			clientGroup.POST("/add", admin.AddClient)
 			clientGroup.GET("/list", admin.ListClients)
+			clientGroup.GET("/providers", admin.GetProviderBreakdown)
 			clientGroup.GET("/:uuid", admin.GetClient)
 			clientGroup.POST("/:uuid/edit", admin.EditClient)
 			clientGroup.POST("/:uuid/remove", admin.RemoveClient)
 			clientGroup.GET("/:uuid/token", admin.GetClientToken)
+			clientGroup.GET("/:uuid/ip-history", admin.GetClientIPHistory)
+			clientGroup.GET("/:uuid/traffic", admin.GetClientTraffic)
+			clientGroup.GET("/:uuid/certificate", admin.ListClientCertificates)
+			clientGroup.POST("/:uuid/certificate", admin.IssueClientCertificate)
+			clientGroup.POST("/:uuid/certificate/revoke", admin.RevokeClientCertificates)
+			clientGroup.GET("/:uuid/transfer-policy", admin.GetTransferPolicy)
+			clientGroup.GET("/:uuid/config", admin.GetClientCollectConfig)
+			clientGroup.POST("/:uuid/config", admin.EditClientCollectConfig)
+			clientGroup.POST("/:uuid/config/reset", admin.ResetClientCollectConfig)
+			clientGroup.GET("/group-config", admin.ListGroupCollectConfigs)
+			clientGroup.POST("/group-config/:group", admin.EditGroupCollectConfig)
+			clientGroup.POST("/group-config/:group/remove", admin.DeleteGroupCollectConfig)
+			clientGroup.POST("/:uuid/transfer-policy", admin.EditTransferPolicy)
+			clientGroup.GET("/:uuid/metrics", admin.ListCustomMetrics)
+			clientGroup.POST("/:uuid/metrics/:name/public", admin.SetCustomMetricPublic)
+			clientGroup.POST("/:uuid/metrics/:name/remove", admin.DeleteCustomMetric)
 			clientGroup.POST("/order", admin.OrderWeight)
 			// client terminal
 			clientGroup.GET("/:uuid/terminal", api.RequestTerminal)
@@ -290,6 +405,13 @@ func RunServer() {
 			two_factorGroup.GET("/generate", admin.Generate2FA)
 			two_factorGroup.POST("/enable", admin.Enable2FA)
 			two_factorGroup.POST("/disable", admin.Disable2FA)
+			two_factorGroup.GET("/passkey", admin.ListPasskeys)
+			two_factorGroup.POST("/passkey/register/begin", admin.BeginPasskeyRegistration)
+			two_factorGroup.POST("/passkey/register/finish", admin.FinishPasskeyRegistration)
+			two_factorGroup.POST("/passkey/edit", admin.EditPasskey)
+			two_factorGroup.POST("/passkey/remove", admin.RemovePasskey)
+			two_factorGroup.GET("/recovery", admin.GetRecoveryCodeStatus)
+			two_factorGroup.POST("/recovery/generate", admin.GenerateRecoveryCodes)
 		}
 		adminAuthrized.GET("/logs", log_api.GetLogs)
 
@@ -302,6 +424,10 @@ func RunServer() {
 			clipboardGroup.POST("/:id", clipboard.UpdateClipboard)
 			clipboardGroup.POST("/remove", clipboard.BatchDeleteClipboard)
 			clipboardGroup.POST("/:id/remove", clipboard.DeleteClipboard)
+			clipboardGroup.GET("/export", clipboard.ExportClipboard)
+			clipboardGroup.POST("/import", clipboard.ImportClipboard)
+			clipboardGroup.POST("/:id/exec", admin.ExecSnippet)
+			clipboardGroup.GET("/:id/tasks", clipboard.GetClipboardTasks)
 		}
 
 		notificationGroup := adminAuthrized.Group("/notification")
@@ -311,6 +437,9 @@ func RunServer() {
 			notificationGroup.POST("/offline/edit", notification.EditOfflineNotification)
 			notificationGroup.POST("/offline/enable", notification.EnableOfflineNotification)
 			notificationGroup.POST("/offline/disable", notification.DisableOfflineNotification)
+			// expire notifications
+			notificationGroup.GET("/expire", notification.ListExpireNotifications)
+			notificationGroup.POST("/expire/edit", notification.EditExpireNotification)
 			loadAlertGroup := notificationGroup.Group("/load")
 			{
 				loadAlertGroup.GET("/", notification.GetAllLoadNotifications)
@@ -344,9 +473,59 @@ func RunServer() {
 		Addr:    flags.Listen,
 		Handler: r,
 	}
-	log.Printf("Starting server on %s ...", flags.Listen)
+	var httpSrv *http.Server
+	tlsOptions := certs.Options{
+		CertFile:      flags.TLSCert,
+		KeyFile:       flags.TLSKey,
+		ACMEDomains:   flags.ACMEDomains,
+		ACMEEmail:     flags.ACMEEmail,
+		ACMEDirectory: flags.ACMEDirectory,
+		ACMECACert:    flags.ACMECACert,
+	}
+	if tlsOptions.Enabled() {
+		certManager, err := certs.New(tlsOptions)
+		if err != nil {
+			log.Fatalf("Failed to initialize TLS: %v", err)
+		}
+		srv.TLSConfig = certManager.TLSConfig()
+		if flags.AgentMTLS {
+			pool, err := agentca.Pool()
+			if err != nil {
+				log.Fatalf("Failed to load agent CA: %v", err)
+			}
+			// 浏览器与使用 token 的 Agent 不受影响，只有提供了证书的连接才会被校验
+			srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			srv.TLSConfig.ClientCAs = pool
+		}
+		if flags.HTTPListen != "" {
+			_, httpsPort, _ := net.SplitHostPort(flags.Listen)
+			httpSrv = &http.Server{
+				Addr:    flags.HTTPListen,
+				Handler: certManager.HTTPHandler(certs.RedirectHandler(httpsPort)),
+			}
+			log.Printf("Redirecting HTTP on %s to HTTPS ...", flags.HTTPListen)
+			go func() {
+				if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					OnFatal(err)
+					log.Fatalf("listen: %s\n", err)
+				}
+			}()
+		}
+		log.Printf("Starting HTTPS server on %s ...", flags.Listen)
+	} else {
+		if flags.AgentMTLS {
+			log.Println("Agent mTLS requires HTTPS (--tls-cert or --acme-domains), client certificates will not be verified")
+		}
+		log.Printf("Starting server on %s ...", flags.Listen)
+	}
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			OnFatal(err)
 			log.Fatalf("listen: %s\n", err)
 		}
@@ -357,6 +536,9 @@ func RunServer() {
 	OnShutdown()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	if httpSrv != nil {
+		httpSrv.Shutdown(ctx)
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -402,16 +584,21 @@ func DoScheduledWork() {
 			records.DeleteRecordBefore(time.Now().Add(-time.Hour * time.Duration(cfg.RecordPreserveTime)))
 			records.CompactRecord()
 			tasks.ClearTaskResultsByTimeBefore(time.Now().Add(-time.Hour * time.Duration(cfg.RecordPreserveTime)))
+			filetransfer.Cleanup(time.Now().Add(-time.Hour * time.Duration(cfg.RecordPreserveTime)))
 			tasks.DeletePingRecordsBefore(time.Now().Add(-time.Hour * time.Duration(cfg.PingRecordPreserveTime)))
 			auditlog.RemoveOldLogs()
 		case <-minute.C:
 			api.SaveClientReportToDB()
+			traffic.Flush()
 			if !cfg.RecordEnabled {
 				records.DeleteAll()
 				tasks.DeleteAllPingRecords()
 			}
 			// 每分钟检查一次流量提醒
 			go notifier.CheckTraffic()
+			go notifier.CheckExpiredActions()
+			go agentupdate.Tick()
+			go notifier.CheckPingAlerts()
 		}
 	}
 
@@ -419,6 +606,7 @@ func DoScheduledWork() {
 
 func OnShutdown() {
 	auditlog.Log("", "", "server is shutting down", "info")
+	traffic.Flush()
 	cloudflared.Kill()
 }
 
@@ -426,3 +614,23 @@ func OnFatal(err error) {
 	auditlog.Log("", "", "server encountered a fatal error: "+err.Error(), "error")
 	cloudflared.Kill()
 }
+
+// setTrustedProxies 配置 gin 采信转发头的代理列表，影响 c.ClientIP() 的结果
+func setTrustedProxies(r *gin.Engine, value string) error {
+	value = strings.TrimSpace(value)
+	// 未配置时保持 gin 默认行为（信任所有代理），兼容已有的反向代理与 cloudflared 部署
+	if value == "" {
+		log.Println("Trusted proxies are not configured, X-Forwarded-For from any source will be trusted. Set --trusted-proxies to restrict it")
+		return nil
+	}
+	if strings.EqualFold(value, "none") {
+		return r.SetTrustedProxies(nil)
+	}
+	var proxies []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return r.SetTrustedProxies(proxies)
+}
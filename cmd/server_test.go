@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"未配置时保持默认信任", "", "1.2.3.4"},
+		{"none 忽略转发头", "none", "10.0.0.1"},
+		{"受信任的代理采信转发头", "10.0.0.0/8", "1.2.3.4"},
+		{"不受信任的代理忽略转发头", "192.168.0.1", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := setTrustedProxies(r, tt.value); err != nil {
+				t.Fatalf("setTrustedProxies() error = %v", err)
+			}
+			r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:12345"
+			req.Header.Set("X-Forwarded-For", "1.2.3.4")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
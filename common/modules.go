@@ -9,23 +9,46 @@ type Message struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// CollectConfig Agent 的采集开关与上报间隔，通过上报 WebSocket 下发给 Agent
+type CollectConfig struct {
+	CPU         bool `json:"cpu"`
+	GPU         bool `json:"gpu"`
+	RAM         bool `json:"ram"`
+	SWAP        bool `json:"swap"`
+	LOAD        bool `json:"load"`
+	UPTIME      bool `json:"uptime"`
+	TEMP        bool `json:"temp"`
+	OS          bool `json:"os"`
+	DISK        bool `json:"disk"`
+	NET         bool `json:"net"`
+	PROCESS     bool `json:"process"`
+	Connections bool `json:"connections"`
+	Interval    int  `json:"interval"` // 上报间隔（秒）
+}
+
+// DefaultCollectConfig 未配置时 Agent 使用的默认值
+func DefaultCollectConfig() CollectConfig {
+	return CollectConfig{
+		CPU: true, GPU: true, RAM: true, SWAP: true, LOAD: true, UPTIME: true,
+		TEMP: true, OS: true, DISK: true, NET: true, PROCESS: true, Connections: true,
+		Interval: 1,
+	}
+}
+
+// ClientConfig 单个客户端的采集配置，优先于分组配置
 type ClientConfig struct {
-	ClientUUID  string    `json:"client_uuid" gorm:"type:uuid;primaryKey;foreignKey:ClientUUID;references:UUID;constraint:OnDelete:CASCADE"`
-	CPU         bool      `json:"cpu" gorm:"default:true"`
-	GPU         bool      `json:"gpu" gorm:"default:true"`
-	RAM         bool      `json:"ram" gorm:"default:true"`
-	SWAP        bool      `json:"swap" gorm:"default:true"`
-	LOAD        bool      `json:"load" gorm:"default:true"`
-	UPTIME      bool      `json:"uptime" gorm:"default:true"`
-	TEMP        bool      `json:"temp" gorm:"default:true"`
-	OS          bool      `json:"os" gorm:"default:true"`
-	DISK        bool      `json:"disk" gorm:"default:true"`
-	NET         bool      `json:"net" gorm:"default:true"`
-	PROCESS     bool      `json:"process" gorm:"default:true"`
-	Connections bool      `json:"connections" gorm:"default:true"`
-	Interval    int       `json:"interval" gorm:"default:3;check:Interval >= 1"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ClientUUID    string `json:"client_uuid" gorm:"type:varchar(36);primaryKey"`
+	CollectConfig `gorm:"embedded"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GroupConfig 分组的采集配置，用于分组内没有单独配置的客户端
+type GroupConfig struct {
+	Group         string `json:"group" gorm:"type:varchar(100);primaryKey"`
+	CollectConfig `gorm:"embedded"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // ClientInfo stores static client information
@@ -61,20 +84,35 @@ type IPAddress struct {
 	Ipv6 string `json:"ipv6"`
 }
 type Report struct {
-	UUID        string            `json:"uuid,omitempty"`
-	CPU         CPUReport         `json:"cpu"`
-	Ram         RamReport         `json:"ram"`
-	Swap        RamReport         `json:"swap"`
-	Load        LoadReport        `json:"load"`
-	Disk        DiskReport        `json:"disk"`
-	Network     NetworkReport     `json:"network"`
-	Connections ConnectionsReport `json:"connections"`
-	GPU         *GPUDetailReport  `json:"gpu,omitempty"` // 新增GPU详细信息
-	Uptime      int64             `json:"uptime"`
-	Process     int               `json:"process"`
-	Message     string            `json:"message"`
-	Method      string            `json:"method,omitempty"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	UUID        string                  `json:"uuid,omitempty"`
+	CPU         CPUReport               `json:"cpu"`
+	Ram         RamReport               `json:"ram"`
+	Swap        RamReport               `json:"swap"`
+	Load        LoadReport              `json:"load"`
+	Disk        DiskReport              `json:"disk"`
+	Network     NetworkReport           `json:"network"`
+	Connections ConnectionsReport       `json:"connections"`
+	GPU         *GPUDetailReport        `json:"gpu,omitempty"` // 新增GPU详细信息
+	Uptime      int64                   `json:"uptime"`
+	Process     int                     `json:"process"`
+	Custom      map[string]CustomMetric `json:"custom,omitempty"` // 自定义指标，键为指标名
+	Message     string                  `json:"message"`
+	Method      string                  `json:"method,omitempty"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// 自定义指标类型
+const (
+	CustomGauge   = "gauge"   // 瞬时值，例如队列长度
+	CustomCounter = "counter" // 单调递增的累计值，例如请求总数
+)
+
+// CustomMetric 单个自定义指标的取值
+type CustomMetric struct {
+	Type   string  `json:"type"`
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	Public bool    `json:"-"` // 是否对访客可见，由服务端按管理员设置填充
 }
 
 type CPUReport struct {
@@ -0,0 +1,59 @@
+package accounts
+
+import (
+	"strings"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeCount 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// normalizeRecoveryCode 忽略大小写与分隔符，方便用户输入
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "-", "")
+	return strings.ToUpper(strings.ReplaceAll(code, " ", ""))
+}
+
+// GenerateRecoveryCodes 生成新的一组恢复码并替换旧的，明文只在此返回一次
+func GenerateRecoveryCodes(uuid string) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw := strings.ToUpper(utils.GenerateRandomString(10))
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.RecoveryCode{UserUUID: uuid, Hash: hashPasswd(raw)})
+	}
+	db := dbcore.GetDBInstance()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_uuid = ?", uuid).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode 校验并消耗一个恢复码
+func UseRecoveryCode(uuid, code string) bool {
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return false
+	}
+	db := dbcore.GetDBInstance()
+	result := db.Where("user_uuid = ? AND hash = ?", uuid, hashPasswd(code)).Delete(&models.RecoveryCode{})
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// CountRecoveryCodes 剩余可用的恢复码数量
+func CountRecoveryCodes(uuid string) (count int64, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Model(&models.RecoveryCode{}).Where("user_uuid = ?", uuid).Count(&count).Error
+	return
+}
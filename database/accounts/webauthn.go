@@ -0,0 +1,49 @@
+package accounts
+
+import (
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// AddWebAuthnCredential 保存新注册的 passkey
+func AddWebAuthnCredential(cred *models.WebAuthnCredential) error {
+	db := dbcore.GetDBInstance()
+	return db.Create(cred).Error
+}
+
+// GetWebAuthnCredentials 获取用户的所有 passkey
+func GetWebAuthnCredentials(uuid string) (creds []models.WebAuthnCredential, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("user_uuid = ?", uuid).Order("id ASC").Find(&creds).Error
+	return
+}
+
+// GetWebAuthnCredentialByID 根据凭据 ID（base64url）查找 passkey
+func GetWebAuthnCredentialByID(credentialID string) (cred models.WebAuthnCredential, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("credential_id = ?", credentialID).First(&cred).Error
+	return
+}
+
+// UpdateWebAuthnSignCount 登录成功后更新签名计数器与最后使用时间
+func UpdateWebAuthnSignCount(id uint, signCount uint32) error {
+	db := dbcore.GetDBInstance()
+	return db.Model(&models.WebAuthnCredential{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"last_used_at": models.FromTime(time.Now()),
+	}).Error
+}
+
+// RenameWebAuthnCredential 修改 passkey 名称
+func RenameWebAuthnCredential(uuid string, id uint, name string) error {
+	db := dbcore.GetDBInstance()
+	return db.Model(&models.WebAuthnCredential{}).Where("id = ? AND user_uuid = ?", id, uuid).Update("name", name).Error
+}
+
+// DeleteWebAuthnCredential 删除用户的某个 passkey
+func DeleteWebAuthnCredential(uuid string, id uint) error {
+	db := dbcore.GetDBInstance()
+	return db.Where("id = ? AND user_uuid = ?", id, uuid).Delete(&models.WebAuthnCredential{}).Error
+}
@@ -0,0 +1,132 @@
+package agentupdate
+
+import (
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
+)
+
+// SaveBinary 保存 Agent 二进制文件信息，同一版本与平台已存在时覆盖
+func SaveBinary(b *models.AgentBinary) error {
+	db := dbcore.GetDBInstance()
+	var existing models.AgentBinary
+	if err := db.Where("version = ? AND os = ? AND arch = ?", b.Version, b.OS, b.Arch).First(&existing).Error; err == nil {
+		b.Id = existing.Id
+	}
+	b.CreatedAt = models.FromTime(time.Now())
+	return db.Save(b).Error
+}
+
+// ListBinaries 按版本列出全部二进制文件
+func ListBinaries() ([]models.AgentBinary, error) {
+	var list []models.AgentBinary
+	err := dbcore.GetDBInstance().Order("version DESC, os, arch").Find(&list).Error
+	return list, err
+}
+
+// GetBinary 根据 ID 获取二进制文件信息
+func GetBinary(id uint) (*models.AgentBinary, error) {
+	var b models.AgentBinary
+	if err := dbcore.GetDBInstance().First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// FindBinary 查找指定版本与平台的二进制文件
+func FindBinary(version, os, arch string) (*models.AgentBinary, error) {
+	var b models.AgentBinary
+	if err := dbcore.GetDBInstance().Where("version = ? AND os = ? AND arch = ?", version, os, arch).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// DeleteBinary 删除二进制文件信息
+func DeleteBinary(id uint) error {
+	return dbcore.GetDBInstance().Delete(&models.AgentBinary{}, id).Error
+}
+
+// CreateRollout 创建升级计划及其客户端列表
+func CreateRollout(r *models.AgentRollout) error {
+	return dbcore.GetDBInstance().Transaction(func(tx *gorm.DB) error {
+		targets := r.Targets
+		r.Targets = nil
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		for i := range targets {
+			targets[i].RolloutId = r.Id
+		}
+		if len(targets) > 0 {
+			if err := tx.Create(&targets).Error; err != nil {
+				return err
+			}
+		}
+		r.Targets = targets
+		return nil
+	})
+}
+
+// GetRollout 获取升级计划及其客户端列表
+func GetRollout(id uint) (*models.AgentRollout, error) {
+	var r models.AgentRollout
+	if err := dbcore.GetDBInstance().Preload("Targets").First(&r, id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListRollouts 按创建时间倒序列出升级计划，不含客户端列表
+func ListRollouts() ([]models.AgentRollout, error) {
+	var list []models.AgentRollout
+	err := dbcore.GetDBInstance().Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+// ListActiveRollouts 列出运行中、已暂停或已终止的升级计划，包含客户端列表
+func ListActiveRollouts() ([]models.AgentRollout, error) {
+	var list []models.AgentRollout
+	err := dbcore.GetDBInstance().Preload("Targets").
+		Where("status IN ?", []string{models.RolloutRunning, models.RolloutPaused, models.RolloutHalted}).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+// GetActiveRolloutByGroup 获取分组中尚未结束的升级计划
+func GetActiveRolloutByGroup(group string) (*models.AgentRollout, error) {
+	var r models.AgentRollout
+	err := dbcore.GetDBInstance().
+		Where(map[string]interface{}{"group": group}).
+		Where("status IN ?", []string{models.RolloutRunning, models.RolloutPaused, models.RolloutHalted}).
+		First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateRollout 更新升级计划的字段
+func UpdateRollout(id uint, fields map[string]interface{}) error {
+	fields["updated_at"] = models.FromTime(time.Now())
+	return dbcore.GetDBInstance().Model(&models.AgentRollout{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateTarget 更新单个客户端的升级状态
+func UpdateTarget(id uint, fields map[string]interface{}) error {
+	return dbcore.GetDBInstance().Model(&models.AgentRolloutTarget{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// FindSentTarget 查找客户端在升级计划中已下发但尚未确认的记录
+func FindSentTarget(rolloutId uint, client string) (*models.AgentRolloutTarget, error) {
+	var t models.AgentRolloutTarget
+	err := dbcore.GetDBInstance().
+		Where("rollout_id = ? AND client = ? AND status = ?", rolloutId, client, models.RolloutTargetSent).
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,71 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/agentca"
+)
+
+// IssueClientCertificate 为客户端签发新的证书并记录序列号
+func IssueClientCertificate(clientUuid string) (agentca.Issued, error) {
+	issued, err := agentca.Issue(clientUuid)
+	if err != nil {
+		return agentca.Issued{}, err
+	}
+	db := dbcore.GetDBInstance()
+	err = db.Create(&models.ClientCertificate{
+		Serial:   issued.Serial,
+		Client:   clientUuid,
+		NotAfter: models.FromTime(issued.NotAfter),
+	}).Error
+	if err != nil {
+		return agentca.Issued{}, err
+	}
+	return issued, nil
+}
+
+// GetClientCertificates 获取客户端的所有证书记录
+func GetClientCertificates(clientUuid string) (certs []models.ClientCertificate, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("client = ?", clientUuid).Order("created_at ASC").Find(&certs).Error
+	return
+}
+
+// RevokeClientCertificates 吊销客户端的所有证书
+func RevokeClientCertificates(clientUuid string) error {
+	db := dbcore.GetDBInstance()
+	return db.Model(&models.ClientCertificate{}).
+		Where("client = ? AND revoked = ?", clientUuid, false).
+		Updates(map[string]interface{}{"revoked": true, "revoked_at": models.FromTime(time.Now())}).Error
+}
+
+// IsCertificateValid 证书已登记、未吊销且属于该客户端
+func IsCertificateValid(serial, clientUuid string) bool {
+	db := dbcore.GetDBInstance()
+	var cert models.ClientCertificate
+	if err := db.Where("serial = ?", serial).First(&cert).Error; err != nil {
+		return false
+	}
+	return !cert.Revoked && cert.Client == clientUuid
+}
+
+// GetCertificateRevocationList 生成当前的 CRL（PEM）
+func GetCertificateRevocationList() ([]byte, error) {
+	db := dbcore.GetDBInstance()
+	var revoked []models.ClientCertificate
+	// 已过期的证书无需继续出现在 CRL 中
+	if err := db.Where("revoked = ? AND not_after > ?", true, models.FromTime(time.Now())).Find(&revoked).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]agentca.Revoked, 0, len(revoked))
+	var number int64
+	for _, r := range revoked {
+		entries = append(entries, agentca.Revoked{Serial: r.Serial, RevokedAt: r.RevokedAt.ToTime()})
+		if ts := r.RevokedAt.ToTime().Unix(); ts > number {
+			number = ts
+		}
+	}
+	return agentca.CRL(entries, number)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/komari-monitor/komari/common"
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/records"
+	"github.com/komari-monitor/komari/database/traffic"
 	"github.com/komari-monitor/komari/utils"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -27,10 +29,41 @@ func DeleteClientConfig(clientUuid string) error {
 }
 func DeleteClient(clientUuid string) error {
 	db := dbcore.GetDBInstance()
+	// 先吊销该客户端的所有证书使其进入 CRL，避免后续清理失败时证书仍然有效
+	if err := RevokeClientCertificates(clientUuid); err != nil {
+		return err
+	}
 	err := db.Delete(&models.Client{}, "uuid = ?", clientUuid).Error
 	if err != nil {
 		return err
 	}
+	if err := DeleteClientIPHistory(clientUuid); err != nil {
+		return err
+	}
+	if err := db.Delete(&models.FileTransferPolicy{}, "client = ?", clientUuid).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(&models.ExpireNotification{}, "client = ?", clientUuid).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(&models.ExpireNotificationState{}, "client = ?", clientUuid).Error; err != nil {
+		return err
+	}
+	if err := traffic.DeleteClient(clientUuid); err != nil {
+		return err
+	}
+	if err := db.Delete(&models.PingAlertState{}, "client = ?", clientUuid).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(&models.ThresholdActionLog{}, "client = ?", clientUuid).Error; err != nil {
+		return err
+	}
+	if err := DeleteClientCollectConfig(clientUuid); err != nil {
+		return err
+	}
+	if err := records.DeleteClientCustomMetrics(clientUuid); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -315,5 +348,9 @@ func SaveClient(updates map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	if _, ok := updates["group"]; ok {
+		// 分组变化会影响生效的采集配置
+		resetIntervalCache()
+	}
 	return nil
 }
@@ -0,0 +1,180 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 采集配置的来源
+const (
+	ConfigSourceClient  = "client"
+	ConfigSourceGroup   = "group"
+	ConfigSourceDefault = "default"
+)
+
+// maxReportInterval 上报间隔上限（秒）
+const maxReportInterval = 3600
+
+// defaultReadWait 未配置上报间隔时，超过这个时间没有收到任何上报则认为客户端已离线
+const defaultReadWait = 11 * time.Second
+
+var (
+	intervalCache   = map[string]int{}
+	intervalCacheMu sync.RWMutex
+)
+
+// ValidateCollectConfig 检查采集配置
+func ValidateCollectConfig(cfg common.CollectConfig) error {
+	if cfg.Interval < 1 || cfg.Interval > maxReportInterval {
+		return fmt.Errorf("interval must be between 1 and %d seconds", maxReportInterval)
+	}
+	return nil
+}
+
+// GetEffectiveConfig 返回客户端实际生效的采集配置：单独配置优先，其次为所在分组的配置，最后为默认值
+func GetEffectiveConfig(uuid string) (common.CollectConfig, string, error) {
+	db := dbcore.GetDBInstance()
+	var cc common.ClientConfig
+	err := db.Where("client_uuid = ?", uuid).First(&cc).Error
+	if err == nil {
+		return cc.CollectConfig, ConfigSourceClient, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return common.CollectConfig{}, "", err
+	}
+	client, err := GetClientByUUID(uuid)
+	if err != nil {
+		return common.CollectConfig{}, "", err
+	}
+	if client.Group != "" {
+		var gc common.GroupConfig
+		err := db.Where(map[string]interface{}{"group": client.Group}).First(&gc).Error
+		if err == nil {
+			return gc.CollectConfig, ConfigSourceGroup, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.CollectConfig{}, "", err
+		}
+	}
+	return common.DefaultCollectConfig(), ConfigSourceDefault, nil
+}
+
+// SaveClientCollectConfig 保存客户端的单独配置
+func SaveClientCollectConfig(uuid string, cfg common.CollectConfig) error {
+	if err := ValidateCollectConfig(cfg); err != nil {
+		return err
+	}
+	now := time.Now()
+	cc := common.ClientConfig{ClientUUID: uuid, CollectConfig: cfg, CreatedAt: now, UpdatedAt: now}
+	db := dbcore.GetDBInstance()
+	var existing common.ClientConfig
+	if err := db.Where("client_uuid = ?", uuid).First(&existing).Error; err == nil {
+		cc.CreatedAt = existing.CreatedAt
+	}
+	defer resetIntervalCache()
+	return db.Save(&cc).Error
+}
+
+// DeleteClientCollectConfig 删除客户端的单独配置，之后使用分组配置或默认值
+func DeleteClientCollectConfig(uuid string) error {
+	defer resetIntervalCache()
+	return dbcore.GetDBInstance().Where("client_uuid = ?", uuid).Delete(&common.ClientConfig{}).Error
+}
+
+// ListGroupConfigs 列出全部分组配置
+func ListGroupConfigs() ([]common.GroupConfig, error) {
+	var list []common.GroupConfig
+	err := dbcore.GetDBInstance().Order(clause.OrderByColumn{Column: clause.Column{Name: "group"}}).Find(&list).Error
+	return list, err
+}
+
+// GetGroupConfig 获取分组配置，未配置时返回默认值
+func GetGroupConfig(group string) common.CollectConfig {
+	var gc common.GroupConfig
+	if err := dbcore.GetDBInstance().Where(map[string]interface{}{"group": group}).First(&gc).Error; err != nil {
+		return common.DefaultCollectConfig()
+	}
+	return gc.CollectConfig
+}
+
+// SaveGroupConfig 保存分组配置
+func SaveGroupConfig(group string, cfg common.CollectConfig) error {
+	if group == "" {
+		return fmt.Errorf("group is required")
+	}
+	if err := ValidateCollectConfig(cfg); err != nil {
+		return err
+	}
+	now := time.Now()
+	gc := common.GroupConfig{Group: group, CollectConfig: cfg, CreatedAt: now, UpdatedAt: now}
+	db := dbcore.GetDBInstance()
+	var existing common.GroupConfig
+	if err := db.Where(map[string]interface{}{"group": group}).First(&existing).Error; err == nil {
+		gc.CreatedAt = existing.CreatedAt
+	}
+	defer resetIntervalCache()
+	return db.Save(&gc).Error
+}
+
+// DeleteGroupConfig 删除分组配置
+func DeleteGroupConfig(group string) error {
+	defer resetIntervalCache()
+	return dbcore.GetDBInstance().Where(map[string]interface{}{"group": group}).Delete(&common.GroupConfig{}).Error
+}
+
+// GetGroupClientUUIDs 返回分组内的客户端
+func GetGroupClientUUIDs(group string) ([]string, error) {
+	var uuids []string
+	err := dbcore.GetDBInstance().Model(&models.Client{}).Where(map[string]interface{}{"group": group}).Pluck("uuid", &uuids).Error
+	return uuids, err
+}
+
+// ReportInterval 返回客户端生效的上报间隔（秒），未配置时返回 0。结果会被缓存，配置变更时清空。
+func ReportInterval(uuid string) int {
+	intervalCacheMu.RLock()
+	v, ok := intervalCache[uuid]
+	intervalCacheMu.RUnlock()
+	if ok {
+		return v
+	}
+	cfg, source, err := GetEffectiveConfig(uuid)
+	if err != nil {
+		return 0
+	}
+	if source == ConfigSourceDefault {
+		v = 0
+	} else {
+		v = cfg.Interval
+	}
+	intervalCacheMu.Lock()
+	intervalCache[uuid] = v
+	intervalCacheMu.Unlock()
+	return v
+}
+
+// ReadWait 根据客户端生效的上报间隔计算离线判定时间：允许连续丢失两次上报，且不低于默认值
+func ReadWait(uuid string) time.Duration {
+	interval := ReportInterval(uuid)
+	if interval <= 0 {
+		return defaultReadWait
+	}
+	wait := time.Duration(interval)*3*time.Second + 5*time.Second
+	if wait < defaultReadWait {
+		return defaultReadWait
+	}
+	return wait
+}
+
+func resetIntervalCache() {
+	intervalCacheMu.Lock()
+	intervalCache = map[string]int{}
+	intervalCacheMu.Unlock()
+}
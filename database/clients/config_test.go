@@ -0,0 +1,53 @@
+package clients
+
+import (
+	"testing"
+	"time"
+
+	"github.com/komari-monitor/komari/common"
+)
+
+func TestValidateCollectConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		wantErr  bool
+	}{
+		{"默认间隔", 1, false},
+		{"上限", maxReportInterval, false},
+		{"为零", 0, true},
+		{"超过上限", maxReportInterval + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := common.DefaultCollectConfig()
+			cfg.Interval = tt.interval
+			if err := ValidateCollectConfig(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCollectConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		want     time.Duration
+	}{
+		{"未配置", 0, defaultReadWait},
+		{"间隔较短时取默认值", 1, defaultReadWait},
+		{"按间隔计算", 10, 35 * time.Second},
+	}
+	defer resetIntervalCache()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intervalCacheMu.Lock()
+			intervalCache["test"] = tt.interval
+			intervalCacheMu.Unlock()
+			if got := ReadWait("test"); got != tt.want {
+				t.Errorf("ReadWait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
+)
+
+// GetClientIPRecord 获取客户端某个 IP 的历史记录
+func GetClientIPRecord(clientUuid, ip string) (record models.ClientIPHistory, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("client = ? AND ip = ?", clientUuid, ip).First(&record).Error
+	return
+}
+
+// RecordClientIP 记录客户端出现的 IP，已存在时刷新最后出现时间与地理信息。
+// 返回该 IP 是否首次出现。
+func RecordClientIP(record models.ClientIPHistory) (bool, error) {
+	db := dbcore.GetDBInstance()
+	now := models.FromTime(time.Now())
+	existing, err := GetClientIPRecord(record.Client, record.IP)
+	if err == gorm.ErrRecordNotFound {
+		record.Id = 0
+		record.FirstSeen = now
+		record.LastSeen = now
+		return true, db.Create(&record).Error
+	}
+	if err != nil {
+		return false, err
+	}
+	updates := map[string]interface{}{"last_seen": now}
+	// 未能解析到地理信息时保留旧值
+	if record.Region != "" || record.ASN != 0 {
+		updates["region"] = record.Region
+		updates["city"] = record.City
+		updates["asn"] = record.ASN
+		updates["organization"] = record.Organization
+	}
+	return false, db.Model(&existing).Updates(updates).Error
+}
+
+// GetClientIPHistory 获取客户端的 IP 历史，按最后出现时间倒序
+func GetClientIPHistory(clientUuid string) (history []models.ClientIPHistory, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("client = ?", clientUuid).Order("last_seen DESC").Find(&history).Error
+	return
+}
+
+// DeleteClientIPHistory 删除客户端的 IP 历史
+func DeleteClientIPHistory(clientUuid string) error {
+	db := dbcore.GetDBInstance()
+	return db.Where("client = ?", clientUuid).Delete(&models.ClientIPHistory{}).Error
+}
@@ -0,0 +1,30 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// AddRenewalRecord 写入一条续费账本记录
+func AddRenewalRecord(record models.RenewalRecord) error {
+	db := dbcore.GetDBInstance()
+	return db.Create(&record).Error
+}
+
+// GetRenewalRecords 获取续费账本，clientUuid 为空时返回全部客户端，时间为零值表示不限制
+func GetRenewalRecords(clientUuid string, from, to time.Time) (records []models.RenewalRecord, err error) {
+	db := dbcore.GetDBInstance().Model(&models.RenewalRecord{})
+	if clientUuid != "" {
+		db = db.Where("client = ?", clientUuid)
+	}
+	if !from.IsZero() {
+		db = db.Where("created_at >= ?", models.FromTime(from))
+	}
+	if !to.IsZero() {
+		db = db.Where("created_at < ?", models.FromTime(to))
+	}
+	err = db.Order("created_at DESC").Find(&records).Error
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"time"
 
 	"github.com/komari-monitor/komari/common"
@@ -133,6 +134,36 @@ func ReportVerify(report common.Report) error {
 	if report.Connections.UDP < 0 {
 		return fmt.Errorf("Connections.UDP must be non-negative: %d", report.Connections.UDP)
 	}
+	return VerifyCustomMetrics(report.Custom)
+}
+
+// 单次上报允许的自定义指标数量
+const maxCustomMetrics = 64
+
+var customMetricName = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+
+// VerifyCustomMetrics 检查自定义指标的名称、类型与取值
+func VerifyCustomMetrics(metrics map[string]common.CustomMetric) error {
+	if len(metrics) > maxCustomMetrics {
+		return fmt.Errorf("too many custom metrics: %d, at most %d", len(metrics), maxCustomMetrics)
+	}
+	for name, m := range metrics {
+		if !customMetricName.MatchString(name) {
+			return fmt.Errorf("invalid custom metric name: %q", name)
+		}
+		if m.Type != common.CustomGauge && m.Type != common.CustomCounter {
+			return fmt.Errorf("custom metric %s: type must be gauge or counter", name)
+		}
+		if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+			return fmt.Errorf("custom metric %s: value must be a finite number", name)
+		}
+		if m.Type == common.CustomCounter && m.Value < 0 {
+			return fmt.Errorf("custom metric %s: counter must be non-negative", name)
+		}
+		if len(m.Unit) > 32 {
+			return fmt.Errorf("custom metric %s: unit is too long", name)
+		}
+	}
 	return nil
 }
 
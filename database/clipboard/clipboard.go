@@ -3,6 +3,7 @@ package clipboard
 import (
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
 )
 
 // CreateClipboard 创建剪贴板记录
@@ -56,3 +57,34 @@ func ListClipboard() ([]models.Clipboard, error) {
 	}
 	return list, nil
 }
+
+// ImportClipboard 导入剪贴板记录，同一文件夹下同名的记录在 overwrite 时覆盖，否则跳过
+func ImportClipboard(items []models.Clipboard, overwrite bool) (created, updated, skipped int, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			var existing models.Clipboard
+			findErr := tx.Where("folder = ? AND name = ?", item.Folder, item.Name).First(&existing).Error
+			if findErr == nil {
+				if !overwrite {
+					skipped++
+					continue
+				}
+				item.Id = existing.Id
+				item.CreatedAt = existing.CreatedAt
+				if err := tx.Select("*").Omit("created_at").Updates(&item).Error; err != nil {
+					return err
+				}
+				updated++
+				continue
+			}
+			item.Id = 0
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	return
+}
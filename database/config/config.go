@@ -2,12 +2,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/ipfilter"
 	"gorm.io/gorm"
 )
 
@@ -95,6 +98,19 @@ func Update(cst map[string]interface{}) error {
 			return errors.New("cannot disable password login when no SSO-bound account exists")
 		}
 	}
+	// 校验 IP 访问控制列表
+	for key, val := range cst {
+		if !strings.HasSuffix(key, "_cidrs") {
+			continue
+		}
+		str, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", key)
+		}
+		if _, err := ipfilter.Parse(str); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
 	err := db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&models.Config{}).Where("id = ?", oldConfig.ID).Updates(cst).Error; err != nil {
 			return errors.Join(err, errors.New("failed to update configuration"))
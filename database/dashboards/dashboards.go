@@ -0,0 +1,120 @@
+package dashboards
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+	"gorm.io/gorm"
+)
+
+const passwordSalt = "komari-dashboard"
+
+// GetAllDashboards 获取全部面板
+func GetAllDashboards() (list []models.Dashboard, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Order("id").Find(&list).Error
+	return
+}
+
+// GetDashboardBySlug 根据 slug 获取面板
+func GetDashboardBySlug(slug string) (*models.Dashboard, error) {
+	db := dbcore.GetDBInstance()
+	var d models.Dashboard
+	if err := db.Where("slug = ?", slug).First(&d).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetDashboardById 根据 ID 获取面板
+func GetDashboardById(id uint) (*models.Dashboard, error) {
+	db := dbcore.GetDBInstance()
+	var d models.Dashboard
+	if err := db.Where("id = ?", id).First(&d).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// AddDashboard 创建面板，password 非空时设置访问密码，并生成分享 token
+func AddDashboard(d *models.Dashboard, password string) error {
+	db := dbcore.GetDBInstance()
+	d.Id = 0
+	if password != "" {
+		d.Password = HashPassword(password)
+	}
+	d.ShareToken = utils.GenerateToken()
+	return db.Create(d).Error
+}
+
+// EditDashboard 更新面板配置，password 为 nil 时保持原密码
+func EditDashboard(d *models.Dashboard, password *string) error {
+	db := dbcore.GetDBInstance()
+	fields := []string{"slug", "name", "title", "description", "theme", "custom_head", "custom_body",
+		"client_groups", "tags", "clients", "access", "hidden_fields"}
+	if password != nil {
+		d.Password = ""
+		if *password != "" {
+			d.Password = HashPassword(*password)
+		}
+		fields = append(fields, "password")
+	}
+	result := db.Model(&models.Dashboard{}).Where("id = ?", d.Id).Select(fields).Updates(d)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RotateShareToken 重新生成分享 token，旧链接与已授权的访问随之失效
+func RotateShareToken(id uint) (string, error) {
+	db := dbcore.GetDBInstance()
+	token := utils.GenerateToken()
+	result := db.Model(&models.Dashboard{}).Where("id = ?", id).Update("share_token", token)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return token, nil
+}
+
+// DeleteDashboard 删除面板
+func DeleteDashboard(id []uint) error {
+	db := dbcore.GetDBInstance()
+	result := db.Where("id IN ?", id).Delete(&models.Dashboard{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HashPassword 对面板访问密码进行加盐哈希
+func HashPassword(password string) string {
+	hash := sha256.Sum256([]byte(password + passwordSalt))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// CheckPassword 校验面板访问密码
+func CheckPassword(d *models.Dashboard, password string) bool {
+	return d.Password != "" && hmac.Equal([]byte(HashPassword(password)), []byte(d.Password))
+}
+
+// AccessKey 返回授权访问后写入 Cookie 的凭据，修改密码或重新生成 token 后失效
+func AccessKey(d *models.Dashboard) string {
+	mac := hmac.New(sha256.New, []byte(d.Password+"|"+d.ShareToken))
+	mac.Write([]byte(d.Slug))
+	return hex.EncodeToString(mac.Sum(nil))
+}
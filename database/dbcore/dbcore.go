@@ -218,7 +218,54 @@ func mergeClientInfo(db *gorm.DB) {
 	log.Println("Data migration completed, old table has been backed up as client_infos_backup")
 }
 
+// migrateClientConfig 重建旧版 client_configs 表：主键由 uuid 类型改为 varchar(36)，
+// 并去掉旧的外键与 check 约束，已有配置原样保留
+func migrateClientConfig(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&common.ClientConfig{}) {
+		return nil
+	}
+	columns, err := m.ColumnTypes(&common.ClientConfig{})
+	if err != nil {
+		return err
+	}
+	legacy := false
+	for _, col := range columns {
+		if col.Name() == "client_uuid" {
+			legacy = !strings.HasPrefix(strings.ToLower(col.DatabaseTypeName()), "varchar")
+		}
+	}
+	if !legacy {
+		return nil
+	}
+	log.Println("Rebuilding client_configs table....")
+	if m.HasTable("client_configs_legacy") {
+		if err := m.DropTable("client_configs_legacy"); err != nil {
+			return err
+		}
+	}
+	if err := m.RenameTable("client_configs", "client_configs_legacy"); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&common.ClientConfig{}); err != nil {
+		return err
+	}
+	var configs []common.ClientConfig
+	if err := db.Table("client_configs_legacy").Find(&configs).Error; err != nil {
+		return err
+	}
+	if len(configs) > 0 {
+		if err := db.CreateInBatches(&configs, 100).Error; err != nil {
+			return err
+		}
+	}
+	return m.DropTable("client_configs_legacy")
+}
+
 func MergeDatabase(db *gorm.DB) {
+	if err := migrateClientConfig(db); err != nil {
+		log.Fatalf("Failed to migrate client_configs table: %v", err)
+	}
 	if db.Migrator().HasTable("client_infos") {
 		log.Println("[>0.0.5] Legacy ClientInfo table detected, starting data migration...")
 		mergeClientInfo(db)
@@ -461,9 +508,22 @@ func GetDBInstance() *gorm.DB {
 			&models.OfflineNotification{},
 			&models.PingRecord{},
 			&models.PingTask{},
+			&models.PingAlertState{},
 			&models.OidcProvider{},
 			&models.MessageSenderProvider{},
 			&models.ThemeConfiguration{},
+			&models.WebAuthnCredential{},
+			&models.RecoveryCode{},
+			&models.ClientCertificate{},
+			&models.NotificationTemplate{},
+			&models.ClientIPHistory{},
+			&models.RenewalRecord{},
+			&models.TrafficCycle{},
+			&models.ThresholdAction{},
+			&models.ThresholdActionLog{},
+			&models.ExpireNotification{},
+			&models.ExpireNotificationState{},
+			&models.Dashboard{},
 		)
 		if err != nil {
 			log.Fatalf("Failed to create tables: %v", err)
@@ -480,6 +540,19 @@ func GetDBInstance() *gorm.DB {
 		if err != nil {
 			log.Printf("Failed to create gpu_records_long_term table, it may already exist: %v", err)
 		}
+		err = instance.AutoMigrate(
+			&models.CustomMetric{},
+			&models.CustomRecord{},
+		)
+		if err != nil {
+			log.Printf("Failed to create CustomMetric tables, it may already exist: %v", err)
+		}
+		err = instance.Table("custom_records_long_term").AutoMigrate(
+			&models.CustomRecord{},
+		)
+		if err != nil {
+			log.Printf("Failed to create custom_records_long_term table, it may already exist: %v", err)
+		}
 		err = instance.AutoMigrate(
 			&models.Session{},
 		)
@@ -493,6 +566,29 @@ func GetDBInstance() *gorm.DB {
 		if err != nil {
 			log.Printf("Failed to create Task and TaskResult table, it may already exist: %v", err)
 		}
+		err = instance.AutoMigrate(
+			&models.FileTransfer{},
+			&models.FileTransferResult{},
+			&models.FileTransferPolicy{},
+		)
+		if err != nil {
+			log.Printf("Failed to create FileTransfer tables, it may already exist: %v", err)
+		}
+		err = instance.AutoMigrate(
+			&common.ClientConfig{},
+			&common.GroupConfig{},
+		)
+		if err != nil {
+			log.Fatalf("Failed to create client config tables: %v", err)
+		}
+		err = instance.AutoMigrate(
+			&models.AgentBinary{},
+			&models.AgentRollout{},
+			&models.AgentRolloutTarget{},
+		)
+		if err != nil {
+			log.Printf("Failed to create agent update tables, it may already exist: %v", err)
+		}
 
 	})
 	return instance
@@ -0,0 +1,159 @@
+package filetransfer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
+)
+
+// CreateTransfer 创建传输任务，并为每个客户端创建待处理的结果
+func CreateTransfer(t models.FileTransfer) error {
+	db := dbcore.GetDBInstance()
+	t.CreatedAt = models.FromTime(time.Now())
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Results").Create(&t).Error; err != nil {
+			return err
+		}
+		var results []models.FileTransferResult
+		for _, client := range t.Clients {
+			results = append(results, models.FileTransferResult{
+				TransferId: t.TransferId,
+				Client:     client,
+				Status:     models.TransferPending,
+				Size:       t.Size,
+				CreatedAt:  t.CreatedAt,
+				UpdatedAt:  t.CreatedAt,
+			})
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		return tx.Create(&results).Error
+	})
+}
+
+// GetTransfer 获取传输任务及各客户端结果
+func GetTransfer(id string) (*models.FileTransfer, error) {
+	var t models.FileTransfer
+	if err := dbcore.GetDBInstance().Preload("Results").Where("transfer_id = ?", id).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTransferInfo 获取传输任务，不含各客户端结果
+func GetTransferInfo(id string) (*models.FileTransfer, error) {
+	var t models.FileTransfer
+	if err := dbcore.GetDBInstance().Where("transfer_id = ?", id).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTransfers 按创建时间倒序列出传输任务，client 不为空时只返回涉及该客户端的任务
+func ListTransfers(client string) ([]models.FileTransfer, error) {
+	var list []models.FileTransfer
+	db := dbcore.GetDBInstance().Preload("Results").Order("created_at DESC")
+	if client != "" {
+		db = db.Where("clients LIKE ?", "%"+client+"%")
+	}
+	err := db.Find(&list).Error
+	return list, err
+}
+
+// GetResult 获取单个客户端的传输结果
+func GetResult(id, client string) (*models.FileTransferResult, error) {
+	var r models.FileTransferResult
+	if err := dbcore.GetDBInstance().Where("transfer_id = ? AND client = ?", id, client).First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateProgress 记录传输进度，已结束的结果不会被改回进行中
+func UpdateProgress(id, client string, transferred, size int64) error {
+	updates := map[string]interface{}{
+		"status":      models.TransferRunning,
+		"transferred": transferred,
+		"updated_at":  models.FromTime(time.Now()),
+	}
+	if size > 0 {
+		updates["size"] = size
+	}
+	return dbcore.GetDBInstance().Model(&models.FileTransferResult{}).
+		Where("transfer_id = ? AND client = ? AND status IN ?", id, client, []string{models.TransferPending, models.TransferRunning}).
+		Updates(updates).Error
+}
+
+// Finish 记录客户端的最终结果，errMsg 为空表示成功
+func Finish(id, client string, transferred int64, sha256, errMsg string) error {
+	now := models.FromTime(time.Now())
+	status := models.TransferSuccess
+	if errMsg != "" {
+		status = models.TransferFailed
+	}
+	return dbcore.GetDBInstance().Model(&models.FileTransferResult{}).
+		Where("transfer_id = ? AND client = ?", id, client).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"transferred": transferred,
+			"sha256":      sha256,
+			"error":       errMsg,
+			"finished_at": now,
+			"updated_at":  now,
+		}).Error
+}
+
+// Restart 将未成功的结果重置为待处理，用于断点续传，已传输的字节数保留
+func Restart(id, client string) error {
+	return dbcore.GetDBInstance().Model(&models.FileTransferResult{}).
+		Where("transfer_id = ? AND client = ? AND status <> ?", id, client, models.TransferSuccess).
+		Updates(map[string]interface{}{
+			"status":      models.TransferPending,
+			"error":       "",
+			"finished_at": nil,
+			"updated_at":  models.FromTime(time.Now()),
+		}).Error
+}
+
+// DeleteTransfer 删除传输任务
+func DeleteTransfer(id string) error {
+	db := dbcore.GetDBInstance()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transfer_id = ?", id).Delete(&models.FileTransferResult{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("transfer_id = ?", id).Delete(&models.FileTransfer{}).Error
+	})
+}
+
+// ListTransferIdsBefore 返回在指定时间前创建的传输任务 ID
+func ListTransferIdsBefore(before time.Time) ([]string, error) {
+	var ids []string
+	err := dbcore.GetDBInstance().Model(&models.FileTransfer{}).
+		Where("created_at < ?", before.Format(time.RFC3339)).
+		Pluck("transfer_id", &ids).Error
+	return ids, err
+}
+
+// GetPolicy 获取客户端的传输限制，未配置时返回零值
+func GetPolicy(client string) (models.FileTransferPolicy, error) {
+	var p models.FileTransferPolicy
+	err := dbcore.GetDBInstance().Where("client = ?", client).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.FileTransferPolicy{Client: client, AllowedPaths: models.StringArray{}}, nil
+	}
+	return p, err
+}
+
+// SavePolicy 保存客户端的传输限制
+func SavePolicy(p models.FileTransferPolicy) error {
+	if p.AllowedPaths == nil {
+		p.AllowedPaths = models.StringArray{}
+	}
+	p.UpdatedAt = models.FromTime(time.Now())
+	return dbcore.GetDBInstance().Save(&p).Error
+}
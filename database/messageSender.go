@@ -3,6 +3,7 @@ package database
 import (
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
 )
 
 func GetAllMessageSenderConfigs() []models.MessageSenderProvider {
@@ -27,3 +28,50 @@ func SaveMessageSenderConfig(config *models.MessageSenderProvider) error {
 	db := dbcore.GetDBInstance()
 	return db.Save(config).Error
 }
+
+func GetAllNotificationTemplates() ([]models.NotificationTemplate, error) {
+	db := dbcore.GetDBInstance()
+	var result []models.NotificationTemplate
+	err := db.Order("event, channel").Find(&result).Error
+	return result, err
+}
+
+// GetNotificationTemplate 按 (事件, 渠道) -> (事件, 全部) -> (全部, 渠道) 的顺序查找最匹配的模板
+func GetNotificationTemplate(event, channel string) (*models.NotificationTemplate, error) {
+	db := dbcore.GetDBInstance()
+	var result models.NotificationTemplate
+	candidates := [][2]string{{event, channel}, {event, ""}, {"", channel}}
+	for _, c := range candidates {
+		if c[0] == "" && c[1] == "" {
+			continue
+		}
+		if err := db.Where("event = ? AND channel = ?", c[0], c[1]).First(&result).Error; err == nil {
+			return &result, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// SaveNotificationTemplate 同一 (事件, 渠道) 只保留一个模板
+func SaveNotificationTemplate(tmpl *models.NotificationTemplate) error {
+	db := dbcore.GetDBInstance()
+	if tmpl.Id == 0 {
+		var existing models.NotificationTemplate
+		if err := db.Where("event = ? AND channel = ?", tmpl.Event, tmpl.Channel).First(&existing).Error; err == nil {
+			tmpl.Id = existing.Id
+		}
+	}
+	return db.Save(tmpl).Error
+}
+
+func DeleteNotificationTemplates(ids []uint) error {
+	db := dbcore.GetDBInstance()
+	result := db.Where("id IN ?", ids).Delete(&models.NotificationTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
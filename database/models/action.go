@@ -0,0 +1,25 @@
+package models
+
+// ThresholdAction 定义了客户端触达流量上限或到期时自动执行的动作
+type ThresholdAction struct {
+	Id         uint        `json:"id,omitempty" gorm:"primaryKey;autoIncrement"`
+	Name       string      `json:"name" gorm:"type:varchar(255)"`
+	Enable     bool        `json:"enable" gorm:"default:true"`
+	Clients    StringArray `json:"clients" gorm:"type:longtext"`             // 为空表示全部客户端
+	Trigger    string      `json:"trigger" gorm:"type:varchar(20);not null"` // traffic, expire
+	Type       string      `json:"type" gorm:"type:varchar(20);not null"`    // exec, webhook, hide
+	Command    string      `json:"command" gorm:"type:text"`                 // exec 执行的命令
+	WebhookUrl string      `json:"webhook_url" gorm:"type:text"`             // webhook 地址，POST JSON
+	CreatedAt  LocalTime   `json:"created_at"`
+	UpdatedAt  LocalTime   `json:"updated_at"`
+}
+
+// ThresholdActionLog 记录动作在某次阈值触达中已执行，保证每次触达只执行一次
+type ThresholdActionLog struct {
+	Id        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Action    uint      `json:"action" gorm:"uniqueIndex:idx_action_client_key"`
+	Client    string    `json:"client" gorm:"type:varchar(36);uniqueIndex:idx_action_client_key"`
+	Key       string    `json:"key" gorm:"type:varchar(64);uniqueIndex:idx_action_client_key"` // 触达标识，如流量周期或到期日
+	TaskId    string    `json:"task_id" gorm:"type:varchar(36)"`
+	CreatedAt LocalTime `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+// AgentBinary 可供下发的 Agent 二进制文件，保存在 ./data/agent 下
+type AgentBinary struct {
+	Id        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Version   string    `json:"version" gorm:"type:varchar(50);uniqueIndex:idx_agent_binary"`
+	OS        string    `json:"os" gorm:"type:varchar(20);uniqueIndex:idx_agent_binary"`   // linux / windows / darwin / freebsd
+	Arch      string    `json:"arch" gorm:"type:varchar(20);uniqueIndex:idx_agent_binary"` // amd64 / arm64 / 386 / arm ...
+	Size      int64     `json:"size" gorm:"type:bigint"`
+	SHA256    string    `json:"sha256" gorm:"type:varchar(64)"`
+	SourceURL string    `json:"source_url,omitempty" gorm:"type:text"` // 从外部地址获取时的来源
+	CreatedAt LocalTime `json:"created_at"`
+}
+
+// Agent 升级计划状态
+const (
+	RolloutRunning   = "running"
+	RolloutPaused    = "paused"
+	RolloutHalted    = "halted" // 失败次数达到上限后自动暂停
+	RolloutCompleted = "completed"
+	RolloutCancelled = "cancelled"
+)
+
+// 单个客户端的升级状态
+const (
+	RolloutTargetPending = "pending"
+	RolloutTargetSent    = "sent"
+	RolloutTargetHealthy = "healthy"
+	RolloutTargetFailed  = "failed"
+	RolloutTargetSkipped = "skipped"
+)
+
+// AgentRollout 将一个分组的 Agent 分阶段升级到目标版本。
+// 第一阶段只升级 Percent% 的客户端，全部在 HealthWindow 分钟内恢复上报后再升级其余客户端。
+type AgentRollout struct {
+	Id             uint                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	Group          string               `json:"group" gorm:"type:varchar(100);index"` // 为空表示未分组的客户端
+	TargetVersion  string               `json:"target_version" gorm:"type:varchar(50)"`
+	Percent        int                  `json:"percent" gorm:"default:10"`
+	HealthWindow   int                  `json:"health_window" gorm:"default:10"` // 分钟
+	MaxFailures    int                  `json:"max_failures" gorm:"default:1"`
+	Status         string               `json:"status" gorm:"type:varchar(16);index"`
+	Stage          int                  `json:"stage"` // 0 为灰度阶段，1 为其余客户端
+	StageStartedAt LocalTime            `json:"stage_started_at"`
+	Message        string               `json:"message,omitempty" gorm:"type:text"` // 暂停或终止的原因
+	Targets        []AgentRolloutTarget `json:"targets,omitempty" gorm:"foreignKey:RolloutId;references:Id;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	CreatedAt      LocalTime            `json:"created_at"`
+	UpdatedAt      LocalTime            `json:"updated_at"`
+}
+
+// AgentRolloutTarget 升级计划中的单个客户端
+type AgentRolloutTarget struct {
+	Id          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	RolloutId   uint       `json:"rollout_id" gorm:"index"`
+	Client      string     `json:"client" gorm:"type:varchar(36);index"`
+	Stage       int        `json:"stage"`
+	FromVersion string     `json:"from_version" gorm:"type:varchar(100)"`
+	Status      string     `json:"status" gorm:"type:varchar(16)"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	SentAt      *LocalTime `json:"sent_at"`
+	FinishedAt  *LocalTime `json:"finished_at"`
+}
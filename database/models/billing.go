@@ -0,0 +1,14 @@
+package models
+
+// RenewalRecord 自动续费账本，每次续费记录一条
+type RenewalRecord struct {
+	Id                uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Client            string    `json:"client" gorm:"type:varchar(36);index"`
+	ClientName        string    `json:"client_name" gorm:"type:varchar(100)"`
+	Amount            float64   `json:"amount"`
+	Currency          string    `json:"currency" gorm:"type:varchar(20)"`
+	BillingCycle      int       `json:"billing_cycle"`
+	PreviousExpiredAt LocalTime `json:"previous_expired_at"`
+	NewExpiredAt      LocalTime `json:"new_expired_at"`
+	CreatedAt         LocalTime `json:"created_at" gorm:"index"`
+}
@@ -1,11 +1,51 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
 type Clipboard struct {
-	Id        int       `json:"id" gorm:"primaryKey;autoIncrement;unique"`
-	Text      string    `json:"text" gorm:"type:longtext"`
-	Name      string    `json:"name" gorm:"type:varchar(255)"`
-	Weight    int       `json:"weight" gorm:"type:int"`
-	Remark    string    `json:"remark" gorm:"type:text"`
-	CreatedAt LocalTime `json:"created_at"`
-	UpdatedAt LocalTime `json:"updated_at"`
+	Id        int             `json:"id" gorm:"primaryKey;autoIncrement;unique"`
+	Text      string          `json:"text" gorm:"type:longtext"` // 内容，作为命令模板时使用 text/template 语法
+	Name      string          `json:"name" gorm:"type:varchar(255)"`
+	Weight    int             `json:"weight" gorm:"type:int"`
+	Remark    string          `json:"remark" gorm:"type:text"`
+	Folder    string          `json:"folder" gorm:"type:varchar(255);index"`
+	Tags      StringArray     `json:"tags" gorm:"type:longtext"`
+	Params    ClipboardParams `json:"params" gorm:"type:longtext"`    // 模板参数声明
+	Dangerous bool            `json:"dangerous" gorm:"default:false"` // 执行前必须确认
+	CreatedAt LocalTime       `json:"created_at"`
+	UpdatedAt LocalTime       `json:"updated_at"`
+}
+
+// ClipboardParam 命令模板的参数声明
+type ClipboardParam struct {
+	Name     string   `json:"name"` // 模板中以 {{.name}} 引用
+	Label    string   `json:"label"`
+	Default  string   `json:"default"`
+	Required bool     `json:"required"`
+	Pattern  string   `json:"pattern,omitempty"` // 值需完整匹配的正则
+	Options  []string `json:"options,omitempty"` // 可选值，为空时不限制
+}
+
+// ClipboardParams 存储为 JSON 的参数声明列表
+type ClipboardParams []ClipboardParam
+
+func (p *ClipboardParams) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return fmt.Errorf("failed to scan ClipboardParams: unsupported type %T", value)
+}
+
+func (p ClipboardParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
 }
@@ -14,6 +14,11 @@ type Config struct {
 	// GeoIP 配置
 	GeoIpEnabled  bool   `json:"geo_ip_enabled" gorm:"default:true"`
 	GeoIpProvider string `json:"geo_ip_provider" gorm:"type:varchar(20);default:'ip-api'"` // empty, mmdb, ip-api, geojs
+	// mmdb 数据库的本地路径或下载地址，Country 留空使用默认地址，City / ASN 留空表示不启用
+	GeoIpCountryDb        string `json:"geo_ip_country_db" gorm:"type:text"`
+	GeoIpCityDb           string `json:"geo_ip_city_db" gorm:"type:text"`
+	GeoIpAsnDb            string `json:"geo_ip_asn_db" gorm:"type:text"`
+	SendGeoDetailsToGuest bool   `json:"send_geo_details_to_guest" gorm:"default:false"` // 是否向访客展示城市、坐标与 ASN
 	// Nezha 兼容（Agent gRPC）
 	NezhaCompatEnabled bool   `json:"nezha_compat_enabled" gorm:"default:false"`
 	NezhaCompatListen  string `json:"nezha_compat_listen" gorm:"type:varchar(100);default:''"` // 例如 0.0.0.0:5555
@@ -21,6 +26,16 @@ type Config struct {
 	OAuthEnabled         bool   `json:"o_auth_enabled" gorm:"default:false"`
 	OAuthProvider        string `json:"o_auth_provider" gorm:"type:varchar(50);default:'github'"`
 	DisablePasswordLogin bool   `json:"disable_password_login" gorm:"default:false"`
+	// 登录保护
+	LoginFailureLimit   int `json:"login_failure_limit" gorm:"default:5"`    // 连续失败多少次后锁定
+	LoginLockoutMinutes int `json:"login_lockout_minutes" gorm:"default:15"` // 首次锁定时长，之后每次失败翻倍
+	// IP 访问控制，逗号或换行分隔的 IP / CIDR，拒绝列表优先，允许列表为空表示不限制
+	AdminAllowCidrs  string `json:"admin_allow_cidrs" gorm:"type:text"`
+	AdminDenyCidrs   string `json:"admin_deny_cidrs" gorm:"type:text"`
+	AgentAllowCidrs  string `json:"agent_allow_cidrs" gorm:"type:text"`
+	AgentDenyCidrs   string `json:"agent_deny_cidrs" gorm:"type:text"`
+	PublicAllowCidrs string `json:"public_allow_cidrs" gorm:"type:text"`
+	PublicDenyCidrs  string `json:"public_deny_cidrs" gorm:"type:text"`
 	// 自定义美化
 	CustomHead string `json:"custom_head" gorm:"type:longtext"`
 	CustomBody string `json:"custom_body" gorm:"type:longtext"`
@@ -32,6 +47,27 @@ type Config struct {
 	ExpireNotificationLeadDays int     `json:"expire_notification_lead_days" gorm:"default:7"`   // 过期前多少天通知，默认7天
 	LoginNotification          bool    `json:"login_notification" gorm:"default:false"`          // 登录通知
 	TrafficLimitPercentage     float64 `json:"traffic_limit_percentage" gorm:"default:80.00"`    // 流量限制百分比，默认80.00%
+	// 到期提醒调度
+	ExpireNotificationTime  string `json:"expire_notification_time" gorm:"type:varchar(5);default:'09:00'"` // 每日检查时间，应用时区 HH:MM
+	ExpireNotificationSteps string `json:"expire_notification_steps" gorm:"type:varchar(100);default:''"`   // 逗号分隔的提前天数，如 30,7,3,1，留空使用 expire_notification_lead_days
+	ExpireEscalationDays    int    `json:"expire_escalation_days" gorm:"default:0"`                         // 剩余天数不超过该值时同时通过升级渠道发送，0 表示不升级
+	ExpireEscalationChannel string `json:"expire_escalation_channel" gorm:"type:varchar(64);default:''"`    // 升级渠道，即消息提供者名称
+	// 客户端 IP 变化通知
+	IPChangeNotification        bool `json:"ip_change_notification" gorm:"default:false"`
+	IPChangeNotifyOnlyGeoChange bool `json:"ip_change_notify_only_geo_change" gorm:"default:false"` // 仅在国家或 ASN 变化时通知
+	// 账单统计
+	BillingCurrency    string `json:"billing_currency" gorm:"type:varchar(20);default:'USD'"`        // 报表币种
+	ExchangeRateSource string `json:"exchange_rate_source" gorm:"type:varchar(20);default:'static'"` // static, http
+	ExchangeRates      string `json:"exchange_rates" gorm:"type:text"`                               // JSON，1 USD 可兑换的各币种数量，覆盖内置汇率
+	ExchangeRateUrl    string `json:"exchange_rate_url" gorm:"type:text"`                            // http 汇率源地址
+	// 公开数据字段可见性，JSON，字段 -> guest / viewer / admin，未设置的字段使用默认策略
+	ExposurePolicy string `json:"exposure_policy" gorm:"type:text"`
+	// 通知与邮件使用的语言，取值见 utils/i18n/locales
+	NotificationLocale string `json:"notification_locale" gorm:"type:varchar(16);default:'en'"`
+	// 主题市场与签名
+	ThemeIndexUrl         string `json:"theme_index_url" gorm:"type:text"`             // 主题索引（JSON 目录）地址，为空时不启用主题市场
+	ThemeTrustedKeys      string `json:"theme_trusted_keys" gorm:"type:text"`          // 受信任的 Ed25519 公钥，base64，每行一个
+	ThemeRequireSignature bool   `json:"theme_require_signature" gorm:"default:false"` // 仅允许安装签名有效的主题
 	// Record
 	RecordEnabled          bool `json:"record_enabled" gorm:"default:true"`          // 是否启用记录功能
 	RecordPreserveTime     int  `json:"record_preserve_time" gorm:"default:720"`     // 记录保留时间，单位小时，默认30天
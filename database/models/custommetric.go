@@ -0,0 +1,19 @@
+package models
+
+// CustomMetric 客户端上报过的自定义指标，记录类型、单位与是否公开
+type CustomMetric struct {
+	Client    string    `json:"client" gorm:"type:varchar(36);primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(64);primaryKey"`
+	Type      string    `json:"type" gorm:"type:varchar(16)"` // gauge | counter
+	Unit      string    `json:"unit" gorm:"type:varchar(32)"`
+	Public    bool      `json:"public"` // 由管理员设置，默认不公开，上报时不会覆盖
+	UpdatedAt LocalTime `json:"updated_at"`
+}
+
+// CustomRecord 自定义指标的历史记录，与 Record 一样按 15 分钟压缩到 custom_records_long_term
+type CustomRecord struct {
+	Client string    `json:"client" gorm:"type:varchar(36);index"`
+	Name   string    `json:"name" gorm:"type:varchar(64);index"`
+	Time   LocalTime `json:"time" gorm:"index"`
+	Value  float64   `json:"value"`
+}
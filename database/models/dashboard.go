@@ -0,0 +1,59 @@
+package models
+
+import "strings"
+
+const (
+	DashboardAccessPublic   = "public"   // 任何人可访问
+	DashboardAccessPassword = "password" // 需要输入访问密码
+	DashboardAccessToken    = "token"    // 需要持有分享链接中的 token
+)
+
+// Dashboard 独立的公开面板（状态页），通过 /s/<slug> 访问
+type Dashboard struct {
+	Id           uint        `json:"id,omitempty" gorm:"primaryKey;autoIncrement"`
+	Slug         string      `json:"slug" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Name         string      `json:"name" gorm:"type:varchar(255)"`
+	Title        string      `json:"title" gorm:"type:varchar(255)"` // 为空时使用站点名称
+	Description  string      `json:"description" gorm:"type:text"`
+	Theme        string      `json:"theme" gorm:"type:varchar(100)"` // 为空时使用全局主题
+	CustomHead   string      `json:"custom_head" gorm:"type:longtext"`
+	CustomBody   string      `json:"custom_body" gorm:"type:longtext"`
+	Groups       StringArray `json:"groups" gorm:"column:client_groups;type:longtext"` // 按分组选择
+	Tags         StringArray `json:"tags" gorm:"type:longtext"`                        // 按标签选择
+	Clients      StringArray `json:"clients" gorm:"type:longtext"`                     // 按 UUID 选择
+	Access       string      `json:"access" gorm:"type:varchar(20);default:'public'"`
+	Password     string      `json:"-" gorm:"type:varchar(255)"` // 加盐哈希
+	ShareToken   string      `json:"share_token" gorm:"type:varchar(64)"`
+	HiddenFields StringArray `json:"hidden_fields" gorm:"type:longtext"` // 对该面板隐藏的字段，取值见 exposure.Fields
+	CreatedAt    LocalTime   `json:"created_at"`
+	UpdatedAt    LocalTime   `json:"updated_at"`
+}
+
+// Matches 判断客户端是否属于面板，分组、标签、UUID 任一命中即可；均未设置时包含全部客户端
+func (d *Dashboard) Matches(c Client) bool {
+	if len(d.Groups) == 0 && len(d.Tags) == 0 && len(d.Clients) == 0 {
+		return true
+	}
+	for _, uuid := range d.Clients {
+		if uuid == c.UUID {
+			return true
+		}
+	}
+	for _, group := range d.Groups {
+		if group == c.Group {
+			return true
+		}
+	}
+	for _, tag := range strings.Split(c.Tags, ";") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		for _, t := range d.Tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package models
+
+// 文件传输方向
+const (
+	TransferUpload   = "upload"   // 从面板上传到客户端
+	TransferDownload = "download" // 从客户端下载到面板
+)
+
+// 单个客户端的传输状态
+const (
+	TransferPending = "pending"
+	TransferRunning = "running"
+	TransferSuccess = "success"
+	TransferFailed  = "failed"
+)
+
+// FileTransfer 文件传输任务，与 Task 一样按客户端记录结果
+type FileTransfer struct {
+	TransferId string               `json:"transfer_id" gorm:"type:varchar(36);primaryKey;unique"`
+	Direction  string               `json:"direction" gorm:"type:varchar(16)"`
+	Clients    StringArray          `json:"clients" gorm:"type:longtext"`
+	Path       string               `json:"path" gorm:"type:text"`                   // 客户端上的文件路径
+	FileName   string               `json:"file_name" gorm:"type:varchar(255)"`      // 上传时的原始文件名
+	Size       int64                `json:"size" gorm:"type:bigint"`                 // 上传文件大小，下载时为 0
+	SHA256     string               `json:"sha256" gorm:"type:varchar(64)"`          // 上传文件的校验值
+	Mode       string               `json:"mode,omitempty" gorm:"type:varchar(8)"`   // 八进制权限，例如 0644
+	Owner      string               `json:"owner,omitempty" gorm:"type:varchar(64)"` // user 或 user:group
+	Results    []FileTransferResult `json:"results,omitempty" gorm:"foreignKey:TransferId;references:TransferId;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	CreatedAt  LocalTime            `json:"created_at" gorm:"type:timestamp"`
+}
+
+// FileTransferResult 单个客户端的传输进度与结果
+type FileTransferResult struct {
+	TransferId  string     `json:"transfer_id" gorm:"type:varchar(36);index"`
+	Client      string     `json:"client" gorm:"type:varchar(36)"`
+	Status      string     `json:"status" gorm:"type:varchar(16);default:'pending'"`
+	Transferred int64      `json:"transferred" gorm:"type:bigint"` // 已传输的字节数，断点续传时从此处继续
+	Size        int64      `json:"size" gorm:"type:bigint"`        // 文件总大小，下载时由客户端上报
+	SHA256      string     `json:"sha256" gorm:"type:varchar(64)"` // 客户端上报的校验值
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	FinishedAt  *LocalTime `json:"finished_at" gorm:"type:timestamp"`
+	UpdatedAt   LocalTime  `json:"updated_at" gorm:"type:timestamp"`
+	CreatedAt   LocalTime  `json:"created_at" gorm:"type:timestamp"`
+}
+
+// FileTransferPolicy 客户端的文件传输限制，不存在时使用默认值
+type FileTransferPolicy struct {
+	Client       string      `json:"client" gorm:"type:varchar(36);primaryKey"`
+	Disabled     bool        `json:"disabled" gorm:"default:false"`
+	MaxSize      int64       `json:"max_size" gorm:"type:bigint"`        // 单个文件大小上限（字节），0 表示使用默认值
+	AllowedPaths StringArray `json:"allowed_paths" gorm:"type:longtext"` // 允许读写的目录或文件，为空时不限制
+	UpdatedAt    LocalTime   `json:"updated_at"`
+}
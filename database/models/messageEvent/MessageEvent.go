@@ -1,11 +1,14 @@
 package messageevent
 
 const (
-	Offline = "Offline"
-	Online  = "Online"
-	Expire  = "Expire"
-	Renew   = "Renew"
-	Login   = "Login"
-	Alert   = "Alert"
-	Traffic = "Traffic"
+	Offline  = "Offline"
+	Online   = "Online"
+	Expire   = "Expire"
+	Renew    = "Renew"
+	Login    = "Login"
+	Alert    = "Alert"
+	Traffic  = "Traffic"
+	IPChange = "IPChange"
+	// Agent 升级计划完成或自动终止
+	AgentUpdate = "AgentUpdate"
 )
@@ -14,3 +14,13 @@ type EventMessage struct {
 	Message string    `json:"message"`
 	Emoji   string    `json:"emoji"`
 }
+
+// NotificationTemplate 按事件与通知渠道区分的消息模板，Event/Channel 为空表示匹配全部
+type NotificationTemplate struct {
+	Id        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Event     string    `json:"event" gorm:"type:varchar(50);uniqueIndex:idx_template_event_channel"`
+	Channel   string    `json:"channel" gorm:"type:varchar(50);uniqueIndex:idx_template_event_channel"`
+	Format    string    `json:"format" gorm:"type:varchar(20);default:'text'"` // text, markdown, html
+	Template  string    `json:"template" gorm:"type:longtext"`
+	UpdatedAt LocalTime `json:"updated_at"`
+}
@@ -21,6 +21,11 @@ type Client struct {
 	IPv4             string    `json:"ipv4,omitempty" gorm:"type:varchar(100)"`
 	IPv6             string    `json:"ipv6,omitempty" gorm:"type:varchar(100)"`
 	Region           string    `json:"region" gorm:"type:varchar(100)"`
+	City             string    `json:"city" gorm:"type:varchar(100)"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	ASN              uint      `json:"asn"`
+	Organization     string    `json:"organization" gorm:"type:varchar(255)"` // ASN 所属组织
 	Remark           string    `json:"remark,omitempty" gorm:"type:longtext"`
 	PublicRemark     string    `json:"public_remark,omitempty" gorm:"type:longtext"`
 	MemTotal         int64     `json:"mem_total" gorm:"type:bigint"`
@@ -38,10 +43,20 @@ type Client struct {
 	Hidden           bool      `json:"hidden" gorm:"default:false"`
 	TrafficLimit     int64     `json:"traffic_limit" gorm:"type:bigint"`
 	TrafficLimitType string    `json:"traffic_limit_type" gorm:"type:varchar(10);default:'max'"` // 流量阈值类型：sum max min up down
+	TrafficResetDay  int       `json:"traffic_reset_day" gorm:"default:1"`                       // 每月流量重置日，超出当月天数时取月末
 	CreatedAt        LocalTime `json:"created_at"`
 	UpdatedAt        LocalTime `json:"updated_at"`
 }
 
+// StripGeoDetails 清除城市、坐标与 ASN 等精确地理信息，仅保留 Region
+func (c *Client) StripGeoDetails() {
+	c.City = ""
+	c.Latitude = 0
+	c.Longitude = 0
+	c.ASN = 0
+	c.Organization = ""
+}
+
 // User represents an authenticated user
 type User struct {
 	UUID      string    `json:"uuid,omitempty" gorm:"type:varchar(36);primaryKey"`
@@ -120,3 +135,27 @@ func (sa *StringArray) Scan(value interface{}) error {
 func (sa StringArray) Value() (driver.Value, error) {
 	return json.Marshal(sa)
 }
+
+// ClientCertificate 内置 CA 为 Agent 签发的客户端证书
+type ClientCertificate struct {
+	Serial    string    `json:"serial" gorm:"type:varchar(64);primaryKey"` // 小写十六进制
+	Client    string    `json:"client" gorm:"type:varchar(36);index"`
+	NotAfter  LocalTime `json:"not_after"`
+	Revoked   bool      `json:"revoked" gorm:"default:false;index"`
+	RevokedAt LocalTime `json:"revoked_at"`
+	CreatedAt LocalTime `json:"created_at"`
+}
+
+// ClientIPHistory 客户端使用过的 IP 地址及其地理信息
+type ClientIPHistory struct {
+	Id           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Client       string    `json:"client" gorm:"type:varchar(36);uniqueIndex:idx_ip_history_client_ip"`
+	Family       string    `json:"family" gorm:"type:varchar(4)"` // ipv4 / ipv6
+	IP           string    `json:"ip" gorm:"type:varchar(100);uniqueIndex:idx_ip_history_client_ip"`
+	Region       string    `json:"region" gorm:"type:varchar(100)"`
+	City         string    `json:"city" gorm:"type:varchar(100)"`
+	ASN          uint      `json:"asn"`
+	Organization string    `json:"organization" gorm:"type:varchar(255)"`
+	FirstSeen    LocalTime `json:"first_seen"`
+	LastSeen     LocalTime `json:"last_seen"`
+}
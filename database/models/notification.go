@@ -10,14 +10,30 @@ type OfflineNotification struct {
 	LastNotified LocalTime `json:"last_notified"`                                     // 上次通知时间
 }
 
+// ExpireNotification 客户端级别的到期提醒设置，没有记录时使用全局设置
+type ExpireNotification struct {
+	Client   string `json:"client" gorm:"type:varchar(36);not null;unique"`
+	Disable  bool   `json:"disable" gorm:"default:false"`                  // 不提醒该客户端
+	LeadDays string `json:"lead_days" gorm:"type:varchar(100);default:''"` // 覆盖全局的提前天数，留空使用全局设置
+}
+
+// ExpireNotificationState 记录某次到期的某个提醒步骤已发送，避免重启后重复或遗漏
+type ExpireNotificationState struct {
+	Id        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Client    string    `json:"client" gorm:"type:varchar(36);uniqueIndex:idx_expire_state"`
+	ExpiredAt LocalTime `json:"expired_at" gorm:"uniqueIndex:idx_expire_state"`
+	Step      int       `json:"step" gorm:"uniqueIndex:idx_expire_state"` // 提前天数
+	CreatedAt LocalTime `json:"created_at"`
+}
+
 // LoadNotification 定义了基于资源占用达标时间比的负载通知规则
 type LoadNotification struct {
 	Id           uint        `json:"id,omitempty" gorm:"primaryKey;autoIncrement"`
 	Name         string      `json:"name" gorm:"type:varchar(255)"`
 	Clients      StringArray `json:"clients" gorm:"type:longtext"`
-	Metric       string      `json:"metric" gorm:"type:varchar(50);not null;default:'cpu'"`     // 监控指标，如 cpu, ram, load
-	Threshold    float32     `json:"threshold" gorm:"type:decimal(5,2);not null;default:80.00"` // 阈值百分比
-	Ratio        float32     `json:"ratio" gorm:"type:decimal(5,2);not null;default:0.80"`      // 达标时间比
-	Interval     int         `json:"interval" gorm:"type:int;not null;default:15"`              // 监测间隔（分钟）
-	LastNotified LocalTime   `json:"last_notified"`                                             // 上次通知时间
+	Metric       string      `json:"metric" gorm:"type:varchar(100);not null;default:'cpu'"`     // 监控指标，如 cpu, ram, load，自定义指标为 custom:<name>
+	Threshold    float32     `json:"threshold" gorm:"type:decimal(15,2);not null;default:80.00"` // 阈值，自定义指标为原始值
+	Ratio        float32     `json:"ratio" gorm:"type:decimal(5,2);not null;default:0.80"`       // 达标时间比
+	Interval     int         `json:"interval" gorm:"type:int;not null;default:15"`               // 监测间隔（分钟）
+	LastNotified LocalTime   `json:"last_notified"`                                              // 上次通知时间
 }
@@ -6,7 +6,19 @@ type PingRecord struct {
 	TaskId     uint      `json:"task_id" gorm:"not null;index"`
 	Task       PingTask  `json:"task" gorm:"foreignKey:TaskId;references:Id;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;"`
 	Time       LocalTime `json:"time" gorm:"index;not null"`
-	Value      int       `json:"value" gorm:"type:int;not null"` // Ping 值，单位毫秒
+	Value      int       `json:"value" gorm:"type:int;not null"` // Ping 值（多包时为平均值），单位毫秒，负数表示全部丢失
+	Min        int       `json:"min" gorm:"type:int;default:0"`
+	Max        int       `json:"max" gorm:"type:int;default:0"`
+	Jitter     int       `json:"jitter" gorm:"type:int;default:0"`        // 相邻包延迟差的平均值，单位毫秒
+	Loss       float32   `json:"loss" gorm:"type:decimal(5,2);default:0"` // 本次探测的丢包率 %
+}
+
+// LossRatio 返回本次探测的丢包比例（0~1），兼容只有 Value 的旧记录
+func (r PingRecord) LossRatio() float64 {
+	if r.Value < 0 {
+		return 1
+	}
+	return float64(r.Loss) / 100
 }
 
 type PingTask struct {
@@ -16,4 +28,22 @@ type PingTask struct {
 	Type     string      `json:"type" gorm:"type:varchar(12);not null;default:'icmp'"` // icmp tcp http
 	Target   string      `json:"target" gorm:"type:varchar(255);not null"`
 	Interval int         `json:"interval" gorm:"type:int;not null;default:60"` // 间隔时间
+	// 探测参数
+	Count          int    `json:"count" gorm:"type:int;default:1"`                // 每次探测发送的包数
+	Timeout        int    `json:"timeout" gorm:"type:int;default:0"`              // 超时时间（毫秒），0 使用 Agent 默认值
+	IPFamily       string `json:"ip_family" gorm:"type:varchar(4);default:''"`    // 留空自动，ipv4 或 ipv6
+	HttpMethod     string `json:"http_method" gorm:"type:varchar(10);default:''"` // http 类型使用，留空为 GET
+	ExpectedStatus int    `json:"expected_status" gorm:"type:int;default:0"`      // http 类型使用，0 表示任意 2xx/3xx
+	// 告警规则，阈值为 0 表示不启用
+	AlertLoss   float64 `json:"alert_loss" gorm:"type:decimal(5,2);default:0"` // 窗口内丢包率超过该值（%）时告警
+	AlertP95    int     `json:"alert_p95" gorm:"type:int;default:0"`           // 窗口内 P95 延迟超过该值（毫秒）时告警
+	AlertWindow int     `json:"alert_window" gorm:"type:int;default:5"`        // 统计窗口（分钟）
+}
+
+// PingAlertState 处于告警中的 Ping 告警规则，恢复后删除。保存在数据库中，重启后不会重复告警或漏发恢复通知
+type PingAlertState struct {
+	Task      uint      `json:"task" gorm:"primaryKey;autoIncrement:false"`
+	Client    string    `json:"client" gorm:"type:varchar(36);primaryKey"`
+	Metric    string    `json:"metric" gorm:"type:varchar(8);primaryKey"` // loss 或 p95
+	CreatedAt LocalTime `json:"created_at"`
 }
@@ -4,6 +4,7 @@ type Task struct {
 	TaskId  string       `json:"task_id" gorm:"type:varchar(36);primaryKey;unique"`
 	Clients StringArray  `json:"clients" gorm:"type:longtext"`
 	Command string       `json:"command" gorm:"type:text"`
+	Snippet int          `json:"snippet_id,omitempty" gorm:"index"` // 由剪贴板命令模板执行时对应的模板 ID
 	Results []TaskResult `gorm:"foreignKey:TaskId;references:TaskId;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
 }
 
@@ -11,6 +12,7 @@ type TaskResult struct {
 	TaskId     string     `json:"task_id" gorm:"type:varchar(36);index"`
 	Client     string     `json:"client" gorm:"type:varchar(36)"`
 	ClientInfo Client     `json:"client_info" gorm:"foreignKey:Client;references:UUID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	Command    string     `json:"command,omitempty" gorm:"type:text"` // 针对该客户端渲染后的命令，为空时与任务命令一致
 	Result     string     `json:"result" gorm:"type:longtext"`
 	ExitCode   *int       `json:"exit_code" gorm:"type:int"`
 	FinishedAt *LocalTime `json:"finished_at" gorm:"type:timestamp"`
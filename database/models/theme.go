@@ -2,14 +2,15 @@ package models
 
 // Theme represents a komari theme information
 type Theme struct {
-	Name          string        `json:"name"`          // 主题名称
-	Short         string        `json:"short"`         // 短名称，用作文件夹名
-	Description   string        `json:"description"`   // 主题描述
-	Version       string        `json:"version"`       // 版本号
-	Author        string        `json:"author"`        // 作者
-	URL           string        `json:"url"`           // 主题URL
-	Preview       string        `json:"preview"`       // 预览图片相对路径
-	Configuration Configuration `json:"configuration"` // 声明配置项
+	Name          string        `json:"name"`                       // 主题名称
+	Short         string        `json:"short"`                      // 短名称，用作文件夹名
+	Description   string        `json:"description"`                // 主题描述
+	Version       string        `json:"version"`                    // 版本号
+	Author        string        `json:"author"`                     // 作者
+	URL           string        `json:"url"`                        // 主题URL
+	Preview       string        `json:"preview"`                    // 预览图片相对路径
+	MinVersion    string        `json:"minKomariVersion,omitempty"` // 要求的最低 Komari 版本
+	Configuration Configuration `json:"configuration"`              // 声明配置项
 }
 
 type Configuration struct {
@@ -20,13 +21,15 @@ type Configuration struct {
 }
 
 type ManagedThemeConfigurationItem struct {
-	Key      string `json:"key"`
-	Name     string `json:"name"`
-	Required bool   `json:"required"`
-	Type     string `json:"type"` // string number select switch title
-	Options  string `json:"options"`
-	Default  any    `json:"default"`
-	Help     string `json:"help"`
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Required bool     `json:"required"`
+	Type     string   `json:"type"` // string number select switch title color image multiselect clients
+	Options  string   `json:"options"`
+	Default  any      `json:"default"`
+	Help     string   `json:"help"`
+	Min      *float64 `json:"min,omitempty"` // number 的最小值
+	Max      *float64 `json:"max,omitempty"` // number 的最大值
 }
 
 type ThemeConfiguration struct {
@@ -0,0 +1,45 @@
+package models
+
+import "strings"
+
+// TrafficCycle 客户端在一个流量计费周期内的用量，由服务端根据上报的累计计数积分得到
+type TrafficCycle struct {
+	Id         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Client     string    `json:"client" gorm:"type:varchar(36);uniqueIndex:idx_traffic_client_cycle"`
+	CycleStart LocalTime `json:"cycle_start" gorm:"uniqueIndex:idx_traffic_client_cycle"`
+	CycleEnd   LocalTime `json:"cycle_end"`
+	Up         int64     `json:"up" gorm:"type:bigint"`
+	Down       int64     `json:"down" gorm:"type:bigint"`
+	Sum        int64     `json:"sum" gorm:"type:bigint"`
+	Max        int64     `json:"max" gorm:"type:bigint"`
+	Min        int64     `json:"min" gorm:"type:bigint"`
+	LastUp     int64     `json:"-" gorm:"type:bigint"` // 最近一次上报的累计计数，重启后据此继续计算增量
+	LastDown   int64     `json:"-" gorm:"type:bigint"`
+	UpdatedAt  LocalTime `json:"updated_at"`
+}
+
+// Refresh 根据 Up / Down 重新计算 Sum、Max、Min
+func (t *TrafficCycle) Refresh() {
+	t.Sum = t.Up + t.Down
+	t.Max, t.Min = t.Up, t.Down
+	if t.Down > t.Up {
+		t.Max, t.Min = t.Down, t.Up
+	}
+}
+
+// Used 按 TrafficLimitType（sum max min up down）返回已用流量，默认 max
+func (t TrafficCycle) Used(limitType string) int64 {
+	t.Refresh()
+	switch strings.ToLower(limitType) {
+	case "up":
+		return t.Up
+	case "down":
+		return t.Down
+	case "sum":
+		return t.Sum
+	case "min":
+		return t.Min
+	default:
+		return t.Max
+	}
+}
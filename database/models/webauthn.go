@@ -0,0 +1,21 @@
+package models
+
+// WebAuthnCredential 用户注册的 passkey / 安全密钥
+type WebAuthnCredential struct {
+	Id           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserUUID     string    `json:"user_uuid" gorm:"type:varchar(36);index;not null"`
+	CredentialID string    `json:"credential_id" gorm:"type:varchar(255);uniqueIndex;not null"` // base64url
+	PublicKey    []byte    `json:"-" gorm:"not null"`                                           // COSE 编码
+	SignCount    uint32    `json:"sign_count"`
+	Name         string    `json:"name" gorm:"type:varchar(100)"`
+	LastUsedAt   LocalTime `json:"last_used_at"`
+	CreatedAt    LocalTime `json:"created_at"`
+}
+
+// RecoveryCode 一次性恢复码，仅保存哈希
+type RecoveryCode struct {
+	Id        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserUUID  string    `json:"user_uuid" gorm:"type:varchar(36);index;not null"`
+	Hash      string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt LocalTime `json:"created_at"`
+}
@@ -0,0 +1,277 @@
+package records
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+var (
+	publicMu     sync.RWMutex
+	publicLoaded bool
+	publicSet    = make(map[string]bool) // 键为 client + "/" + name
+)
+
+// IsCustomMetricPublic 判断自定义指标是否由管理员设为公开，结果缓存在内存中
+func IsCustomMetricPublic(uuid, name string) bool {
+	publicMu.RLock()
+	if publicLoaded {
+		defer publicMu.RUnlock()
+		return publicSet[uuid+"/"+name]
+	}
+	publicMu.RUnlock()
+
+	publicMu.Lock()
+	defer publicMu.Unlock()
+	if !publicLoaded {
+		var list []models.CustomMetric
+		if err := dbcore.GetDBInstance().Where("public = ?", true).Find(&list).Error; err != nil {
+			return false
+		}
+		for _, m := range list {
+			publicSet[m.Client+"/"+m.Name] = true
+		}
+		publicLoaded = true
+	}
+	return publicSet[uuid+"/"+name]
+}
+
+// SetCustomMetricPublic 设置自定义指标是否对访客公开
+func SetCustomMetricPublic(uuid, name string, public bool) error {
+	db := dbcore.GetDBInstance()
+	result := db.Model(&models.CustomMetric{}).Where("client = ? AND name = ?", uuid, name).Update("public", public)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	publicMu.Lock()
+	defer publicMu.Unlock()
+	if public {
+		publicSet[uuid+"/"+name] = true
+	} else {
+		delete(publicSet, uuid+"/"+name)
+	}
+	return nil
+}
+
+// SaveCustomRecords 保存一分钟的自定义指标聚合结果，并更新指标定义。
+// 是否公开由管理员设置，更新定义时不会覆盖。
+func SaveCustomRecords(recs []models.CustomRecord, defs []models.CustomMetric) error {
+	db := dbcore.GetDBInstance()
+	// client、name 与 time 共同构成唯一键
+	unique := make(map[string]models.CustomRecord, len(recs))
+	for _, rec := range recs {
+		unique[rec.Client+"_"+rec.Name+"_"+strconv.FormatInt(rec.Time.ToTime().Unix(), 10)] = rec
+	}
+	deduped := make([]models.CustomRecord, 0, len(unique))
+	for _, rec := range unique {
+		deduped = append(deduped, rec)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(defs) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "client"}, {Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"type", "unit", "updated_at"}),
+			}).Create(&defs).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&deduped).Error
+	})
+}
+
+// ListCustomMetrics 列出客户端上报过的自定义指标，uuid 为空时返回全部
+func ListCustomMetrics(uuid string) ([]models.CustomMetric, error) {
+	db := dbcore.GetDBInstance()
+	var list []models.CustomMetric
+	q := db.Order("client ASC, name ASC")
+	if uuid != "" {
+		q = q.Where("client = ?", uuid)
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+// DeleteCustomMetric 删除自定义指标的定义及其全部历史记录
+func DeleteCustomMetric(uuid, name string) error {
+	err := deleteCustomMetrics("client = ? AND name = ?", uuid, name)
+	if err != nil {
+		return err
+	}
+	publicMu.Lock()
+	delete(publicSet, uuid+"/"+name)
+	publicMu.Unlock()
+	return nil
+}
+
+// DeleteClientCustomMetrics 删除客户端的全部自定义指标定义及历史记录
+func DeleteClientCustomMetrics(uuid string) error {
+	err := deleteCustomMetrics("client = ?", uuid)
+	if err != nil {
+		return err
+	}
+	publicMu.Lock()
+	for key := range publicSet {
+		if strings.HasPrefix(key, uuid+"/") {
+			delete(publicSet, key)
+		}
+	}
+	publicMu.Unlock()
+	return nil
+}
+
+func deleteCustomMetrics(query string, args ...interface{}) error {
+	db := dbcore.GetDBInstance()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("custom_records_long_term").Where(query, args...).Delete(&models.CustomRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(query, args...).Delete(&models.CustomRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(query, args...).Delete(&models.CustomMetric{}).Error
+	})
+}
+
+// GetCustomRecords 获取时间范围内的自定义指标记录，uuid 为空时查询全部客户端，names 为空时不按指标过滤。
+// 与 GetRecordsByClientAndTime 一致，存在长期记录时近期记录按 15 分钟只保留最新一条。
+func GetCustomRecords(uuid string, names []string, start, end time.Time) ([]models.CustomRecord, error) {
+	db := dbcore.GetDBInstance()
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = q.Where("time >= ? AND time <= ?", start, end)
+		if uuid != "" {
+			q = q.Where("client = ?", uuid)
+		}
+		if len(names) > 0 {
+			q = q.Where("name IN ?", names)
+		}
+		return q.Order("time ASC")
+	}
+
+	fourHoursAgo := time.Now().Add(-4*time.Hour - time.Minute)
+	var recent []models.CustomRecord
+	if end.After(fourHoursAgo) {
+		if err := filter(db.Table("custom_records")).Find(&recent).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var longTerm []models.CustomRecord
+	if err := filter(db.Table("custom_records_long_term")).Find(&longTerm).Error; err != nil {
+		return recent, nil
+	}
+	if len(longTerm) == 0 {
+		return recent, nil
+	}
+
+	type key struct {
+		client, name string
+		slot         int64
+	}
+	grouped := make(map[key]models.CustomRecord)
+	for _, rec := range recent {
+		k := key{rec.Client, rec.Name, rec.Time.ToTime().Truncate(15 * time.Minute).Unix()}
+		if old, ok := grouped[k]; !ok || rec.Time.ToTime().After(old.Time.ToTime()) {
+			grouped[k] = rec
+		}
+	}
+	result := make([]models.CustomRecord, 0, len(grouped)+len(longTerm))
+	for _, rec := range grouped {
+		result = append(result, rec)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Time.ToTime().Before(result[j].Time.ToTime()) })
+	return append(result, longTerm...), nil
+}
+
+// migrateCustomRecords 压缩自定义指标记录：gauge 取 0.7 分位，与 Record 一致；counter 取最大值
+func migrateCustomRecords(db *gorm.DB) error {
+	fourHoursAgo := time.Now().Add(-4 * time.Hour)
+
+	var recs []models.CustomRecord
+	if err := db.Table("custom_records").Where("time < ?", fourHoursAgo).Find(&recs).Error; err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+
+	var defs []models.CustomMetric
+	if err := db.Find(&defs).Error; err != nil {
+		return err
+	}
+	counters := make(map[string]bool)
+	for _, d := range defs {
+		if d.Type == common.CustomCounter {
+			counters[d.Client+"_"+d.Name] = true
+		}
+	}
+
+	type groupKey struct {
+		Client   string
+		Name     string
+		TimeSlot time.Time
+	}
+	grouped := make(map[groupKey][]float64)
+	for _, rec := range recs {
+		k := groupKey{rec.Client, rec.Name, rec.Time.ToTime().Truncate(15 * time.Minute)}
+		grouped[k] = append(grouped[k], rec.Value)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for k, values := range grouped {
+			sort.Float64s(values)
+			value := values[len(values)-1]
+			if !counters[k.Client+"_"+k.Name] {
+				value = percentile(values, 0.7)
+			}
+			newRec := models.CustomRecord{
+				Client: k.Client,
+				Name:   k.Name,
+				Time:   models.FromTime(k.TimeSlot),
+				Value:  value,
+			}
+
+			var existingCount int64
+			if err := tx.Table("custom_records_long_term").Where("client = ? AND name = ? AND time = ?", k.Client, k.Name, k.TimeSlot).Count(&existingCount).Error; err != nil {
+				return err
+			}
+			if existingCount > 0 {
+				if err := tx.Table("custom_records_long_term").Where("client = ? AND name = ? AND time = ?", k.Client, k.Name, k.TimeSlot).Update("value", value).Error; err != nil {
+					return err
+				}
+			} else {
+				if err := tx.Table("custom_records_long_term").Create(&newRec).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// 删除 custom_records 表中的旧数据
+		return tx.Table("custom_records").Where("time < ?", fourHoursAgo.Add(-1*time.Hour)).Delete(&models.CustomRecord{}).Error
+	})
+}
+
+// percentile 计算已排序数据的分位数，与 migrateOldRecords 中的插值方式相同
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := float64(len(sorted)-1) * p
+	lower := int(index)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := index - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
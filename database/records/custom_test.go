@@ -0,0 +1,46 @@
+package records
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestMigrateCustomRecords(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.CustomMetric{}, &models.CustomRecord{}))
+	assert.NoError(t, db.Table("custom_records_long_term").AutoMigrate(&models.CustomRecord{}))
+	assert.NoError(t, db.Create(&[]models.CustomMetric{
+		{Client: uuid, Name: "queue", Type: common.CustomGauge},
+		{Client: uuid, Name: "requests", Type: common.CustomCounter},
+	}).Error)
+
+	// 6 小时前的同一个 15 分钟时间段内写入 11 条记录
+	slot := time.Now().Add(-6 * time.Hour).Truncate(15 * time.Minute)
+	for i := 0; i <= 10; i++ {
+		ts := models.FromTime(slot.Add(time.Duration(i) * time.Minute))
+		assert.NoError(t, db.Create(&models.CustomRecord{Client: uuid, Name: "queue", Time: ts, Value: float64(i * 10)}).Error)
+		assert.NoError(t, db.Create(&models.CustomRecord{Client: uuid, Name: "requests", Time: ts, Value: float64(1000 + i)}).Error)
+	}
+	recent := models.FromTime(time.Now())
+	assert.NoError(t, db.Create(&models.CustomRecord{Client: uuid, Name: "queue", Time: recent, Value: 1}).Error)
+
+	assert.NoError(t, migrateCustomRecords(db))
+
+	var longTerm []models.CustomRecord
+	assert.NoError(t, db.Table("custom_records_long_term").Order("name").Find(&longTerm).Error)
+	assert.Len(t, longTerm, 2)
+	assert.InDelta(t, 70, longTerm[0].Value, 0.001)   // gauge 取 0.7 分位
+	assert.InDelta(t, 1010, longTerm[1].Value, 0.001) // counter 取最大值
+
+	var remain int64
+	assert.NoError(t, db.Table("custom_records").Count(&remain).Error)
+	assert.Equal(t, int64(1), remain)
+}
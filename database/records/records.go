@@ -34,6 +34,12 @@ func DeleteAll() error {
 	if err := db.Exec("DELETE FROM gpu_records").Error; err != nil {
 		return err
 	}
+	if err := db.Exec("DELETE FROM custom_records_long_term").Error; err != nil {
+		return err
+	}
+	if err := db.Exec("DELETE FROM custom_records").Error; err != nil {
+		return err
+	}
 	return db.Exec("DELETE FROM records").Error
 }
 
@@ -84,6 +90,8 @@ func DeleteRecordBefore(before time.Time) error {
 	db.Table("records_long_term").Where("time < ?", before).Delete(&models.Record{})
 	db.Table("gpu_records_long_term").Where("time < ?", before).Delete(&models.GPURecord{})
 	db.Where("time < ?", before).Delete(&models.GPURecord{})
+	db.Table("custom_records_long_term").Where("time < ?", before).Delete(&models.CustomRecord{})
+	db.Where("time < ?", before).Delete(&models.CustomRecord{})
 	return db.Where("time < ?", before).Delete(&models.Record{}).Error
 }
 
@@ -172,6 +180,12 @@ func CompactRecord() error {
 		return err
 	}
 
+	err = migrateCustomRecords(db)
+	if err != nil {
+		log.Printf("Error migrating custom metric records: %v", err)
+		return err
+	}
+
 	if flags.DatabaseType == "sqlite" {
 		if err := db.Exec("VACUUM").Error; err != nil {
 			log.Printf("Error vacuuming database: %v", err)
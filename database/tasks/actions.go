@@ -0,0 +1,77 @@
+package tasks
+
+import (
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm"
+)
+
+func AddThresholdAction(action *models.ThresholdAction) error {
+	db := dbcore.GetDBInstance()
+	return db.Create(action).Error
+}
+
+func EditThresholdAction(actions []*models.ThresholdAction) error {
+	db := dbcore.GetDBInstance()
+	for _, action := range actions {
+		// 使用 Select 以允许将 enable 等字段更新为零值
+		result := db.Model(&models.ThresholdAction{}).Where("id = ?", action.Id).
+			Select("name", "enable", "clients", "trigger", "type", "command", "webhook_url").Updates(action)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+func DeleteThresholdAction(id []uint) error {
+	db := dbcore.GetDBInstance()
+	result := db.Where("id IN ?", id).Delete(&models.ThresholdAction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return db.Where("action IN ?", id).Delete(&models.ThresholdActionLog{}).Error
+}
+
+func GetAllThresholdActions() (actions []models.ThresholdAction, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Find(&actions).Error
+	return
+}
+
+// GetThresholdActionsByTrigger 获取指定触发类型下已启用的动作
+func GetThresholdActionsByTrigger(trigger string) (actions []models.ThresholdAction, err error) {
+	db := dbcore.GetDBInstance()
+	err = db.Where("`trigger` = ? AND enable = ?", trigger, true).Find(&actions).Error
+	return
+}
+
+// ClaimThresholdAction 登记动作在本次触达中的执行，已登记过时返回 false
+func ClaimThresholdAction(actionId uint, client, key, taskId string) (bool, error) {
+	db := dbcore.GetDBInstance()
+	var count int64
+	if err := db.Model(&models.ThresholdActionLog{}).
+		Where("action = ? AND client = ? AND `key` = ?", actionId, client, key).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	err := db.Create(&models.ThresholdActionLog{Action: actionId, Client: client, Key: key, TaskId: taskId}).Error
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseThresholdAction 撤销动作的执行登记，下发失败时调用以便下次重试
+func ReleaseThresholdAction(actionId uint, client, key string) error {
+	db := dbcore.GetDBInstance()
+	return db.Where("action = ? AND client = ? AND `key` = ?", actionId, client, key).Delete(&models.ThresholdActionLog{}).Error
+}
@@ -1,23 +1,31 @@
 package tasks
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func AddPingTask(clients []string, name string, target, task_type string, interval int) (uint, error) {
-	db := dbcore.GetDBInstance()
-	task := models.PingTask{
+	return AddPingTaskWithOptions(models.PingTask{
 		Clients:  clients,
 		Name:     name,
 		Type:     task_type,
 		Target:   target,
 		Interval: interval,
-	}
+		Count:    1,
+	})
+}
+
+// AddPingTaskWithOptions 添加包含探测参数与告警规则的 Ping 任务
+func AddPingTaskWithOptions(task models.PingTask) (uint, error) {
+	db := dbcore.GetDBInstance()
+	task.Id = 0
 	if err := db.Create(&task).Error; err != nil {
 		return 0, err
 	}
@@ -31,6 +39,7 @@ func DeletePingTask(id []uint) error {
 	if result.RowsAffected == 0 {
 		return gorm.ErrRecordNotFound
 	}
+	db.Where("task IN ?", id).Delete(&models.PingAlertState{})
 	ReloadPingSchedule()
 	return result.Error
 }
@@ -38,15 +47,58 @@ func DeletePingTask(id []uint) error {
 func EditPingTask(tasks []*models.PingTask) error {
 	db := dbcore.GetDBInstance()
 	for _, task := range tasks {
-		result := db.Model(&models.PingTask{}).Where("id = ?", task.Id).Updates(task)
+		// 使用 Select 以允许将告警阈值等字段改回零值（不启用或默认值）
+		result := db.Model(&models.PingTask{}).Where("id = ?", task.Id).
+			Select("name", "clients", "type", "target", "interval", "count", "timeout", "ip_family",
+				"http_method", "expected_status", "alert_loss", "alert_p95", "alert_window").Updates(task)
+		if result.Error != nil {
+			return result.Error
+		}
 		if result.RowsAffected == 0 {
 			return gorm.ErrRecordNotFound
 		}
+		// 关闭的告警规则不再有告警状态
+		var disabled []string
+		if task.AlertLoss <= 0 {
+			disabled = append(disabled, "loss")
+		}
+		if task.AlertP95 <= 0 {
+			disabled = append(disabled, "p95")
+		}
+		if len(disabled) > 0 {
+			if err := db.Where("task = ? AND metric IN ?", task.Id, disabled).Delete(&models.PingAlertState{}).Error; err != nil {
+				return err
+			}
+		}
 	}
 	ReloadPingSchedule()
 	return nil
 }
 
+// GetPingAlertStates 获取处于告警中的规则，key 为 taskId:client:metric
+func GetPingAlertStates() (map[string]bool, error) {
+	db := dbcore.GetDBInstance()
+	var list []models.PingAlertState
+	if err := db.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	states := make(map[string]bool, len(list))
+	for _, s := range list {
+		states[fmt.Sprintf("%d:%s:%s", s.Task, s.Client, s.Metric)] = true
+	}
+	return states, nil
+}
+
+// SavePingAlertState 保存规则的告警状态，恢复时删除记录
+func SavePingAlertState(taskId uint, client, metric string, firing bool) error {
+	db := dbcore.GetDBInstance()
+	if !firing {
+		return db.Where("task = ? AND client = ? AND metric = ?", taskId, client, metric).Delete(&models.PingAlertState{}).Error
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.PingAlertState{Task: taskId, Client: client, Metric: metric}).Error
+}
+
 func GetAllPingTasks() ([]models.PingTask, error) {
 	db := dbcore.GetDBInstance()
 	var tasks []models.PingTask
@@ -8,21 +8,34 @@ import (
 )
 
 func CreateTask(taskId string, clients []string, command string) error {
-	db := dbcore.GetDBInstance()
-	// Create a new task in the database with clients as JSON array
-	task := models.Task{
+	return createTask(models.Task{
 		TaskId:  taskId,
 		Clients: models.StringArray(clients),
 		Command: command,
-	}
+	}, nil)
+}
+
+// CreateSnippetTask 创建由命令模板执行的任务，commands 为各客户端渲染后的命令
+func CreateSnippetTask(taskId string, snippetId int, template string, clients []string, commands map[string]string) error {
+	return createTask(models.Task{
+		TaskId:  taskId,
+		Clients: models.StringArray(clients),
+		Command: template,
+		Snippet: snippetId,
+	}, commands)
+}
+
+func createTask(task models.Task, commands map[string]string) error {
+	db := dbcore.GetDBInstance()
 	if err := db.Create(&task).Error; err != nil {
 		return err
 	}
 	var taskResults []models.TaskResult
-	for _, client := range clients {
+	for _, client := range task.Clients {
 		taskResults = append(taskResults, models.TaskResult{
-			TaskId:     taskId,
+			TaskId:     task.TaskId,
 			Client:     client,
+			Command:    commands[client],
 			Result:     "",
 			ExitCode:   nil,
 			FinishedAt: nil,
@@ -34,6 +47,7 @@ func CreateTask(taskId string, clients []string, command string) error {
 	}
 	return nil
 }
+
 func GetTaskByTaskId(taskId string) (*models.Task, error) {
 	var task models.Task
 	if err := dbcore.GetDBInstance().Where("task_id = ?", taskId).First(&task).Error; err != nil {
@@ -64,6 +78,14 @@ func GetAllTasksResultByUUID(uuid string) ([]models.TaskResult, error) {
 	}
 	return results, nil
 }
+
+// GetTasksBySnippet 获取由指定命令模板执行的任务
+func GetTasksBySnippet(snippetId int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := dbcore.GetDBInstance().Where("snippet = ?", snippetId).Find(&tasks).Error
+	return tasks, err
+}
+
 func GetAllTasks() ([]models.Task, error) {
 	var tasks []models.Task
 	if err := dbcore.GetDBInstance().Find(&tasks).Error; err != nil {
@@ -0,0 +1,198 @@
+package traffic
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/komari-monitor/komari/database/dbcore"
+	"github.com/komari-monitor/komari/database/models"
+	"gorm.io/gorm/clause"
+)
+
+// state 单个客户端当前周期的内存统计，定期写回数据库
+type state struct {
+	resetDay int
+	cycle    models.TrafficCycle
+	hasLast  bool // 是否已有上一次的累计计数
+	dirty    bool
+}
+
+var (
+	mu     sync.Mutex
+	states = map[string]*state{}
+)
+
+// CycleBounds 返回 t 所在计费周期的起止时间，resetDay 超出当月天数时取月末，非法值按 1 号处理
+func CycleBounds(t time.Time, resetDay int) (start, end time.Time) {
+	if resetDay < 1 || resetDay > 31 {
+		resetDay = 1
+	}
+	y, m, _ := t.Date()
+	start = resetDate(y, m, resetDay, t.Location())
+	if t.Before(start) {
+		start = resetDate(y, m-1, resetDay, t.Location())
+	}
+	sy, sm, _ := start.Date()
+	end = resetDate(sy, sm+1, resetDay, t.Location())
+	return start, end
+}
+
+func resetDate(y int, m time.Month, day int, loc *time.Location) time.Time {
+	first := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Accumulate 根据客户端上报的累计计数积分流量。
+// 计数小于上一次时视为 Agent 或主机重启导致的清零，本次增量取当前值。
+func Accumulate(clientUuid string, up, down int64, now time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := states[clientUuid]
+	if !ok {
+		s = loadState(clientUuid)
+		states[clientUuid] = s
+	}
+	rollover(clientUuid, s, now)
+
+	c := &s.cycle
+	if s.hasLast {
+		du, dd := up-c.LastUp, down-c.LastDown
+		if up < c.LastUp {
+			du = up
+		}
+		if down < c.LastDown {
+			dd = down
+		}
+		c.Up += du
+		c.Down += dd
+	}
+	c.LastUp, c.LastDown = up, down
+	c.Refresh()
+	s.hasLast = true
+	s.dirty = true
+}
+
+// rollover 当前时间已不在内存中的周期内（跨周期或修改了重置日）时切换到新周期
+func rollover(clientUuid string, s *state, now time.Time) {
+	start, end := CycleBounds(now.In(models.GetAppLocation()), s.resetDay)
+	if s.cycle.CycleStart.ToTime().Equal(start) && s.cycle.CycleEnd.ToTime().Equal(end) {
+		return
+	}
+	if s.dirty {
+		if err := save(s.cycle); err != nil {
+			log.Printf("Failed to save traffic cycle of client %s: %v", clientUuid, err)
+		}
+	}
+	next := models.TrafficCycle{Client: clientUuid, CycleStart: models.FromTime(start), CycleEnd: models.FromTime(end)}
+	db := dbcore.GetDBInstance()
+	var existing models.TrafficCycle
+	if err := db.Where("client = ? AND cycle_start = ?", clientUuid, next.CycleStart).First(&existing).Error; err == nil {
+		next.Id, next.Up, next.Down = existing.Id, existing.Up, existing.Down
+	}
+	// 累计计数跨周期延续
+	next.LastUp, next.LastDown = s.cycle.LastUp, s.cycle.LastDown
+	next.Refresh()
+	s.cycle = next
+	// 尚未收到过上报的客户端不写入空记录
+	s.dirty = s.hasLast
+}
+
+// loadState 从数据库恢复客户端的重置日与最近一个周期
+func loadState(clientUuid string) *state {
+	db := dbcore.GetDBInstance()
+	s := &state{resetDay: 1}
+	var client models.Client
+	if err := db.Select("traffic_reset_day").Where("uuid = ?", clientUuid).First(&client).Error; err == nil {
+		s.resetDay = client.TrafficResetDay
+	}
+	var latest models.TrafficCycle
+	if err := db.Where("client = ?", clientUuid).Order("cycle_start DESC").First(&latest).Error; err == nil {
+		s.cycle = latest
+		s.hasLast = true
+	}
+	return s
+}
+
+func save(cycle models.TrafficCycle) error {
+	db := dbcore.GetDBInstance()
+	cycle.Id = 0 // 以 (client, cycle_start) 判断冲突
+	cycle.Refresh()
+	cycle.UpdatedAt = models.FromTime(time.Now())
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client"}, {Name: "cycle_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cycle_end", "up", "down", "sum", "max", "min", "last_up", "last_down", "updated_at"}),
+	}).Create(&cycle).Error
+}
+
+// Flush 将内存中的统计写回数据库，并刷新各客户端的重置日，由外部每分钟调用一次
+func Flush() {
+	db := dbcore.GetDBInstance()
+	var resetDays []models.Client
+	db.Select("uuid", "traffic_reset_day").Find(&resetDays)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range resetDays {
+		if s, ok := states[c.UUID]; ok {
+			s.resetDay = c.TrafficResetDay
+		}
+	}
+	now := time.Now()
+	for uuid, s := range states {
+		rollover(uuid, s, now)
+		if !s.dirty {
+			continue
+		}
+		if err := save(s.cycle); err != nil {
+			log.Printf("Failed to save traffic cycle of client %s: %v", uuid, err)
+			continue
+		}
+		s.dirty = false
+	}
+}
+
+// Current 返回客户端当前周期的用量，客户端自服务启动后未上报时从数据库读取
+func Current(clientUuid string) models.TrafficCycle {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := states[clientUuid]
+	if !ok {
+		s = loadState(clientUuid)
+		states[clientUuid] = s
+	}
+	rollover(clientUuid, s, time.Now())
+	cycle := s.cycle
+	cycle.Refresh()
+	return cycle
+}
+
+// History 返回客户端最近 limit 个周期的用量（含当前周期），按时间倒序
+func History(clientUuid string, limit int) ([]models.TrafficCycle, error) {
+	current := Current(clientUuid)
+	if limit == 1 {
+		return []models.TrafficCycle{current}, nil
+	}
+	db := dbcore.GetDBInstance()
+	var cycles []models.TrafficCycle
+	query := db.Where("client = ? AND cycle_start < ?", clientUuid, current.CycleStart).Order("cycle_start DESC")
+	if limit > 1 {
+		query = query.Limit(limit - 1)
+	}
+	if err := query.Find(&cycles).Error; err != nil {
+		return nil, err
+	}
+	return append([]models.TrafficCycle{current}, cycles...), nil
+}
+
+// DeleteClient 删除客户端的全部流量统计
+func DeleteClient(clientUuid string) error {
+	mu.Lock()
+	delete(states, clientUuid)
+	mu.Unlock()
+	db := dbcore.GetDBInstance()
+	return db.Where("client = ?", clientUuid).Delete(&models.TrafficCycle{}).Error
+}
@@ -0,0 +1,44 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestCycleBounds(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name      string
+		now       time.Time
+		resetDay  int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"默认 1 号", date(2025, 3, 15), 0, date(2025, 3, 1), date(2025, 4, 1)},
+		{"重置日之后", date(2025, 3, 20), 15, date(2025, 3, 15), date(2025, 4, 15)},
+		{"重置日之前", date(2025, 3, 10), 15, date(2025, 2, 15), date(2025, 3, 15)},
+		{"重置日当天", date(2025, 3, 15), 15, date(2025, 3, 15), date(2025, 4, 15)},
+		{"月末截断", date(2025, 2, 28), 31, date(2025, 2, 28), date(2025, 3, 31)},
+		{"月末之前", date(2025, 2, 27), 31, date(2025, 1, 31), date(2025, 2, 28)},
+		{"跨年", date(2025, 1, 5), 10, date(2024, 12, 10), date(2025, 1, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := CycleBounds(tt.now, tt.resetDay)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("CycleBounds() = %v, %v; want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCycleUsed(t *testing.T) {
+	c := models.TrafficCycle{Up: 30, Down: 70}
+	for limitType, want := range map[string]int64{"up": 30, "down": 70, "sum": 100, "max": 70, "min": 30, "": 70} {
+		if got := c.Used(limitType); got != want {
+			t.Errorf("Used(%q) = %d, want %d", limitType, got, want)
+		}
+	}
+}
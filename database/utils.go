@@ -3,14 +3,12 @@ package database
 import (
 	"encoding/json"
 	"log"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/themes"
 )
 
 func GetPublicInfo() (any, error) {
@@ -18,69 +16,7 @@ func GetPublicInfo() (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	db := dbcore.GetDBInstance()
-	tc := models.ThemeConfiguration{}
-	err = db.Model(&models.ThemeConfiguration{}).Where("short = ?", cst.Theme).First(&tc).Error
-	if err != nil {
-		tc.Data = "{}"
-	}
-	tc_data := gin.H{}
-	err = json.Unmarshal([]byte(tc.Data), &tc_data)
-	if err != nil {
-		log.Printf("%v", err)
-	}
-	// Try to load theme declaration file and merge defaults for managed configuration
-	// Theme declarations live in ./data/theme/<short>/komari-theme.json
-	if cst.Theme != "" && cst.Theme != "default" {
-		themeConfigPath := filepath.Join("./data/theme", cst.Theme, "komari-theme.json")
-		if _, err := os.Stat(themeConfigPath); err == nil {
-			b, err := os.ReadFile(themeConfigPath)
-			if err == nil {
-				var themeDecl struct {
-					Configuration struct {
-						Type string                                 `json:"type"`
-						Data []models.ManagedThemeConfigurationItem `json:"data"`
-					} `json:"configuration"`
-				}
-				if err := json.Unmarshal(b, &themeDecl); err == nil {
-					if themeDecl.Configuration.Type == "managed" {
-						for _, item := range themeDecl.Configuration.Data {
-							if item.Key == "" {
-								continue
-							}
-							// missing
-							if _, exists := tc_data[item.Key]; !exists {
-								var def any = item.Default
-								// select
-								if item.Type == "select" {
-									if def == nil || def == "" {
-										if item.Options != "" {
-											opts := strings.Split(item.Options, ",")
-											if len(opts) > 0 {
-												def = strings.TrimSpace(opts[0])
-											}
-										}
-									}
-								}
-								// number->0, string->"", switch->false
-								if def == nil {
-									switch item.Type {
-									case "number":
-										def = 0
-									case "switch":
-										def = false
-									default:
-										def = ""
-									}
-								}
-								tc_data[item.Key] = def
-							}
-						}
-					}
-				}
-			}
-		}
-	}
+	tc_data := LoadThemeSettings(cst.Theme)
 
 	return gin.H{
 		"sitename":                  cst.Sitename,
@@ -99,3 +35,46 @@ func GetPublicInfo() (any, error) {
 		"theme_settings":            tc_data,
 	}, nil
 }
+
+// GetDashboardPublicInfo 返回公开面板的公开信息，以面板的标题、描述、主题与自定义内容覆盖站点设置
+func GetDashboardPublicInfo(d *models.Dashboard) (any, error) {
+	info, err := GetPublicInfo()
+	if err != nil {
+		return nil, err
+	}
+	m := info.(gin.H)
+	if d.Title != "" {
+		m["sitename"] = d.Title
+	}
+	if d.Description != "" {
+		m["description"] = d.Description
+	}
+	m["custom_head"] = m["custom_head"].(string) + d.CustomHead
+	m["custom_body"] = m["custom_body"].(string) + d.CustomBody
+	if d.Theme != "" {
+		m["theme"] = d.Theme
+		m["theme_settings"] = LoadThemeSettings(d.Theme)
+	}
+	m["dashboard"] = gin.H{"slug": d.Slug, "name": d.Name, "hidden_fields": d.HiddenFields}
+	return m, nil
+}
+
+// LoadThemeSettings 读取主题配置，并为托管配置项补全默认值
+func LoadThemeSettings(theme string) gin.H {
+	db := dbcore.GetDBInstance()
+	tc := models.ThemeConfiguration{}
+	err := db.Model(&models.ThemeConfiguration{}).Where("short = ?", theme).First(&tc).Error
+	if err != nil {
+		tc.Data = "{}"
+	}
+	tc_data := gin.H{}
+	err = json.Unmarshal([]byte(tc.Data), &tc_data)
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	// 托管配置补全默认值，配置声明位于 ./data/theme/<short>/komari-theme.json
+	if items, ok := themes.Schema(theme); ok {
+		themes.Resolve(items, tc_data)
+	}
+	return tc_data
+}
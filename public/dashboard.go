@@ -0,0 +1,134 @@
+package public
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/dashboards"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// serveDashboard 服务 /s/<slug> 公开面板页面
+func serveDashboard(c *gin.Context) {
+	d, err := dashboards.GetDashboardBySlug(c.Param("slug"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Dashboard not found")
+		return
+	}
+
+	if c.Request.Method == http.MethodPost {
+		if d.Access != models.DashboardAccessPassword {
+			renderDashboardLogin(c, d, "Incorrect password")
+			return
+		}
+		if wait := api.DashboardPasswordWait(c, d.Slug); wait > 0 {
+			renderDashboardLogin(c, d, fmt.Sprintf("Too many failed attempts, retry after %d seconds", wait))
+			return
+		}
+		if !dashboards.CheckPassword(d, c.PostForm("password")) {
+			api.RecordDashboardPasswordFailure(c, d.Slug)
+			renderDashboardLogin(c, d, "Incorrect password")
+			return
+		}
+		api.RecordDashboardPasswordSuccess(c, d.Slug)
+		api.SetDashboardCookie(c, d)
+		c.Redirect(http.StatusSeeOther, "/s/"+d.Slug)
+		return
+	}
+
+	if !api.DashboardAuthorized(c, d) {
+		if d.Access == models.DashboardAccessPassword {
+			renderDashboardLogin(c, d, "")
+			return
+		}
+		c.String(http.StatusForbidden, "Access denied")
+		return
+	}
+	// 通过分享链接访问后写入凭据，页面内的 RPC 请求无需再携带 token
+	if d.Access != models.DashboardAccessPublic && d.Access != "" {
+		api.SetDashboardCookie(c, d)
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to get configuration")
+		return
+	}
+	index := RawIndexFile
+	if theme := dashboardTheme(d, cfg); theme != "default" {
+		if data, err := os.ReadFile(filepath.Join("./data/theme", theme, "dist", "index.html")); err == nil {
+			index = string(data)
+		}
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html", []byte(applyCustomizations(index, dashboardConfig(cfg, d))))
+}
+
+// dashboardTheme 返回面板使用的主题，未设置时使用全局主题
+func dashboardTheme(d *models.Dashboard, cfg models.Config) string {
+	theme := d.Theme
+	if theme == "" {
+		theme = cfg.Theme
+	}
+	if theme == "" {
+		theme = "default"
+	}
+	return theme
+}
+
+// dashboardConfig 以面板设置覆盖站点名称、描述与自定义内容，并向页面注入面板 slug
+func dashboardConfig(cfg models.Config, d *models.Dashboard) models.Config {
+	if d.Title != "" {
+		cfg.Sitename = d.Title
+	}
+	if d.Description != "" {
+		cfg.Description = d.Description
+	}
+	slug, _ := json.Marshal(d.Slug)
+	cfg.CustomHead += fmt.Sprintf("<script>window.__KOMARI_DASHBOARD__=%s</script>", slug) + d.CustomHead
+	cfg.CustomBody += d.CustomBody
+	return cfg
+}
+
+func renderDashboardLogin(c *gin.Context, d *models.Dashboard, message string) {
+	title := d.Title
+	if title == "" {
+		title = d.Name
+	}
+	if message != "" {
+		message = `<p class="error">` + html.EscapeString(message) + `</p>`
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusUnauthorized, "text/html; charset=utf-8", []byte(fmt.Sprintf(dashboardLoginPage,
+		html.EscapeString(title), html.EscapeString(title), message)))
+}
+
+const dashboardLoginPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+<style>
+body{font-family:system-ui,sans-serif;display:flex;align-items:center;justify-content:center;min-height:100vh;margin:0;background:#f5f5f5}
+form{background:#fff;padding:2em;border-radius:8px;box-shadow:0 2px 8px rgba(0,0,0,.1);min-width:280px}
+input,button{width:100%%;box-sizing:border-box;padding:.6em;margin-top:.6em;font-size:1em}
+.error{color:#c00}
+</style>
+</head>
+<body>
+<form method="post">
+<h2>%s</h2>
+%s
+<input type="password" name="password" placeholder="Password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>`
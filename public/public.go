@@ -14,7 +14,9 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/api"
 	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/dashboards"
 	"github.com/komari-monitor/komari/database/models"
 )
 
@@ -132,6 +134,12 @@ func Static(r *gin.RouterGroup, noRoute func(handlers ...gin.HandlerFunc)) {
 
 	// Serve theme files from data/theme directory (for theme previews and static assets)
 	r.Static("/themes", "./data/theme")
+	// 主题配置中上传的图片
+	r.Static("/theme-assets", "./data/theme_assets")
+
+	// 公开面板
+	r.GET("/s/:slug", serveDashboard)
+	r.POST("/s/:slug", serveDashboard)
 
 	// 使用传入的noRoute函数来处理未匹配的路由
 	noRoute(func(c *gin.Context) {
@@ -145,14 +153,21 @@ func Static(r *gin.RouterGroup, noRoute func(handlers ...gin.HandlerFunc)) {
 
 		// 获取当前主题配置
 		cfg, err := config.Get()
-		if err != nil || cfg.Theme == "default" || cfg.Theme == "" {
+		theme := cfg.Theme
+		// 公开面板页面加载的静态资源使用面板的主题
+		if slug := api.DashboardSlug(c); err == nil && slug != "" && strings.Contains(path, ".") {
+			if d, err := dashboards.GetDashboardBySlug(slug); err == nil {
+				theme = dashboardTheme(d, cfg)
+			}
+		}
+		if err != nil || theme == "default" || theme == "" {
 			// 使用默认主题（embedded文件）
 			serveFromEmbedded(c, path)
 			return
 		}
 
 		// 使用自定义主题
-		serveFromTheme(c, path, cfg.Theme)
+		serveFromTheme(c, path, theme)
 	})
 }
 
@@ -0,0 +1,177 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/komari-monitor/komari/database/auditlog"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/ws"
+)
+
+const (
+	TriggerTraffic = "traffic" // 流量达到 100%
+	TriggerExpire  = "expire"  // 超过到期时间
+
+	TypeExec    = "exec"    // 通过任务通道在客户端执行命令
+	TypeWebhook = "webhook" // 调用 Webhook
+	TypeHide    = "hide"    // 在公开页面隐藏节点
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Validate 检查动作配置是否完整
+func Validate(a models.ThresholdAction) error {
+	switch a.Trigger {
+	case TriggerTraffic, TriggerExpire:
+	default:
+		return fmt.Errorf("invalid trigger: %s", a.Trigger)
+	}
+	switch a.Type {
+	case TypeExec:
+		if strings.TrimSpace(a.Command) == "" {
+			return errors.New("command is required for exec action")
+		}
+	case TypeWebhook:
+		u, err := url.Parse(a.WebhookUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("webhook_url must be a valid http(s) URL")
+		}
+	case TypeHide:
+	default:
+		return fmt.Errorf("invalid action type: %s", a.Type)
+	}
+	return nil
+}
+
+// Fire 对客户端执行指定触发类型下的全部动作。
+// key 标识一次阈值触达（如流量周期起始日、到期日），同一动作对同一 key 只执行一次。
+func Fire(trigger string, client models.Client, key, reason string) {
+	list, err := tasks.GetThresholdActionsByTrigger(trigger)
+	if err != nil {
+		return
+	}
+	for _, action := range list {
+		if len(action.Clients) > 0 && !contains(action.Clients, client.UUID) {
+			continue
+		}
+		taskId := utils.GenerateRandomString(16)
+		claimKey := trigger + ":" + key
+		claimed, err := tasks.ClaimThresholdAction(action.Id, client.UUID, claimKey, taskId)
+		if err != nil || !claimed {
+			continue
+		}
+		// 未能下发（如客户端离线）时撤销登记，下次检查时重试
+		if !run(action, client, taskId, reason) {
+			tasks.ReleaseThresholdAction(action.Id, client.UUID, claimKey)
+		}
+	}
+}
+
+// run 执行动作，返回 false 表示动作未能下发
+func run(action models.ThresholdAction, client models.Client, taskId, reason string) bool {
+	command := fmt.Sprintf("[action:%s] %s", action.Type, action.Name)
+	if action.Type == TypeExec {
+		command = action.Command
+	}
+	if err := tasks.CreateTask(taskId, []string{client.UUID}, command); err != nil {
+		auditlog.EventLog("action", fmt.Sprintf("Failed to create task for action %q on %s (%s): %v", action.Name, client.Name, client.UUID, err))
+		return false
+	}
+
+	var result string
+	exitCode := 0
+	dispatched := true
+	switch action.Type {
+	case TypeExec:
+		// 成功下发后执行结果由客户端通过任务通道回传
+		if err := sendExec(client.UUID, taskId, action.Command); err != nil {
+			result, exitCode = err.Error(), -1
+			dispatched = false
+		}
+	case TypeWebhook:
+		result, exitCode = callWebhook(action, client, reason)
+	case TypeHide:
+		if err := clients.SaveClient(map[string]interface{}{"uuid": client.UUID, "hidden": true}); err != nil {
+			result, exitCode = "Failed to hide client: "+err.Error(), 1
+		} else {
+			result = "Client hidden from public page"
+		}
+	}
+	if action.Type != TypeExec || exitCode != 0 {
+		tasks.SaveTaskResult(taskId, client.UUID, result, exitCode, models.FromTime(time.Now()))
+	}
+
+	status := "dispatched"
+	if action.Type != TypeExec {
+		status = fmt.Sprintf("exit code %d", exitCode)
+	}
+	if exitCode != 0 {
+		status = "failed: " + result
+	}
+	auditlog.EventLog("action", fmt.Sprintf("Action %q (%s) on %s (%s) triggered by %s, task id: %s, %s",
+		action.Name, action.Type, client.Name, client.UUID, reason, taskId, status))
+	return dispatched
+}
+
+// sendExec 通过 WebSocket 下发命令，与管理员手动执行命令使用相同的消息格式
+func sendExec(clientUuid, taskId, command string) error {
+	conn := ws.GetConnectedClients()[clientUuid]
+	if conn == nil {
+		return errors.New("Client offline!")
+	}
+	payload, _ := json.Marshal(map[string]string{
+		"message": "exec",
+		"command": command,
+		"task_id": taskId,
+	})
+	if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+		return fmt.Errorf("client connection is broken: %w", err)
+	}
+	return nil
+}
+
+func callWebhook(action models.ThresholdAction, client models.Client, reason string) (string, int) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"action":  action.Name,
+		"trigger": action.Trigger,
+		"reason":  reason,
+		"time":    time.Now().Format(time.RFC3339),
+		"client": map[string]interface{}{
+			"uuid":  client.UUID,
+			"name":  client.Name,
+			"group": client.Group,
+		},
+	})
+	resp, err := httpClient.Post(action.WebhookUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "Webhook request failed: " + err.Error(), 1
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	result := fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, 1
+	}
+	return result, 0
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
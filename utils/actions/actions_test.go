@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  models.ThresholdAction
+		wantErr bool
+	}{
+		{"执行命令", models.ThresholdAction{Trigger: TriggerTraffic, Type: TypeExec, Command: "shutdown -h now"}, false},
+		{"缺少命令", models.ThresholdAction{Trigger: TriggerTraffic, Type: TypeExec}, true},
+		{"Webhook", models.ThresholdAction{Trigger: TriggerExpire, Type: TypeWebhook, WebhookUrl: "https://example.com/hook"}, false},
+		{"Webhook 地址无效", models.ThresholdAction{Trigger: TriggerExpire, Type: TypeWebhook, WebhookUrl: "ftp://example.com"}, true},
+		{"隐藏节点", models.ThresholdAction{Trigger: TriggerExpire, Type: TypeHide}, false},
+		{"未知触发类型", models.ThresholdAction{Trigger: "cpu", Type: TypeHide}, true},
+		{"未知动作类型", models.ThresholdAction{Trigger: TriggerTraffic, Type: "reboot"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.action); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallWebhook(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &got)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(500)
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := models.Client{UUID: "u1", Name: "node"}
+	result, code := callWebhook(models.ThresholdAction{Name: "limit", Trigger: TriggerTraffic, WebhookUrl: srv.URL}, client, "traffic used 100.0%")
+	if code != 0 || result != "HTTP 200: ok" {
+		t.Errorf("callWebhook() = %q, %d", result, code)
+	}
+	if got["action"] != "limit" || got["reason"] != "traffic used 100.0%" || got["client"].(map[string]interface{})["uuid"] != "u1" {
+		t.Errorf("unexpected payload: %v", got)
+	}
+	if _, code := callWebhook(models.ThresholdAction{WebhookUrl: srv.URL + "/fail"}, client, ""); code == 0 {
+		t.Error("non-2xx response should fail")
+	}
+}
@@ -0,0 +1,219 @@
+// Package agentca 是签发 Agent 客户端证书的内置 CA
+package agentca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// Dir CA 证书与私钥的存放目录
+	Dir = "./data/agent-ca"
+	// Validity 签发的客户端证书有效期
+	Validity = 2 * 365 * 24 * time.Hour
+)
+
+var (
+	mu     sync.Mutex
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caPEM  []byte
+)
+
+// Issued 签发结果，私钥只在签发时返回，服务端不保存
+type Issued struct {
+	Serial      string
+	NotAfter    time.Time
+	Certificate []byte // PEM
+	PrivateKey  []byte // PEM
+}
+
+// Revoked CRL 中的一项
+type Revoked struct {
+	Serial    string
+	RevokedAt time.Time
+}
+
+// load 加载 CA，不存在时自动生成
+func load() error {
+	if caCert != nil {
+		return nil
+	}
+	certPath := filepath.Join(Dir, "ca.crt")
+	keyPath := filepath.Join(Dir, "ca.key")
+	certPEM, certErr := os.ReadFile(certPath)
+	keyPEM, keyErr := os.ReadFile(keyPath)
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		var err error
+		if certPEM, keyPEM, err = generate(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(Dir, 0700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+			return err
+		}
+	} else if certErr != nil || keyErr != nil {
+		return fmt.Errorf("failed to read agent CA: %v", errors.Join(certErr, keyErr))
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return errors.New("invalid agent CA files")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return err
+	}
+	caCert, caKey, caPEM = cert, key, certPEM
+	return nil
+}
+
+func generate() (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Komari Agent CA", Organization: []string{"Komari"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(20, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// CertificatePEM 返回 CA 证书
+func CertificatePEM() ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return nil, err
+	}
+	return caPEM, nil
+}
+
+// Pool 返回只包含本 CA 的证书池，用于校验客户端证书
+func Pool() (*x509.CertPool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, nil
+}
+
+// Issue 为客户端签发证书，CommonName 为客户端 UUID
+func Issue(clientUUID string) (Issued, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return Issued{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Issued{}, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return Issued{}, err
+	}
+	notAfter := time.Now().Add(Validity)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: clientUUID, Organization: []string{"Komari Agent"}},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return Issued{}, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return Issued{}, err
+	}
+	return Issued{
+		Serial:      SerialString(serial),
+		NotAfter:    notAfter,
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		PrivateKey:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}, nil
+}
+
+// CRL 生成证书吊销列表（PEM），number 应随吊销列表变化单调递增
+func CRL(revoked []Revoked, number int64) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return nil, err
+	}
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, r := range revoked {
+		serial, ok := new(big.Int).SetString(r.Serial, 16)
+		if !ok {
+			continue
+		}
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: r.RevokedAt})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(number),
+		ThisUpdate:                time.Now(),
+		NextUpdate:                time.Now().Add(24 * time.Hour),
+		RevokedCertificateEntries: entries,
+	}, caCert, caKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+// SerialString 证书序列号的统一字符串形式（小写十六进制）
+func SerialString(serial *big.Int) string {
+	return serial.Text(16)
+}
@@ -0,0 +1,59 @@
+package agentca
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func TestIssueAndRevoke(t *testing.T) {
+	Dir = t.TempDir()
+	caCert = nil
+
+	issued, err := Issue("7901508c-304f-49aa-b84f-957c33ae6f8a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(issued.Certificate)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, _ := Pool()
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Fatalf("issued certificate does not verify: %v", err)
+	}
+	if leaf.Subject.CommonName != "7901508c-304f-49aa-b84f-957c33ae6f8a" || SerialString(leaf.SerialNumber) != issued.Serial {
+		t.Fatalf("unexpected subject %q / serial %s", leaf.Subject.CommonName, issued.Serial)
+	}
+
+	// 重新从磁盘加载的 CA 应与之前一致
+	caCert = nil
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: must(Pool()), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Fatalf("CA was not persisted: %v", err)
+	}
+
+	crlPEM, err := CRL([]Revoked{{Serial: issued.Serial, RevokedAt: time.Now()}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ = pem.Decode(crlPEM)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crl.CheckSignatureFrom(caCert); err != nil {
+		t.Fatalf("CRL signature invalid: %v", err)
+	}
+	if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+		t.Fatal("revoked serial missing from CRL")
+	}
+}
+
+func must(pool *x509.CertPool, err error) *x509.CertPool {
+	if err != nil {
+		panic(err)
+	}
+	return pool
+}
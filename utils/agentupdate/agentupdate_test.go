@@ -0,0 +1,58 @@
+package agentupdate
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		os, arch string
+		wantOS   string
+		wantArch string
+	}{
+		{"Linux 发行版", "Ubuntu 22.04.3 LTS", "x86_64", "linux", "amd64"},
+		{"ARM 服务器", "Debian GNU/Linux 12", "aarch64", "linux", "arm64"},
+		{"Windows", "Microsoft Windows Server 2022", "amd64", "windows", "amd64"},
+		{"macOS", "macOS 14.2", "arm64", "darwin", "arm64"},
+		{"32 位 ARM", "Raspbian", "armv7l", "linux", "arm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goos, arch := Platform(models.Client{OS: tt.os, Arch: tt.arch})
+			if goos != tt.wantOS || arch != tt.wantArch {
+				t.Errorf("Platform() = %s/%s, want %s/%s", goos, arch, tt.wantOS, tt.wantArch)
+			}
+		})
+	}
+}
+
+func TestFirstStageSize(t *testing.T) {
+	tests := []struct {
+		total, percent, want int
+	}{
+		{0, 10, 0},
+		{5, 10, 1},
+		{20, 10, 2},
+		{21, 10, 3},
+		{8, 100, 8},
+	}
+	for _, tt := range tests {
+		if got := FirstStageSize(tt.total, tt.percent); got != tt.want {
+			t.Errorf("FirstStageSize(%d, %d) = %d, want %d", tt.total, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBinary(t *testing.T) {
+	if err := ValidateBinary("1.1.0", "linux", "amd64"); err != nil {
+		t.Errorf("ValidateBinary() = %v", err)
+	}
+	for _, c := range [][3]string{{"../1.0", "linux", "amd64"}, {"1.0", "plan9", "amd64"}, {"1.0", "linux", "x/../y"}} {
+		if ValidateBinary(c[0], c[1], c[2]) == nil {
+			t.Errorf("ValidateBinary(%q, %q, %q) should fail", c[0], c[1], c[2])
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package agentupdate 管理 Agent 二进制文件，并按分组分阶段下发升级指令。
+package agentupdate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	db "github.com/komari-monitor/komari/database/agentupdate"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// Dir Agent 二进制文件的保存目录，按 <version>/<os>-<arch> 存放
+const Dir = "./data/agent"
+
+// maxBinarySize 单个二进制文件的大小上限
+const maxBinarySize = 256 << 20
+
+var (
+	versionPattern = regexp.MustCompile(`^v?[0-9A-Za-z][0-9A-Za-z._+-]{0,48}$`)
+	archPattern    = regexp.MustCompile(`^[a-z0-9_]{1,20}$`)
+	supportedOS    = []string{"linux", "windows", "darwin", "freebsd"}
+)
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// NormalizeOS 将客户端上报的系统名称归类为 GOOS，例如 "Ubuntu 22.04" -> linux
+func NormalizeOS(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "windows"):
+		return "windows"
+	case strings.Contains(name, "darwin"), strings.Contains(name, "macos"), strings.Contains(name, "mac os"):
+		return "darwin"
+	case strings.Contains(name, "freebsd"):
+		return "freebsd"
+	case name == "":
+		return ""
+	default:
+		return "linux"
+	}
+}
+
+// NormalizeArch 将客户端上报的架构转换为 GOARCH，例如 x86_64 -> amd64
+func NormalizeArch(arch string) string {
+	arch = strings.ToLower(strings.TrimSpace(arch))
+	switch arch {
+	case "x86_64", "x64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64", "armv8", "armv8l":
+		return "arm64"
+	case "i386", "i686", "x86", "386":
+		return "386"
+	case "armv7", "armv7l", "armv6l", "arm":
+		return "arm"
+	default:
+		return arch
+	}
+}
+
+// Platform 返回客户端对应的二进制平台
+func Platform(c models.Client) (string, string) {
+	return NormalizeOS(c.OS), NormalizeArch(c.Arch)
+}
+
+// ValidateBinary 检查版本号与平台
+func ValidateBinary(version, goos, arch string) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("invalid version: %s", version)
+	}
+	supported := false
+	for _, s := range supportedOS {
+		if s == goos {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported os: %s", goos)
+	}
+	if !archPattern.MatchString(arch) {
+		return fmt.Errorf("invalid arch: %s", arch)
+	}
+	return nil
+}
+
+// BinaryPath 二进制文件在磁盘上的位置
+func BinaryPath(b models.AgentBinary) string {
+	return filepath.Join(Dir, b.Version, b.OS+"-"+b.Arch)
+}
+
+// StoreBinary 保存二进制文件并记录校验值，expectedSHA256 不为空时校验
+func StoreBinary(version, goos, arch string, r io.Reader, expectedSHA256, sourceURL string) (*models.AgentBinary, error) {
+	arch = NormalizeArch(arch)
+	if err := ValidateBinary(version, goos, arch); err != nil {
+		return nil, err
+	}
+	b := &models.AgentBinary{Version: version, OS: goos, Arch: arch, SourceURL: sourceURL}
+	target := BinaryPath(*b)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, err
+	}
+	// 使用唯一的临时文件，避免同一平台的并发上传互相覆盖
+	f, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	tmp := f.Name()
+	h := sha256.New()
+	err = f.Chmod(0644)
+	var n int64
+	if err == nil {
+		n, err = io.Copy(io.MultiWriter(f, h), io.LimitReader(r, maxBinarySize+1))
+	}
+	f.Close()
+	if err == nil && n > maxBinarySize {
+		err = fmt.Errorf("binary exceeds %d bytes", maxBinarySize)
+	}
+	if err == nil && n == 0 {
+		err = fmt.Errorf("binary is empty")
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if err == nil && expectedSHA256 != "" && !strings.EqualFold(expectedSHA256, sum) {
+		err = fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA256, sum)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	b.Size = n
+	b.SHA256 = sum
+	if err := db.SaveBinary(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// FetchBinary 从外部地址下载二进制文件并保存
+func FetchBinary(url, version, goos, arch, expectedSHA256 string) (*models.AgentBinary, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid url: %s", url)
+	}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+	return StoreBinary(version, goos, arch, resp.Body, expectedSHA256, url)
+}
+
+// RemoveBinary 删除二进制文件及其记录
+func RemoveBinary(b models.AgentBinary) error {
+	if err := os.Remove(BinaryPath(b)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	os.Remove(filepath.Dir(BinaryPath(b))) // 目录为空时一并删除
+	return db.DeleteBinary(b.Id)
+}
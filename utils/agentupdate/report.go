@@ -0,0 +1,109 @@
+package agentupdate
+
+import (
+	"sort"
+
+	db "github.com/komari-monitor/komari/database/agentupdate"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/ws"
+)
+
+// VersionCount 某个版本的客户端数量
+type VersionCount struct {
+	Version string `json:"version"`
+	Total   int    `json:"total"`
+	Online  int    `json:"online"`
+}
+
+// GroupVersions 分组内的版本分布与目标版本
+type GroupVersions struct {
+	Group         string         `json:"group"`
+	Total         int            `json:"total"`
+	TargetVersion string         `json:"target_version,omitempty"` // 未结束的升级计划的目标版本
+	RolloutId     uint           `json:"rollout_id,omitempty"`
+	RolloutStatus string         `json:"rollout_status,omitempty"`
+	UpToDate      int            `json:"up_to_date"` // 已达到目标版本的客户端数
+	Versions      []VersionCount `json:"versions"`
+}
+
+// Distribution 全部客户端的版本分布
+type Distribution struct {
+	Total    int             `json:"total"`
+	Versions []VersionCount  `json:"versions"`
+	Groups   []GroupVersions `json:"groups"`
+}
+
+// Report 统计客户端的 Agent 版本分布
+func Report() (Distribution, error) {
+	all, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return Distribution{}, err
+	}
+	online := map[string]bool{}
+	for _, uuid := range ws.GetAllOnlineUUIDs() {
+		online[uuid] = true
+	}
+	overall := map[string]*VersionCount{}
+	groups := map[string]*GroupVersions{}
+	groupVersions := map[string]map[string]*VersionCount{}
+	for _, c := range all {
+		version := c.Version
+		if version == "" {
+			version = "unknown"
+		}
+		count(overall, version, online[c.UUID])
+		g, ok := groups[c.Group]
+		if !ok {
+			g = &GroupVersions{Group: c.Group}
+			groups[c.Group] = g
+			groupVersions[c.Group] = map[string]*VersionCount{}
+		}
+		g.Total++
+		count(groupVersions[c.Group], version, online[c.UUID])
+	}
+	d := Distribution{Total: len(all), Versions: sortedCounts(overall)}
+	for name, g := range groups {
+		g.Versions = sortedCounts(groupVersions[name])
+		if r, err := db.GetActiveRolloutByGroup(name); err == nil {
+			g.TargetVersion = r.TargetVersion
+			g.RolloutId = r.Id
+			g.RolloutStatus = r.Status
+			for _, v := range g.Versions {
+				if v.Version != "unknown" && utils.CompareVersion(v.Version, r.TargetVersion) >= 0 {
+					g.UpToDate += v.Total
+				}
+			}
+		}
+		d.Groups = append(d.Groups, *g)
+	}
+	sort.Slice(d.Groups, func(i, j int) bool { return d.Groups[i].Group < d.Groups[j].Group })
+	return d, nil
+}
+
+func count(m map[string]*VersionCount, version string, online bool) {
+	v, ok := m[version]
+	if !ok {
+		v = &VersionCount{Version: version}
+		m[version] = v
+	}
+	v.Total++
+	if online {
+		v.Online++
+	}
+}
+
+// sortedCounts 按版本从新到旧排序
+func sortedCounts(m map[string]*VersionCount) []VersionCount {
+	list := make([]VersionCount, 0, len(m))
+	for _, v := range m {
+		list = append(list, *v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if c := utils.CompareVersion(list[i].Version, list[j].Version); c != 0 {
+			return c > 0
+		}
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
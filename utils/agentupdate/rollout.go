@@ -0,0 +1,368 @@
+package agentupdate
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/komari-monitor/komari/database/agentupdate"
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/messageSender"
+	"github.com/komari-monitor/komari/ws"
+)
+
+// RolloutOptions 创建升级计划的参数
+type RolloutOptions struct {
+	Group         string `json:"group"`
+	TargetVersion string `json:"target_version"`
+	Percent       int    `json:"percent"`       // 灰度阶段的客户端比例，100 表示一次全部升级
+	HealthWindow  int    `json:"health_window"` // 分钟
+	MaxFailures   int    `json:"max_failures"`
+}
+
+// updateMessage 下发给 Agent 的升级指令，Agent 通过 URL 下载并校验后替换自身
+type updateMessage struct {
+	Message   string `json:"message"` // update
+	RolloutId uint   `json:"rollout_id"`
+	Version   string `json:"version"`
+	URL       string `json:"url"` // 相对面板地址，需携带 Agent Token 访问
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+}
+
+// ResultMessage Agent 上报的升级结果，成功时 Agent 会以新版本重新连接
+type ResultMessage struct {
+	RolloutId uint   `json:"rollout_id"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+}
+
+var mu sync.Mutex
+
+// FirstStageSize 计算灰度阶段的客户端数量，至少为 1
+func FirstStageSize(total, percent int) int {
+	if total == 0 {
+		return 0
+	}
+	if percent >= 100 {
+		return total
+	}
+	n := (total*percent + 99) / 100
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// CreateRollout 为分组创建升级计划。已是目标版本的客户端跳过，
+// 缺少对应平台二进制文件时返回错误；在线的客户端优先进入灰度阶段。
+func CreateRollout(opts RolloutOptions) (*models.AgentRollout, error) {
+	if !versionPattern.MatchString(opts.TargetVersion) {
+		return nil, fmt.Errorf("invalid version: %s", opts.TargetVersion)
+	}
+	if opts.Percent < 1 || opts.Percent > 100 {
+		return nil, fmt.Errorf("percent must be between 1 and 100")
+	}
+	if opts.HealthWindow < 1 {
+		return nil, fmt.Errorf("health_window must be at least 1 minute")
+	}
+	if opts.MaxFailures < 1 {
+		opts.MaxFailures = 1
+	}
+	// 持有锁直到写入计划，避免并发请求为同一分组创建多个计划
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := db.GetActiveRolloutByGroup(opts.Group); err == nil {
+		return nil, fmt.Errorf("group %q already has an unfinished rollout", opts.Group)
+	}
+	all, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return nil, err
+	}
+	connected := ws.GetConnectedClients()
+	var eligible []models.Client
+	for _, c := range all {
+		if c.Group != opts.Group || (c.Version != "" && utils.CompareVersion(c.Version, opts.TargetVersion) >= 0) {
+			continue
+		}
+		goos, arch := Platform(c)
+		if _, err := db.FindBinary(opts.TargetVersion, goos, arch); err != nil {
+			return nil, fmt.Errorf("no %s binary for %s/%s (client %s)", opts.TargetVersion, goos, arch, c.Name)
+		}
+		eligible = append(eligible, c)
+	}
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("no clients in group %q need updating", opts.Group)
+	}
+	sort.SliceStable(eligible, func(i, j int) bool {
+		_, oi := connected[eligible[i].UUID]
+		_, oj := connected[eligible[j].UUID]
+		if oi != oj {
+			return oi
+		}
+		return eligible[i].UUID < eligible[j].UUID
+	})
+	first := FirstStageSize(len(eligible), opts.Percent)
+	r := &models.AgentRollout{
+		Group:          opts.Group,
+		TargetVersion:  opts.TargetVersion,
+		Percent:        opts.Percent,
+		HealthWindow:   opts.HealthWindow,
+		MaxFailures:    opts.MaxFailures,
+		Status:         models.RolloutRunning,
+		StageStartedAt: models.FromTime(time.Now()),
+	}
+	for i, c := range eligible {
+		stage := 0
+		if i >= first {
+			stage = 1
+		}
+		r.Targets = append(r.Targets, models.AgentRolloutTarget{
+			Client:      c.UUID,
+			Stage:       stage,
+			FromVersion: c.Version,
+			Status:      models.RolloutTargetPending,
+		})
+	}
+	if err := db.CreateRollout(r); err != nil {
+		return nil, err
+	}
+	go Tick()
+	return r, nil
+}
+
+// Tick 推进所有运行中的升级计划，由定时任务每分钟调用
+func Tick() {
+	mu.Lock()
+	defer mu.Unlock()
+	rollouts, err := db.ListActiveRollouts()
+	if err != nil {
+		log.Printf("Failed to load agent rollouts: %v", err)
+		return
+	}
+	for i := range rollouts {
+		if rollouts[i].Status == models.RolloutRunning {
+			advance(&rollouts[i], time.Now())
+		}
+	}
+}
+
+func advance(r *models.AgentRollout, now time.Time) {
+	window := time.Duration(r.HealthWindow) * time.Minute
+	connected := ws.GetConnectedClients()
+	reports := ws.GetLatestReport()
+	done := true
+	for i := range r.Targets {
+		t := &r.Targets[i]
+		if t.Stage != r.Stage {
+			continue
+		}
+		switch t.Status {
+		case models.RolloutTargetPending:
+			if conn, ok := connected[t.Client]; ok {
+				if err := send(conn, r, t); err != nil {
+					finishTarget(t, models.RolloutTargetFailed, err.Error(), now)
+				}
+			} else if now.Sub(r.StageStartedAt.ToTime()) > window {
+				finishTarget(t, models.RolloutTargetSkipped, "client offline", now)
+			}
+		case models.RolloutTargetSent:
+			client, err := clients.GetClientByUUID(t.Client)
+			if err != nil {
+				finishTarget(t, models.RolloutTargetSkipped, "client removed", now)
+				break
+			}
+			report, reported := reports[t.Client]
+			_, online := connected[t.Client]
+			if online && reported && utils.CompareVersion(client.Version, r.TargetVersion) >= 0 && report.UpdatedAt.After(t.SentAt.ToTime()) {
+				finishTarget(t, models.RolloutTargetHealthy, "", now)
+			} else if now.Sub(t.SentAt.ToTime()) > window {
+				finishTarget(t, models.RolloutTargetFailed, fmt.Sprintf("not healthy on %s within %d minutes (version %s)", r.TargetVersion, r.HealthWindow, client.Version), now)
+			}
+		}
+		if t.Status == models.RolloutTargetPending || t.Status == models.RolloutTargetSent {
+			done = false
+		}
+	}
+
+	failures := 0
+	for _, t := range r.Targets {
+		if t.Status == models.RolloutTargetFailed {
+			failures++
+		}
+	}
+	switch {
+	case failures >= r.MaxFailures:
+		halt(r, fmt.Sprintf("%d agent(s) failed to update", failures))
+	case !done:
+	case r.Stage == 0 && hasStage(r, 1):
+		r.Stage = 1
+		r.StageStartedAt = models.FromTime(now)
+		db.UpdateRollout(r.Id, map[string]interface{}{"stage": 1, "stage_started_at": r.StageStartedAt})
+		advance(r, now)
+	default:
+		r.Status = models.RolloutCompleted
+		db.UpdateRollout(r.Id, map[string]interface{}{"status": models.RolloutCompleted})
+		notify(r, "notify.rollout_completed", "✅")
+	}
+}
+
+func hasStage(r *models.AgentRollout, stage int) bool {
+	for _, t := range r.Targets {
+		if t.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func send(conn *ws.SafeConn, r *models.AgentRollout, t *models.AgentRolloutTarget) error {
+	client, err := clients.GetClientByUUID(t.Client)
+	if err != nil {
+		return err
+	}
+	goos, arch := Platform(client)
+	b, err := db.FindBinary(r.TargetVersion, goos, arch)
+	if err != nil {
+		return fmt.Errorf("no binary for %s/%s", goos, arch)
+	}
+	if err := conn.WriteJSON(updateMessage{
+		Message:   "update",
+		RolloutId: r.Id,
+		Version:   b.Version,
+		URL:       fmt.Sprintf("/api/clients/agent/binary/%d", b.Id),
+		SHA256:    b.SHA256,
+		Size:      b.Size,
+	}); err != nil {
+		return err
+	}
+	now := models.FromTime(time.Now())
+	t.Status = models.RolloutTargetSent
+	t.SentAt = &now
+	return db.UpdateTarget(t.Id, map[string]interface{}{"status": t.Status, "sent_at": now, "error": ""})
+}
+
+func finishTarget(t *models.AgentRolloutTarget, status, errMsg string, now time.Time) {
+	finished := models.FromTime(now)
+	t.Status = status
+	t.Error = errMsg
+	t.FinishedAt = &finished
+	db.UpdateTarget(t.Id, map[string]interface{}{"status": status, "error": errMsg, "finished_at": finished})
+}
+
+func halt(r *models.AgentRollout, reason string) {
+	r.Status = models.RolloutHalted
+	r.Message = reason
+	db.UpdateRollout(r.Id, map[string]interface{}{"status": r.Status, "message": reason})
+	log.Printf("Agent rollout %d halted: %s", r.Id, reason)
+	notify(r, "notify.rollout_halted", "⛔")
+}
+
+func notify(r *models.AgentRollout, key, emoji string) {
+	cfg, _ := config.Get()
+	group := r.Group
+	if group == "" {
+		group = "-"
+	}
+	go messageSender.SendEvent(models.EventMessage{
+		Event:   messageevent.AgentUpdate,
+		Time:    time.Now(),
+		Message: i18n.T(cfg.NotificationLocale, key, r.Id, group, r.TargetVersion, r.Message),
+		Emoji:   emoji,
+	})
+}
+
+// HandleResult 处理 Agent 上报的升级结果，失败时立即记为失败
+func HandleResult(client string, msg ResultMessage) {
+	if msg.Success {
+		// 成功与否以 Agent 重新连接后上报的版本为准
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t, err := db.FindSentTarget(msg.RolloutId, client)
+	if err != nil {
+		return
+	}
+	errMsg := msg.Error
+	if errMsg == "" {
+		errMsg = "agent reported failure"
+	}
+	finishTarget(t, models.RolloutTargetFailed, errMsg, time.Now())
+}
+
+// Pause 暂停运行中的升级计划，已下发的客户端仍会继续升级
+func Pause(id uint) error {
+	mu.Lock()
+	defer mu.Unlock()
+	r, err := db.GetRollout(id)
+	if err != nil {
+		return err
+	}
+	if r.Status != models.RolloutRunning {
+		return fmt.Errorf("rollout is %s", r.Status)
+	}
+	return db.UpdateRollout(id, map[string]interface{}{"status": models.RolloutPaused, "message": "paused by admin"})
+}
+
+// Resume 继续已暂停或自动终止的升级计划。retryFailed 为 true 时失败的客户端重新下发，
+// 否则视为已确认并跳过，不再计入失败次数。
+func Resume(id uint, retryFailed bool) error {
+	mu.Lock()
+	r, err := db.GetRollout(id)
+	if err != nil {
+		mu.Unlock()
+		return err
+	}
+	if r.Status != models.RolloutPaused && r.Status != models.RolloutHalted {
+		mu.Unlock()
+		return fmt.Errorf("rollout is %s", r.Status)
+	}
+	for _, t := range r.Targets {
+		if t.Status != models.RolloutTargetFailed {
+			continue
+		}
+		if retryFailed {
+			db.UpdateTarget(t.Id, map[string]interface{}{"status": models.RolloutTargetPending, "sent_at": nil, "finished_at": nil})
+		} else {
+			db.UpdateTarget(t.Id, map[string]interface{}{"status": models.RolloutTargetSkipped, "error": "failure acknowledged: " + t.Error})
+		}
+	}
+	err = db.UpdateRollout(id, map[string]interface{}{
+		"status":           models.RolloutRunning,
+		"message":          "",
+		"stage_started_at": models.FromTime(time.Now()),
+	})
+	mu.Unlock()
+	if err == nil {
+		go Tick()
+	}
+	return err
+}
+
+// Cancel 取消升级计划，尚未下发的客户端记为跳过
+func Cancel(id uint) error {
+	mu.Lock()
+	defer mu.Unlock()
+	r, err := db.GetRollout(id)
+	if err != nil {
+		return err
+	}
+	if r.Status == models.RolloutCompleted || r.Status == models.RolloutCancelled {
+		return fmt.Errorf("rollout is %s", r.Status)
+	}
+	now := time.Now()
+	for i := range r.Targets {
+		if r.Targets[i].Status == models.RolloutTargetPending {
+			finishTarget(&r.Targets[i], models.RolloutTargetSkipped, "rollout cancelled", now)
+		}
+	}
+	return db.UpdateRollout(id, map[string]interface{}{"status": models.RolloutCancelled, "message": "cancelled by admin"})
+}
@@ -0,0 +1,254 @@
+package billing
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+const gb = 1024 * 1024 * 1024
+
+// CycleMonths 将账单周期（天）换算为月数，常见周期与自动续费保持一致
+func CycleMonths(cycle int) float64 {
+	switch {
+	case cycle <= 0:
+		return 0
+	case cycle >= 27 && cycle <= 32:
+		return 1
+	case cycle >= 87 && cycle <= 95:
+		return 3
+	case cycle >= 175 && cycle <= 185:
+		return 6
+	case cycle >= 360 && cycle <= 370:
+		return 12
+	case cycle >= 720 && cycle <= 750:
+		return 24
+	case cycle >= 1080 && cycle <= 1150:
+		return 36
+	case cycle >= 1800 && cycle <= 1850:
+		return 60
+	default:
+		return float64(cycle) * 12 / 365
+	}
+}
+
+// MonthlyCost 按账单周期折算的月均费用，价格不大于 0（免费或未设置）时返回 0
+func MonthlyCost(price float64, cycle int) float64 {
+	months := CycleMonths(cycle)
+	if price <= 0 || months == 0 {
+		return 0
+	}
+	return price / months
+}
+
+// ClientCost 单个客户端的费用分析，金额均已换算为报表币种
+type ClientCost struct {
+	UUID             string           `json:"uuid"`
+	Name             string           `json:"name"`
+	Group            string           `json:"group"`
+	Tags             []string         `json:"tags"`
+	Provider         string           `json:"provider"`
+	Price            float64          `json:"price"`    // 原始价格
+	Currency         string           `json:"currency"` // 原始币种
+	BillingCycle     int              `json:"billing_cycle"`
+	Converted        bool             `json:"converted"` // 是否找到汇率，否则金额为 0
+	Monthly          float64          `json:"monthly"`
+	Annual           float64          `json:"annual"`
+	CpuCores         int              `json:"cpu_cores"`
+	CostPerCore      float64          `json:"cost_per_core"` // 每核心月均费用
+	TrafficLimitGB   float64          `json:"traffic_limit_gb"`
+	TrafficUsedGB    float64          `json:"traffic_used_gb"`
+	CostPerGB        float64          `json:"cost_per_gb"`      // 月均费用 / 流量配额
+	CostPerUsedGB    float64          `json:"cost_per_used_gb"` // 统计区间对应的费用 / 已用流量
+	ExpiredAt        models.LocalTime `json:"expired_at"`
+	RemainingSeconds int64            `json:"remaining_seconds"` // 距离到期的秒数，已过期或长期有效为 0
+	RemainingValue   float64          `json:"remaining_value"`   // 预付剩余价值
+}
+
+// Bucket 按某个维度汇总的费用
+type Bucket struct {
+	Key     string  `json:"key"`
+	Count   int     `json:"count"`
+	Monthly float64 `json:"monthly"`
+	Annual  float64 `json:"annual"`
+	// 以下仅在按币种汇总时有意义，为换算前的原始金额
+	OriginalMonthly float64 `json:"original_monthly,omitempty"`
+	OriginalAnnual  float64 `json:"original_annual,omitempty"`
+}
+
+// Report 费用分析报告
+type Report struct {
+	Currency            string       `json:"currency"`
+	RateSource          string       `json:"rate_source"`
+	GeneratedAt         time.Time    `json:"generated_at"`
+	TotalMonthly        float64      `json:"total_monthly"`
+	TotalAnnual         float64      `json:"total_annual"`
+	TotalRemainingValue float64      `json:"total_remaining_value"`
+	Unconverted         []string     `json:"unconverted"` // 缺少汇率的币种
+	Groups              []Bucket     `json:"groups"`
+	Tags                []Bucket     `json:"tags"`
+	Providers           []Bucket     `json:"providers"`
+	Currencies          []Bucket     `json:"currencies"`
+	Clients             []ClientCost `json:"clients"`
+}
+
+// Usage 客户端在统计区间内的已用流量，End 为区间的计划结束时间，
+// 月均费用按已过去的时长占整个区间的比例折算
+type Usage struct {
+	Bytes int64
+	Start time.Time
+	End   time.Time
+}
+
+// BuildReport 根据客户端与各自的已用流量生成费用报告
+func BuildReport(clients []models.Client, usage map[string]Usage, currency string, rates map[string]float64, now time.Time) Report {
+	currency = NormalizeCurrency(currency)
+	report := Report{Currency: currency, GeneratedAt: now, Unconverted: []string{}, Clients: []ClientCost{}}
+	groups := map[string]*Bucket{}
+	tags := map[string]*Bucket{}
+	providers := map[string]*Bucket{}
+	currencies := map[string]*Bucket{}
+	unconverted := map[string]bool{}
+
+	add := func(m map[string]*Bucket, key string, cost ClientCost) *Bucket {
+		b, ok := m[key]
+		if !ok {
+			b = &Bucket{Key: key}
+			m[key] = b
+		}
+		b.Count++
+		b.Monthly += cost.Monthly
+		b.Annual += cost.Annual
+		return b
+	}
+
+	for _, cl := range clients {
+		cost := clientCost(cl, usage[cl.UUID], currency, rates, now)
+		if !cost.Converted {
+			unconverted[cost.Currency] = true
+		}
+		report.TotalMonthly += cost.Monthly
+		report.TotalAnnual += cost.Annual
+		report.TotalRemainingValue += cost.RemainingValue
+
+		add(groups, cl.Group, cost)
+		for _, tag := range cost.Tags {
+			add(tags, tag, cost)
+		}
+		add(providers, cost.Provider, cost)
+		b := add(currencies, cost.Currency, cost)
+		monthly := MonthlyCost(cl.Price, cl.BillingCycle)
+		b.OriginalMonthly += monthly
+		b.OriginalAnnual += monthly * 12
+
+		report.Clients = append(report.Clients, cost)
+	}
+
+	for k := range unconverted {
+		report.Unconverted = append(report.Unconverted, k)
+	}
+	sort.Strings(report.Unconverted)
+	report.TotalMonthly = round(report.TotalMonthly)
+	report.TotalAnnual = round(report.TotalAnnual)
+	report.TotalRemainingValue = round(report.TotalRemainingValue)
+	report.Groups = sortedBuckets(groups)
+	report.Tags = sortedBuckets(tags)
+	report.Providers = sortedBuckets(providers)
+	report.Currencies = sortedBuckets(currencies)
+	return report
+}
+
+func clientCost(cl models.Client, used Usage, currency string, rates map[string]float64, now time.Time) ClientCost {
+	cost := ClientCost{
+		UUID:         cl.UUID,
+		Name:         cl.Name,
+		Group:        cl.Group,
+		Tags:         splitTags(cl.Tags),
+		Provider:     cl.Organization,
+		Price:        cl.Price,
+		Currency:     NormalizeCurrency(cl.Currency),
+		BillingCycle: cl.BillingCycle,
+		CpuCores:     cl.CpuCores,
+		ExpiredAt:    cl.ExpiredAt,
+	}
+	if cost.Provider == "" {
+		cost.Provider = "unknown"
+	}
+	factor, ok := Convert(1, cost.Currency, currency, rates)
+	cost.Converted = ok
+
+	cost.Monthly = MonthlyCost(cl.Price, cl.BillingCycle) * factor
+	cost.Annual = cost.Monthly * 12
+	if cl.CpuCores > 0 {
+		cost.CostPerCore = cost.Monthly / float64(cl.CpuCores)
+	}
+	if cl.TrafficLimit > 0 {
+		cost.TrafficLimitGB = float64(cl.TrafficLimit) / gb
+		cost.CostPerGB = cost.Monthly / cost.TrafficLimitGB
+	}
+	if used.Bytes > 0 {
+		cost.TrafficUsedGB = float64(used.Bytes) / gb
+		// 用量只覆盖区间内已过去的部分，费用按同样的比例折算，避免区间刚开始时单价虚高
+		cost.CostPerUsedGB = cost.Monthly * used.elapsed(now) / cost.TrafficUsedGB
+	}
+
+	// 超过 100 年视为长期有效，与自动续费的判断一致
+	expire := cl.ExpiredAt.ToTime()
+	if expire.After(now) && expire.Before(now.AddDate(100, 0, 0)) {
+		remaining := expire.Sub(now)
+		cost.RemainingSeconds = int64(remaining.Seconds())
+		if cl.Price > 0 && cl.BillingCycle > 0 {
+			cost.RemainingValue = cl.Price * remaining.Hours() / 24 / float64(cl.BillingCycle) * factor
+		}
+	}
+	return cost
+}
+
+// elapsed 返回区间已过去的比例，超过计划结束时间时大于 1
+func (u Usage) elapsed(now time.Time) float64 {
+	total := u.End.Sub(u.Start)
+	if total <= 0 {
+		return 1
+	}
+	elapsed := now.Sub(u.Start)
+	if elapsed < time.Minute {
+		elapsed = time.Minute
+	}
+	return float64(elapsed) / float64(total)
+}
+
+func splitTags(raw string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(raw, ";") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func sortedBuckets(m map[string]*Bucket) []Bucket {
+	result := make([]Bucket, 0, len(m))
+	for _, b := range m {
+		b.Monthly = round(b.Monthly)
+		b.Annual = round(b.Annual)
+		b.OriginalMonthly = round(b.OriginalMonthly)
+		b.OriginalAnnual = round(b.OriginalAnnual)
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Monthly != result[j].Monthly {
+			return result[i].Monthly > result[j].Monthly
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,117 @@
+package billing
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func almostEqual(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+
+func TestMonthlyCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		cycle int
+		want  float64
+	}{
+		{"月付", 10, 30, 10},
+		{"季付", 30, 90, 10},
+		{"年付", 120, 365, 10},
+		{"自定义周期", 73, 73, 30.42},
+		{"免费", -1, 30, 0},
+		{"未设置周期", 10, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthlyCost(tt.price, tt.cycle); !almostEqual(got, tt.want) {
+				t.Errorf("MonthlyCost(%v, %v) = %v, want %v", tt.price, tt.cycle, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := map[string]float64{"USD": 1, "CNY": 7}
+	cls := []models.Client{
+		{UUID: "a", Price: 10, Currency: "$", BillingCycle: 30, Group: "hk", Tags: "prod;web", Organization: "AS1", CpuCores: 2,
+			TrafficLimit: 100 * gb, ExpiredAt: models.FromTime(now.AddDate(0, 0, 15))},
+		{UUID: "b", Price: 840, Currency: "¥", BillingCycle: 365, Group: "hk", Tags: "prod", CpuCores: 4},
+		{UUID: "c", Price: 5, Currency: "XYZ", BillingCycle: 30},
+	}
+	usage := map[string]Usage{"a": {Bytes: 20 * gb, Start: now.AddDate(0, -1, 0), End: now}}
+	r := BuildReport(cls, usage, "USD", rates, now)
+
+	// 10 USD + 840/12/7 = 20 USD
+	if !almostEqual(r.TotalMonthly, 20) || !almostEqual(r.TotalAnnual, 240) {
+		t.Errorf("totals = %v / %v", r.TotalMonthly, r.TotalAnnual)
+	}
+	if len(r.Unconverted) != 1 || r.Unconverted[0] != "XYZ" {
+		t.Errorf("Unconverted = %v", r.Unconverted)
+	}
+	if r.Groups[0].Key != "hk" || r.Groups[0].Count != 2 || !almostEqual(r.Groups[0].Monthly, 20) {
+		t.Errorf("groups = %+v", r.Groups)
+	}
+	if r.Tags[0].Key != "prod" || r.Tags[0].Count != 2 {
+		t.Errorf("tags = %+v", r.Tags)
+	}
+	for _, b := range r.Currencies {
+		if b.Key == "CNY" && (!almostEqual(b.OriginalMonthly, 70) || !almostEqual(b.Monthly, 10)) {
+			t.Errorf("CNY bucket = %+v", b)
+		}
+	}
+	a := r.Clients[0]
+	if !almostEqual(a.CostPerCore, 5) || !almostEqual(a.CostPerGB, 0.1) || !almostEqual(a.CostPerUsedGB, 0.5) {
+		t.Errorf("unit costs = %+v", a)
+	}
+	if a.RemainingSeconds != 15*86400 || !almostEqual(a.RemainingValue, 5) {
+		t.Errorf("remaining = %v / %v", a.RemainingSeconds, a.RemainingValue)
+	}
+	if r.Clients[1].Provider != "unknown" || r.Clients[1].RemainingSeconds != 0 {
+		t.Errorf("client b = %+v", r.Clients[1])
+	}
+}
+
+func TestCostPerUsedGB(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	cl := models.Client{UUID: "a", Price: 30, Currency: "$", BillingCycle: 30}
+	tests := []struct {
+		name  string
+		start time.Time
+		want  float64
+	}{
+		{"区间已结束", now.AddDate(0, 0, -30), 1.5},
+		{"区间开始一天", now.AddDate(0, 0, -1), 0.05},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := Usage{Bytes: 20 * gb, Start: tt.start, End: tt.start.AddDate(0, 0, 30)}
+			r := BuildReport([]models.Client{cl}, map[string]Usage{"a": used}, "USD", map[string]float64{"USD": 1}, now)
+			if got := r.Clients[0].CostPerUsedGB; !almostEqual(got, tt.want) {
+				t.Errorf("CostPerUsedGB = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPRateSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"base":"EUR","rates":{"EUR":1,"USD":1.25,"CNY":9}}`))
+	}))
+	defer srv.Close()
+	rates, source, err := LoadRates(models.Config{ExchangeRateSource: "http", ExchangeRateUrl: srv.URL})
+	if err != nil || source != "http" {
+		t.Fatalf("LoadRates() = %v, %v", source, err)
+	}
+	if !almostEqual(rates["USD"], 1) || !almostEqual(rates["EUR"], 0.8) || !almostEqual(rates["CNY"], 7.2) {
+		t.Errorf("rates = %v", rates)
+	}
+	if _, err := ParseRates(`{"USD":0}`); err == nil {
+		t.Error("ParseRates should reject non-positive rates")
+	}
+}
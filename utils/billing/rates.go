@@ -0,0 +1,228 @@
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// DefaultRates 内置汇率表，表示 1 USD 可兑换的各币种数量，仅作为没有配置时的粗略参考
+var DefaultRates = map[string]float64{
+	"USD": 1,
+	"CNY": 7.2,
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"JPY": 150,
+	"HKD": 7.8,
+	"TWD": 32,
+	"KRW": 1350,
+	"SGD": 1.35,
+	"AUD": 1.52,
+	"CAD": 1.36,
+	"RUB": 92,
+	"INR": 83,
+}
+
+// currencySymbols 客户端 Currency 字段常用的货币符号
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"¥":   "CNY",
+	"￥":   "CNY",
+	"€":   "EUR",
+	"£":   "GBP",
+	"HK$": "HKD",
+	"NT$": "TWD",
+	"₩":   "KRW",
+	"S$":  "SGD",
+	"A$":  "AUD",
+	"C$":  "CAD",
+	"₽":   "RUB",
+	"₹":   "INR",
+}
+
+// NormalizeCurrency 将货币符号或代码统一为大写的 ISO 4217 代码，空值视为 USD
+func NormalizeCurrency(currency string) string {
+	currency = strings.TrimSpace(currency)
+	if currency == "" {
+		return "USD"
+	}
+	if code, ok := currencySymbols[currency]; ok {
+		return code
+	}
+	return strings.ToUpper(currency)
+}
+
+// ParseRates 解析 JSON 形式的汇率表，键会被规范化为货币代码
+func ParseRates(raw string) (map[string]float64, error) {
+	rates := map[string]float64{}
+	if strings.TrimSpace(raw) == "" {
+		return rates, nil
+	}
+	var parsed map[string]float64
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, err
+	}
+	for k, v := range parsed {
+		if v <= 0 {
+			return nil, fmt.Errorf("rate of %s must be positive", k)
+		}
+		rates[NormalizeCurrency(k)] = v
+	}
+	return rates, nil
+}
+
+// Convert 按汇率表换算金额，任一币种不在表中时返回 false
+func Convert(amount float64, from, to string, rates map[string]float64) (float64, bool) {
+	from, to = NormalizeCurrency(from), NormalizeCurrency(to)
+	if from == to {
+		return amount, true
+	}
+	rf, okf := rates[from]
+	rt, okt := rates[to]
+	if !okf || !okt {
+		return 0, false
+	}
+	return amount / rf * rt, true
+}
+
+// RateSource 汇率来源，返回 1 USD 可兑换的各币种数量
+type RateSource interface {
+	Name() string
+	Rates() (map[string]float64, error)
+}
+
+var (
+	rateSourcesMu sync.RWMutex
+	rateSources   = map[string]func(cfg models.Config) RateSource{}
+)
+
+// RegisterRateSource 注册汇率来源，name 对应配置项 exchange_rate_source
+func RegisterRateSource(name string, constructor func(cfg models.Config) RateSource) {
+	rateSourcesMu.Lock()
+	defer rateSourcesMu.Unlock()
+	rateSources[name] = constructor
+}
+
+func init() {
+	RegisterRateSource("static", func(cfg models.Config) RateSource {
+		return &staticRateSource{raw: cfg.ExchangeRates}
+	})
+	RegisterRateSource("http", func(cfg models.Config) RateSource {
+		return &httpRateSource{url: cfg.ExchangeRateUrl, fallback: &staticRateSource{raw: cfg.ExchangeRates}}
+	})
+}
+
+// LoadRates 按配置选择汇率来源并返回合并内置汇率后的汇率表，以及实际使用的来源名称
+func LoadRates(cfg models.Config) (map[string]float64, string, error) {
+	rateSourcesMu.RLock()
+	constructor, ok := rateSources[cfg.ExchangeRateSource]
+	if !ok {
+		constructor = rateSources["static"]
+	}
+	rateSourcesMu.RUnlock()
+	source := constructor(cfg)
+
+	loaded, err := source.Rates()
+	if err != nil {
+		return nil, source.Name(), err
+	}
+	rates := make(map[string]float64, len(DefaultRates)+len(loaded))
+	for k, v := range DefaultRates {
+		rates[k] = v
+	}
+	for k, v := range loaded {
+		rates[k] = v
+	}
+	return rates, source.Name(), nil
+}
+
+// staticRateSource 使用配置中的汇率表
+type staticRateSource struct {
+	raw string
+}
+
+func (s *staticRateSource) Name() string { return "static" }
+
+func (s *staticRateSource) Rates() (map[string]float64, error) {
+	return ParseRates(s.raw)
+}
+
+// httpRateSource 从远程接口获取汇率，兼容 {"rates":{...}} 与扁平的 JSON 对象，结果缓存 6 小时
+type httpRateSource struct {
+	url      string
+	fallback RateSource
+}
+
+type cachedRates struct {
+	url     string
+	rates   map[string]float64
+	fetched time.Time
+}
+
+var (
+	httpRatesMu    sync.Mutex
+	httpRatesCache cachedRates
+	httpRatesTTL   = 6 * time.Hour
+)
+
+func (s *httpRateSource) Name() string { return "http" }
+
+func (s *httpRateSource) Rates() (map[string]float64, error) {
+	if s.url == "" {
+		return s.fallback.Rates()
+	}
+	httpRatesMu.Lock()
+	defer httpRatesMu.Unlock()
+	if httpRatesCache.url == s.url && time.Since(httpRatesCache.fetched) < httpRatesTTL {
+		return httpRatesCache.rates, nil
+	}
+	rates, err := fetchRates(s.url)
+	if err != nil {
+		// 获取失败时继续使用上一次的结果
+		if httpRatesCache.url == s.url && httpRatesCache.rates != nil {
+			return httpRatesCache.rates, nil
+		}
+		return nil, err
+	}
+	httpRatesCache = cachedRates{url: s.url, rates: rates, fetched: time.Now()}
+	return rates, nil
+}
+
+func fetchRates(url string) (map[string]float64, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch exchange rates: HTTP status %s", resp.Status)
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate response: %w", err)
+	}
+	raw, ok := body["rates"]
+	if !ok {
+		// 扁平格式：{"USD":1,"CNY":7.2}
+		b, _ := json.Marshal(body)
+		raw = b
+	}
+	rates, err := ParseRates(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange rate response: %w", err)
+	}
+	// 以其他币种为基准的接口需换算为以 USD 为基准
+	if usd, ok := rates["USD"]; ok && usd != 1 {
+		for k, v := range rates {
+			rates[k] = v / usd
+		}
+	}
+	return rates, nil
+}
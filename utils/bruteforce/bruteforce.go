@@ -0,0 +1,106 @@
+// Package bruteforce 记录登录失败次数，提供指数退避与临时锁定
+package bruteforce
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxLockout 单次锁定的最长时间
+const MaxLockout = 24 * time.Hour
+
+type entry struct {
+	failures    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// Guard 按键（如 IP、用户名）统计失败次数
+type Guard struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+func New() *Guard {
+	return &Guard{entries: make(map[string]*entry), now: time.Now}
+}
+
+// Wait 返回距离允许再次尝试的剩余时间，取所有键中最长的一个
+func (g *Guard) Wait(keys ...string) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	var wait time.Duration
+	for _, k := range keys {
+		if e, ok := g.entries[k]; ok {
+			if d := e.lockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// Fail 记录一次失败并返回新的等待时间。
+// 未达到 limit 前按 1s、2s、4s... 退避；达到 limit 后锁定 lockout，此后每次失败翻倍，最长 MaxLockout。
+// locked 表示本次失败触发了（或延长了）锁定。
+func (g *Guard) Fail(limit int, lockout time.Duration, keys ...string) (wait time.Duration, locked bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	g.cleanup(now)
+	if limit <= 0 {
+		limit = 5
+	}
+	for _, k := range keys {
+		e, ok := g.entries[k]
+		if !ok {
+			e = &entry{}
+			g.entries[k] = e
+		}
+		e.failures++
+		e.lastFailure = now
+		var d time.Duration
+		if e.failures >= limit {
+			d = backoff(lockout, e.failures-limit)
+			locked = true
+		} else {
+			d = backoff(time.Second, e.failures-1)
+		}
+		e.lockedUntil = now.Add(d)
+		if d > wait {
+			wait = d
+		}
+	}
+	return wait, locked
+}
+
+// Success 登录成功后清除计数
+func (g *Guard) Success(keys ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, k := range keys {
+		delete(g.entries, k)
+	}
+}
+
+func backoff(base time.Duration, exp int) time.Duration {
+	d := base
+	for i := 0; i < exp && d < MaxLockout; i++ {
+		d *= 2
+	}
+	if d > MaxLockout {
+		d = MaxLockout
+	}
+	return d
+}
+
+// cleanup 清理已经解锁且长时间没有失败的记录
+func (g *Guard) cleanup(now time.Time) {
+	for k, e := range g.entries {
+		if now.After(e.lockedUntil) && now.Sub(e.lastFailure) > MaxLockout {
+			delete(g.entries, k)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bruteforce
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffAndLockout(t *testing.T) {
+	now := time.Unix(0, 0)
+	g := New()
+	g.now = func() time.Time { return now }
+
+	// 未达到上限时按 1s、2s 退避
+	if wait, locked := g.Fail(3, time.Minute, "ip:1.1.1.1"); wait != time.Second || locked {
+		t.Fatalf("first failure: wait=%v locked=%v", wait, locked)
+	}
+	if wait, _ := g.Fail(3, time.Minute, "ip:1.1.1.1"); wait != 2*time.Second {
+		t.Fatalf("second failure: wait=%v", wait)
+	}
+	// 达到上限后锁定，并随失败次数翻倍
+	if wait, locked := g.Fail(3, time.Minute, "ip:1.1.1.1"); wait != time.Minute || !locked {
+		t.Fatalf("third failure: wait=%v locked=%v", wait, locked)
+	}
+	if wait, _ := g.Fail(3, time.Minute, "ip:1.1.1.1"); wait != 2*time.Minute {
+		t.Fatalf("fourth failure: wait=%v", wait)
+	}
+	if wait := g.Wait("ip:1.1.1.1", "user:admin"); wait != 2*time.Minute {
+		t.Fatalf("Wait = %v", wait)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if wait := g.Wait("ip:1.1.1.1"); wait != 0 {
+		t.Fatalf("lock should expire, got %v", wait)
+	}
+
+	g.Success("ip:1.1.1.1")
+	if wait, _ := g.Fail(3, time.Minute, "ip:1.1.1.1"); wait != time.Second {
+		t.Fatalf("counter should reset after success, got %v", wait)
+	}
+
+	for i := 0; i < 40; i++ {
+		g.Fail(3, time.Minute, "user:root")
+	}
+	if wait := g.Wait("user:root"); wait != MaxLockout {
+		t.Fatalf("lockout should be capped, got %v", wait)
+	}
+}
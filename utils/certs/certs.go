@@ -0,0 +1,187 @@
+// Package certs 为内置 HTTPS 提供证书：本地证书文件（变更后自动重载）或 ACME 自动签发
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// reloadInterval 两次检查证书文件修改时间的最小间隔
+const reloadInterval = 5 * time.Second
+
+type Options struct {
+	CertFile string
+	KeyFile  string
+
+	ACMEDomains   []string
+	ACMEEmail     string
+	ACMEDirectory string // 为空时使用 Let's Encrypt 生产环境
+	ACMECACert    string // 额外信任的 ACME 服务器 CA（例如 Pebble 的测试 CA）
+	ACMECacheDir  string
+}
+
+// Enabled 是否需要启用 HTTPS
+func (o Options) Enabled() bool {
+	return o.CertFile != "" || len(o.ACMEDomains) > 0
+}
+
+type Manager struct {
+	reloader *fileReloader
+	acme     *autocert.Manager
+}
+
+func New(o Options) (*Manager, error) {
+	if len(o.ACMEDomains) > 0 && o.CertFile != "" {
+		return nil, errors.New("certificate files and ACME cannot be used together")
+	}
+	if o.CertFile != "" {
+		if o.KeyFile == "" {
+			return nil, errors.New("key file is required")
+		}
+		r := &fileReloader{certFile: o.CertFile, keyFile: o.KeyFile}
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+		return &Manager{reloader: r}, nil
+	}
+	if len(o.ACMEDomains) == 0 {
+		return nil, errors.New("no certificate source configured")
+	}
+
+	client := &acme.Client{DirectoryURL: o.ACMEDirectory}
+	if client.DirectoryURL == "" {
+		client.DirectoryURL = autocert.DefaultACMEDirectory
+	}
+	if o.ACMECACert != "" {
+		pem, err := os.ReadFile(o.ACMECACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ACME CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("invalid ACME CA certificate")
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		client.HTTPClient = &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	}
+	cacheDir := o.ACMECacheDir
+	if cacheDir == "" {
+		cacheDir = "./data/acme"
+	}
+	return &Manager{acme: &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(o.ACMEDomains...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      o.ACMEEmail,
+		Client:     client,
+	}}, nil
+}
+
+// TLSConfig 返回服务端 TLS 配置；ACME 模式下同时支持 TLS-ALPN-01 验证
+func (m *Manager) TLSConfig() *tls.Config {
+	if m.acme != nil {
+		cfg := m.acme.TLSConfig()
+		cfg.MinVersion = tls.VersionTLS12
+		return cfg
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: m.reloader.getCertificate,
+	}
+}
+
+// HTTPHandler 用于明文 HTTP 监听：ACME 模式下处理 HTTP-01 验证，其余请求交给 fallback
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	if m.acme != nil {
+		return m.acme.HTTPHandler(fallback)
+	}
+	return fallback
+}
+
+// RedirectHandler 将请求重定向到 HTTPS，httpsPort 为 443 或空时省略端口
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// fileReloader 在证书或私钥文件修改后自动重新加载
+type fileReloader struct {
+	certFile, keyFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func (r *fileReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = r.latestModTime()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *fileReloader) latestModTime() time.Time {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		if st, err := os.Stat(f); err == nil && st.ModTime().After(latest) {
+			latest = st.ModTime()
+		}
+	}
+	return latest
+}
+
+func (r *fileReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	check := time.Since(r.lastCheck) >= reloadInterval
+	if check {
+		r.lastCheck = time.Now()
+	}
+	modTime := r.modTime
+	r.mu.Unlock()
+
+	if check && r.latestModTime().After(modTime) {
+		// 加载失败（例如只写入了证书还未写入私钥）时继续使用旧证书
+		if err := r.load(); err != nil {
+			log.Printf("TLS certificate reload failed: %v", err)
+		} else {
+			log.Println("TLS certificate reloaded")
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
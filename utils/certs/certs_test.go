@@ -0,0 +1,120 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSelfSigned(t *testing.T, dir, cn string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	os.WriteFile(filepath.Join(dir, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	writeSelfSigned(t, dir, "first")
+	m, err := New(Options{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := m.TLSConfig()
+	cert, _ := cfg.GetCertificate(nil)
+	if commonName(t, cert) != "first" {
+		t.Fatal("initial certificate not loaded")
+	}
+
+	writeSelfSigned(t, dir, "second")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, "cert.pem"), future, future)
+	m.reloader.lastCheck = time.Time{}
+	cert, _ = cfg.GetCertificate(nil)
+	if commonName(t, cert) != "second" {
+		t.Fatal("certificate was not reloaded")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		port string
+		want string
+	}{
+		{name: "默认端口", host: "komari.example:80", port: "443", want: "https://komari.example/admin?a=1"},
+		{name: "自定义端口", host: "komari.example", port: "25774", want: "https://komari.example:25774/admin?a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin?a=1", nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			RedirectHandler(tt.port).ServeHTTP(w, req)
+			if got := w.Header().Get("Location"); got != tt.want {
+				t.Fatalf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestACMEPebble 针对本地 Pebble 服务器申请证书，默认跳过。
+// 运行: PEBBLE_VA_ALWAYS_VALID=1 pebble -config test/config/pebble-config.json
+// 然后设置 KOMARI_TEST_ACME_DIRECTORY=https://localhost:14000/dir 与 KOMARI_TEST_ACME_CA=pebble.minica.pem
+func TestACMEPebble(t *testing.T) {
+	directory := os.Getenv("KOMARI_TEST_ACME_DIRECTORY")
+	if directory == "" {
+		t.Skip("KOMARI_TEST_ACME_DIRECTORY not set")
+	}
+	m, err := New(Options{
+		ACMEDomains:   []string{"komari.test"},
+		ACMEDirectory: directory,
+		ACMECACert:    os.Getenv("KOMARI_TEST_ACME_CA"),
+		ACMECacheDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := m.TLSConfig().GetCertificate(&tls.ClientHelloInfo{
+		ServerName:      "komari.test",
+		CipherSuites:    []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		SupportedCurves: []tls.CurveID{tls.CurveP256},
+	})
+	if err != nil {
+		t.Fatalf("failed to obtain certificate: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	if len(leaf.DNSNames) == 0 || leaf.DNSNames[0] != "komari.test" {
+		t.Fatalf("unexpected certificate names %v", leaf.DNSNames)
+	}
+}
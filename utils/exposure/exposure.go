@@ -0,0 +1,363 @@
+// Package exposure 集中决定客户端与记录字段对不同受众的可见性，
+// 所有对外返回节点信息的接口都应通过这里过滤，避免新增字段意外泄露。
+package exposure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// Audience 数据的受众，数值越大权限越高
+type Audience int
+
+const (
+	Guest  Audience = iota // 匿名访客
+	Viewer                 // 通过密码或分享链接授权访问面板的查看者
+	Admin                  // 已登录的管理员
+	Nobody                 // 任何人不可见，用于面板隐藏字段
+)
+
+var audienceNames = map[string]Audience{"guest": Guest, "viewer": Viewer, "admin": Admin}
+
+func (a Audience) String() string {
+	for name, v := range audienceNames {
+		if v == a {
+			return name
+		}
+	}
+	return "nobody"
+}
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// 受策略控制的字段
+const (
+	FieldIP           = "ip"            // IPv4 / IPv6，不可见时按 send_ip_addr_to_guest 决定是否打码
+	FieldRegion       = "region"        // 国家/地区
+	FieldGeo          = "geo"           // 城市、坐标与 ASN
+	FieldOS           = "os"            // 系统、内核、架构与虚拟化
+	FieldCPU          = "cpu"           // CPU / GPU 型号与核心数
+	FieldVersion      = "version"       // Agent 版本
+	FieldPrice        = "price"         // 价格与账单周期
+	FieldExpiry       = "expired_at"    // 到期时间
+	FieldTrafficLimit = "traffic_limit" // 流量配额与重置日
+	FieldTrafficUsed  = "traffic_used"  // 累计流量
+	FieldPublicRemark = "public_remark" // 公开备注
+	FieldTags         = "tags"          // 标签
+	FieldPingTarget   = "ping_target"   // Ping 任务目标地址
+)
+
+// Fields 全部可配置的字段
+var Fields = []string{
+	FieldIP, FieldRegion, FieldGeo, FieldOS, FieldCPU, FieldVersion, FieldPrice, FieldExpiry,
+	FieldTrafficLimit, FieldTrafficUsed, FieldPublicRemark, FieldTags, FieldPingTarget,
+}
+
+// Policy 字段到最低可见受众的映射，未列出的字段仅管理员可见
+type Policy map[string]Audience
+
+// DefaultPolicy 与旧版开关保持一致的默认策略
+func DefaultPolicy(cfg models.Config) Policy {
+	p := Policy{}
+	for _, f := range Fields {
+		p[f] = Guest
+	}
+	p[FieldIP] = Admin
+	p[FieldVersion] = Admin
+	p[FieldPingTarget] = Admin
+	if !cfg.SendGeoDetailsToGuest {
+		p[FieldGeo] = Admin
+	}
+	return p
+}
+
+// ParseOverrides 解析配置中的 JSON 策略，例如 {"price":"viewer","os":"admin"}
+func ParseOverrides(raw string) (Policy, error) {
+	p := Policy{}
+	if strings.TrimSpace(raw) == "" {
+		return p, nil
+	}
+	var parsed map[string]string
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, err
+	}
+	for field, name := range parsed {
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		a, ok := audienceNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid audience of %s: %s", field, name)
+		}
+		p[field] = a
+	}
+	return p, nil
+}
+
+// Load 返回默认策略合并配置覆盖后的结果，配置非法时使用默认策略
+func Load(cfg models.Config) Policy {
+	p := DefaultPolicy(cfg)
+	overrides, err := ParseOverrides(cfg.ExposurePolicy)
+	if err != nil {
+		return p
+	}
+	for f, a := range overrides {
+		p[f] = a
+	}
+	return p
+}
+
+// WithDashboard 叠加面板隐藏的字段
+func (p Policy) WithDashboard(d *models.Dashboard) Policy {
+	if d == nil || len(d.HiddenFields) == 0 {
+		return p
+	}
+	merged := make(Policy, len(p))
+	for f, a := range p {
+		merged[f] = a
+	}
+	for _, f := range d.HiddenFields {
+		merged[f] = Nobody
+	}
+	return merged
+}
+
+// Allows 判断受众能否看到字段
+func (p Policy) Allows(field string, a Audience) bool {
+	min, ok := p[field]
+	if !ok {
+		min = Admin
+	}
+	return a >= min
+}
+
+// AudienceOf 决定一次请求的受众，通过面板访问时不使用管理员视角
+func AudienceOf(isAdmin bool, d *models.Dashboard) Audience {
+	if d != nil {
+		if d.Access == models.DashboardAccessPassword || d.Access == models.DashboardAccessToken {
+			return Viewer
+		}
+		return Guest
+	}
+	if isAdmin {
+		return Admin
+	}
+	return Guest
+}
+
+// Client 按策略保留受众可见的客户端字段。非管理员使用白名单复制，models.Client 新增的字段默认不可见；
+// Token 对任何受众都不返回，私有备注仅管理员可见。
+// maskIP 为 true 时不可见的 IP 以打码形式返回，面板隐藏的 IP 始终清除。
+func (p Policy) Client(c *models.Client, a Audience, maskIP bool) {
+	c.Token = ""
+	if a >= Admin {
+		return
+	}
+	out := models.Client{
+		UUID:      c.UUID,
+		Name:      c.Name,
+		Group:     c.Group,
+		Weight:    c.Weight,
+		Hidden:    c.Hidden,
+		MemTotal:  c.MemTotal,
+		SwapTotal: c.SwapTotal,
+		DiskTotal: c.DiskTotal,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if p.Allows(FieldIP, a) {
+		out.IPv4 = c.IPv4
+		out.IPv6 = c.IPv6
+	} else if maskIP && p[FieldIP] != Nobody {
+		if c.IPv4 != "" {
+			out.IPv4 = strings.Split(c.IPv4, ".")[0] + ".*.*.*"
+		}
+		if c.IPv6 != "" {
+			out.IPv6 = strings.Split(c.IPv6, ":")[0] + ":*:*:*:*:*:*:*"
+		}
+	}
+	if p.Allows(FieldRegion, a) {
+		out.Region = c.Region
+	}
+	if p.Allows(FieldGeo, a) {
+		out.City = c.City
+		out.Latitude = c.Latitude
+		out.Longitude = c.Longitude
+		out.ASN = c.ASN
+		out.Organization = c.Organization
+	}
+	if p.Allows(FieldOS, a) {
+		out.OS = c.OS
+		out.KernelVersion = c.KernelVersion
+		out.Arch = c.Arch
+		out.Virtualization = c.Virtualization
+	}
+	if p.Allows(FieldCPU, a) {
+		out.CpuName = c.CpuName
+		out.CpuCores = c.CpuCores
+		out.GpuName = c.GpuName
+	}
+	if p.Allows(FieldVersion, a) {
+		out.Version = c.Version
+	}
+	if p.Allows(FieldPrice, a) {
+		out.Price = c.Price
+		out.BillingCycle = c.BillingCycle
+		out.Currency = c.Currency
+		out.AutoRenewal = c.AutoRenewal
+	}
+	if p.Allows(FieldExpiry, a) {
+		out.ExpiredAt = c.ExpiredAt
+	}
+	if p.Allows(FieldTrafficLimit, a) {
+		out.TrafficLimit = c.TrafficLimit
+		out.TrafficLimitType = c.TrafficLimitType
+		out.TrafficResetDay = c.TrafficResetDay
+	}
+	if p.Allows(FieldPublicRemark, a) {
+		out.PublicRemark = c.PublicRemark
+	}
+	if p.Allows(FieldTags, a) {
+		out.Tags = c.Tags
+	}
+	*c = out
+}
+
+// Record 按策略清除历史记录中受众不可见的字段
+func (p Policy) Record(r *models.Record, a Audience) {
+	if !p.Allows(FieldTrafficUsed, a) {
+		r.NetTotalUp = 0
+		r.NetTotalDown = 0
+	}
+}
+
+// Report 按策略清除实时上报中受众不可见的字段
+func (p Policy) Report(r *common.Report, a Audience) {
+	if !p.Allows(FieldTrafficUsed, a) {
+		r.Network.TotalUp = 0
+		r.Network.TotalDown = 0
+	}
+	if !p.Allows(FieldCPU, a) {
+		r.CPU.Name = ""
+		r.CPU.Cores = 0
+	}
+	if !p.Allows(FieldOS, a) {
+		r.CPU.Arch = ""
+	}
+	if a < Admin && len(r.Custom) > 0 {
+		// 自定义指标仅公开的对非管理员可见，重新分配以免修改共享的原始数据
+		public := make(map[string]common.CustomMetric)
+		for name, m := range r.Custom {
+			if m.Public {
+				public[name] = m
+			}
+		}
+		r.Custom = public
+	}
+}
+
+// View 一次请求的可见性规则，由受众、字段策略与关联的面板决定
+type View struct {
+	Audience  Audience
+	Dashboard *models.Dashboard
+	policy    Policy
+	maskIP    bool
+}
+
+// NewView 根据配置、是否为管理员以及访问的面板构建可见性规则
+func NewView(cfg models.Config, isAdmin bool, d *models.Dashboard) View {
+	return View{
+		Audience:  AudienceOf(isAdmin, d),
+		Dashboard: d,
+		policy:    Load(cfg).WithDashboard(d),
+		maskIP:    cfg.SendIpAddrToGuest,
+	}
+}
+
+// Unrestricted 管理员直接访问时不做任何过滤
+func (v View) Unrestricted() bool {
+	return v.Audience == Admin && v.Dashboard == nil
+}
+
+// Allows 判断当前受众能否看到字段
+func (v View) Allows(field string) bool {
+	return v.policy.Allows(field, v.Audience)
+}
+
+// ClientVisible 非管理员不可见 Hidden 节点，通过面板访问时仅可见面板选择的节点
+func (v View) ClientVisible(c models.Client) bool {
+	if v.Unrestricted() {
+		return true
+	}
+	return !c.Hidden && (v.Dashboard == nil || v.Dashboard.Matches(c))
+}
+
+// HiddenSet 返回列表中对当前受众不可见的客户端 UUID
+func (v View) HiddenSet(list []models.Client) map[string]bool {
+	hidden := map[string]bool{}
+	for _, c := range list {
+		if !v.ClientVisible(c) {
+			hidden[c.UUID] = true
+		}
+	}
+	return hidden
+}
+
+// Clients 过滤不可见的客户端，并清除其中不可见的字段
+func (v View) Clients(list []models.Client) []models.Client {
+	result := make([]models.Client, 0, len(list))
+	for _, c := range list {
+		if !v.ClientVisible(c) {
+			continue
+		}
+		v.Client(&c)
+		result = append(result, c)
+	}
+	return result
+}
+
+// Client 清除客户端中不可见的字段
+func (v View) Client(c *models.Client) {
+	v.policy.Client(c, v.Audience, v.maskIP)
+}
+
+// Record 清除历史记录中不可见的字段
+func (v View) Record(r *models.Record) {
+	v.policy.Record(r, v.Audience)
+}
+
+// CustomMetrics 过滤当前受众不可见的自定义指标，非管理员仅可见公开的指标
+func (v View) CustomMetrics(list []models.CustomMetric) []models.CustomMetric {
+	if v.Audience >= Admin {
+		return list
+	}
+	result := make([]models.CustomMetric, 0, len(list))
+	for _, m := range list {
+		if m.Public {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Report 返回清除不可见字段后的实时上报副本，不修改共享的原始数据
+func (v View) Report(r *common.Report) common.Report {
+	cp := *r
+	v.policy.Report(&cp, v.Audience)
+	return cp
+}
+
+func isField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
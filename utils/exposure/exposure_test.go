@@ -0,0 +1,113 @@
+package exposure
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestViewClients(t *testing.T) {
+	client := models.Client{
+		UUID: "a", Name: "tokyo-1", Token: "tk", Remark: "private", IPv4: "1.2.3.4", City: "Tokyo",
+		OS: "Debian", Price: 5, Currency: "$", Version: "1.0", Group: "jp",
+	}
+	hidden := models.Client{UUID: "b", Hidden: true}
+	other := models.Client{UUID: "c", Group: "us"}
+	all := []models.Client{client, hidden, other}
+
+	tests := []struct {
+		name      string
+		cfg       models.Config
+		isAdmin   bool
+		dashboard *models.Dashboard
+		wantUUIDs []string
+		check     func(t *testing.T, c models.Client)
+	}{
+		{"管理员不受限制", models.Config{}, true, nil, []string{"a", "b", "c"}, func(t *testing.T, c models.Client) {
+			if c.IPv4 != "1.2.3.4" || c.Remark != "private" || c.Version != "1.0" {
+				t.Errorf("admin should see all fields: %+v", c)
+			}
+			if c.Token != "" {
+				t.Errorf("token should never be returned: %+v", c)
+			}
+		}},
+		{"访客默认策略", models.Config{}, false, nil, []string{"a", "c"}, func(t *testing.T, c models.Client) {
+			if c.Token != "" || c.Remark != "" || c.IPv4 != "" || c.City != "" || c.Version != "" {
+				t.Errorf("guest should not see private fields: %+v", c)
+			}
+			if c.OS != "Debian" || c.Price != 5 || c.Name != "tokyo-1" || c.Group != "jp" {
+				t.Errorf("guest should see public fields: %+v", c)
+			}
+		}},
+		{"访客 IP 打码", models.Config{SendIpAddrToGuest: true}, false, nil, []string{"a", "c"}, func(t *testing.T, c models.Client) {
+			if c.IPv4 != "1.*.*.*" {
+				t.Errorf("ip = %q, want masked", c.IPv4)
+			}
+		}},
+		{"策略覆盖", models.Config{ExposurePolicy: `{"price":"viewer","os":"admin"}`}, false, nil, []string{"a", "c"}, func(t *testing.T, c models.Client) {
+			if c.Price != 0 || c.Currency != "" || c.OS != "" {
+				t.Errorf("guest should not see price or os: %+v", c)
+			}
+		}},
+		{"受保护面板的查看者", models.Config{ExposurePolicy: `{"price":"viewer"}`}, false,
+			&models.Dashboard{Access: models.DashboardAccessToken, Groups: models.StringArray{"jp"}}, []string{"a"},
+			func(t *testing.T, c models.Client) {
+				if c.Price != 5 {
+					t.Errorf("viewer should see price: %+v", c)
+				}
+			}},
+		{"管理员通过面板访问", models.Config{SendIpAddrToGuest: true}, true,
+			&models.Dashboard{Access: models.DashboardAccessPublic, HiddenFields: models.StringArray{"ip", "price"}}, []string{"a", "c"},
+			func(t *testing.T, c models.Client) {
+				if c.Token != "" || c.IPv4 != "" || c.Price != 0 {
+					t.Errorf("dashboard hidden fields should be stripped: %+v", c)
+				}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewView(tt.cfg, tt.isAdmin, tt.dashboard).Clients(all)
+			if len(got) != len(tt.wantUUIDs) {
+				t.Fatalf("got %d clients, want %v", len(got), tt.wantUUIDs)
+			}
+			for i, c := range got {
+				if c.UUID != tt.wantUUIDs[i] {
+					t.Errorf("client %d = %s, want %s", i, c.UUID, tt.wantUUIDs[i])
+				}
+				if c.UUID == "a" {
+					tt.check(t, c)
+				}
+			}
+		})
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	if _, err := ParseOverrides(`{"price":"viewer"}`); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ParseOverrides(`{"password":"guest"}`); err == nil {
+		t.Error("unknown field should be rejected")
+	}
+	if _, err := ParseOverrides(`{"price":"everyone"}`); err == nil {
+		t.Error("unknown audience should be rejected")
+	}
+}
+
+func TestViewReportCustom(t *testing.T) {
+	r := &common.Report{Custom: map[string]common.CustomMetric{
+		"queue": {Type: common.CustomGauge, Value: 3, Public: true},
+		"smart": {Type: common.CustomGauge, Value: 1},
+	}}
+	guest := NewView(models.Config{}, false, nil).Report(r)
+	if _, ok := guest.Custom["smart"]; ok || len(guest.Custom) != 1 {
+		t.Errorf("guest should only see public metrics: %+v", guest.Custom)
+	}
+	if len(r.Custom) != 2 {
+		t.Error("original report should not be modified")
+	}
+	if admin := NewView(models.Config{}, true, nil).Report(r); len(admin.Custom) != 2 {
+		t.Errorf("admin should see all metrics: %+v", admin.Custom)
+	}
+}
@@ -0,0 +1,210 @@
+// Package filetransfer 通过 Agent 的上报 WebSocket 分块传输文件。
+//
+// 上传（面板 -> 客户端）由 Agent 拉取：服务端下发 file_upload，Agent 按自身已写入的
+// 偏移量发送 file_chunk_request，服务端返回 file_chunk，全部写入后 Agent 上报 file_result。
+// 下载（客户端 -> 面板）由 Agent 推送：服务端下发 file_download（含续传偏移量），
+// Agent 依次发送 file_chunk，最后上报 file_result。每个分块都带有 SHA-256 校验值。
+package filetransfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/komari-monitor/komari/database/filetransfer"
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// Dir 传输文件的暂存目录，每个任务一个子目录
+const Dir = "./data/transfers"
+
+// ChunkSize 单个分块的大小
+const ChunkSize = 256 * 1024
+
+// DefaultMaxSize 客户端未配置大小上限时使用的默认值
+const DefaultMaxSize int64 = 100 << 20
+
+var (
+	windowsPathPattern = regexp.MustCompile(`^[A-Za-z]:/`)
+	ownerPattern       = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)?$`)
+)
+
+// MaxSize 返回客户端允许的单个文件大小上限
+func MaxSize(p models.FileTransferPolicy) int64 {
+	if p.MaxSize > 0 {
+		return p.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+// normalizePath 统一分隔符并清理路径，非绝对路径返回 false
+func normalizePath(p string) (string, bool) {
+	p = strings.ReplaceAll(strings.TrimSpace(p), `\`, "/")
+	if p == "" {
+		return "", false
+	}
+	if windowsPathPattern.MatchString(p) {
+		return strings.ToLower(p[:2]) + path.Clean(p[2:]), true
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", false
+	}
+	return path.Clean(p), true
+}
+
+// PathAllowed 判断路径是否为允许列表中的文件或位于其中的目录下，列表为空时不限制
+func PathAllowed(p string, allowed []string) bool {
+	target, ok := normalizePath(p)
+	if !ok {
+		return false
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+	windows := windowsPathPattern.MatchString(target)
+	for _, a := range allowed {
+		prefix, ok := normalizePath(a)
+		if !ok {
+			continue
+		}
+		t := target
+		if windows {
+			t, prefix = strings.ToLower(t), strings.ToLower(prefix)
+		}
+		if t == prefix || strings.HasPrefix(t, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPath 按客户端的限制检查目标路径
+func CheckPath(p string, policy models.FileTransferPolicy) error {
+	if policy.Disabled {
+		return fmt.Errorf("file transfer is disabled for this client")
+	}
+	if _, ok := normalizePath(p); !ok {
+		return fmt.Errorf("path must be absolute: %s", p)
+	}
+	if !PathAllowed(p, policy.AllowedPaths) {
+		return fmt.Errorf("path is not in the allow-list: %s", p)
+	}
+	return nil
+}
+
+// ValidatePolicy 检查传输限制的配置
+func ValidatePolicy(p models.FileTransferPolicy) error {
+	if p.MaxSize < 0 {
+		return fmt.Errorf("max_size must be non-negative")
+	}
+	for _, a := range p.AllowedPaths {
+		if _, ok := normalizePath(a); !ok {
+			return fmt.Errorf("allowed path must be absolute: %s", a)
+		}
+	}
+	return nil
+}
+
+// ValidateMode 检查八进制权限，例如 644 或 0755，为空表示不修改
+func ValidateMode(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	v, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || v > 0o7777 {
+		return fmt.Errorf("invalid file mode: %s", mode)
+	}
+	return nil
+}
+
+// ValidateOwner 检查 user 或 user:group 格式，为空表示不修改
+func ValidateOwner(owner string) error {
+	if owner != "" && !ownerPattern.MatchString(owner) {
+		return fmt.Errorf("invalid owner: %s", owner)
+	}
+	return nil
+}
+
+func transferDir(id string) string {
+	return filepath.Join(Dir, id)
+}
+
+// SourcePath 上传任务的源文件
+func SourcePath(id string) string {
+	return filepath.Join(transferDir(id), "source")
+}
+
+// partPath 下载中的临时文件
+func partPath(id, client string) string {
+	return filepath.Join(transferDir(id), client+".part")
+}
+
+// DownloadedPath 下载完成后保存的文件
+func DownloadedPath(id, client string) string {
+	return filepath.Join(transferDir(id), client)
+}
+
+// Store 保存上传任务的源文件，超过 maxSize 时返回错误
+func Store(id string, r io.Reader, maxSize int64) (int64, string, error) {
+	if err := os.MkdirAll(transferDir(id), 0700); err != nil {
+		return 0, "", err
+	}
+	f, err := os.OpenFile(SourcePath(id), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return 0, "", err
+	}
+	if n > maxSize {
+		return 0, "", fmt.Errorf("file exceeds the size limit of %d bytes", maxSize)
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Remove 删除任务的暂存文件
+func Remove(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("invalid transfer id: %s", id)
+	}
+	return os.RemoveAll(transferDir(id))
+}
+
+// Cleanup 删除在指定时间前创建的传输任务及其文件
+func Cleanup(before time.Time) error {
+	ids, err := db.ListTransferIdsBefore(before)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		Remove(id)
+		if err := db.DeleteTransfer(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
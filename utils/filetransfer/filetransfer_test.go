@@ -0,0 +1,52 @@
+package filetransfer
+
+import "testing"
+
+func TestPathAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		allowed []string
+		want    bool
+	}{
+		{"未配置时不限制", "/etc/hosts", nil, true},
+		{"相对路径", "etc/hosts", nil, false},
+		{"目录下的文件", "/var/log/syslog", []string{"/var/log"}, true},
+		{"目录末尾带斜杠", "/var/log/nginx/access.log", []string{"/var/log/"}, true},
+		{"前缀相同的其他目录", "/var/logs/x", []string{"/var/log"}, false},
+		{"路径穿越", "/var/log/../../etc/shadow", []string{"/var/log"}, false},
+		{"允许单个文件", "/etc/komari.conf", []string{"/etc/komari.conf"}, true},
+		{"Windows 路径忽略大小写", `C:\Komari\config.json`, []string{"c:/komari"}, true},
+		{"Windows 盘符不同", `D:\komari\config.json`, []string{"C:/komari"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PathAllowed(tt.path, tt.allowed); got != tt.want {
+				t.Errorf("PathAllowed(%q, %v) = %v, want %v", tt.path, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateModeAndOwner(t *testing.T) {
+	for _, mode := range []string{"", "644", "0755", "4755"} {
+		if err := ValidateMode(mode); err != nil {
+			t.Errorf("ValidateMode(%q) = %v", mode, err)
+		}
+	}
+	for _, mode := range []string{"rw-r--r--", "0888", "17777"} {
+		if ValidateMode(mode) == nil {
+			t.Errorf("ValidateMode(%q) should fail", mode)
+		}
+	}
+	for _, owner := range []string{"", "root", "www-data:www-data"} {
+		if err := ValidateOwner(owner); err != nil {
+			t.Errorf("ValidateOwner(%q) = %v", owner, err)
+		}
+	}
+	for _, owner := range []string{"root;rm", "a:b:c", ":root"} {
+		if ValidateOwner(owner) == nil {
+			t.Errorf("ValidateOwner(%q) should fail", owner)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package filetransfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	db "github.com/komari-monitor/komari/database/filetransfer"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/ws"
+)
+
+// uploadMessage 通知 Agent 开始接收文件
+type uploadMessage struct {
+	Message      string   `json:"message"` // file_upload
+	TransferId   string   `json:"transfer_id"`
+	Path         string   `json:"path"`
+	Size         int64    `json:"size"`
+	SHA256       string   `json:"sha256"`
+	Mode         string   `json:"mode,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	ChunkSize    int      `json:"chunk_size"`
+	AllowedPaths []string `json:"allowed_paths"`
+}
+
+// downloadMessage 通知 Agent 从 offset 开始发送文件
+type downloadMessage struct {
+	Message      string   `json:"message"` // file_download
+	TransferId   string   `json:"transfer_id"`
+	Path         string   `json:"path"`
+	Offset       int64    `json:"offset"`
+	ChunkSize    int      `json:"chunk_size"`
+	MaxSize      int64    `json:"max_size"`
+	AllowedPaths []string `json:"allowed_paths"`
+}
+
+// chunkMessage 服务端发送的文件分块，Data 以 base64 编码
+type chunkMessage struct {
+	Message    string `json:"message"` // file_chunk
+	TransferId string `json:"transfer_id"`
+	Offset     int64  `json:"offset"`
+	Data       []byte `json:"data"`
+	SHA256     string `json:"sha256"`
+	EOF        bool   `json:"eof"`
+}
+
+// controlMessage 要求 Agent 从指定偏移量重发（file_resume）或终止传输（file_cancel）
+type controlMessage struct {
+	Message    string `json:"message"`
+	TransferId string `json:"transfer_id"`
+	Offset     int64  `json:"offset,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AgentMessage Agent 上报的传输消息
+type AgentMessage struct {
+	Type       string `json:"type"` // file_chunk_request | file_chunk | file_result
+	TransferId string `json:"transfer_id"`
+	Offset     int64  `json:"offset"`
+	Data       []byte `json:"data"`
+	SHA256     string `json:"sha256"` // 分块或整个文件的校验值
+	Size       int64  `json:"size"`   // 文件总大小
+	Success    bool   `json:"success"`
+	Error      string `json:"error"`
+}
+
+// Start 向在线客户端下发传输任务，下载任务会从已接收的位置续传
+func Start(conn *ws.SafeConn, t *models.FileTransfer, client string) error {
+	policy, err := db.GetPolicy(client)
+	if err != nil {
+		return err
+	}
+	if err := CheckPath(t.Path, policy); err != nil {
+		return err
+	}
+	if t.Direction == models.TransferUpload {
+		if t.Size > MaxSize(policy) {
+			return fmt.Errorf("file exceeds the size limit of %d bytes", MaxSize(policy))
+		}
+		return conn.WriteJSON(uploadMessage{
+			Message:      "file_upload",
+			TransferId:   t.TransferId,
+			Path:         t.Path,
+			Size:         t.Size,
+			SHA256:       t.SHA256,
+			Mode:         t.Mode,
+			Owner:        t.Owner,
+			ChunkSize:    ChunkSize,
+			AllowedPaths: policy.AllowedPaths,
+		})
+	}
+	if err := os.MkdirAll(transferDir(t.TransferId), 0700); err != nil {
+		return err
+	}
+	var offset int64
+	if info, err := os.Stat(partPath(t.TransferId, client)); err == nil {
+		offset = info.Size()
+	}
+	return conn.WriteJSON(downloadMessage{
+		Message:      "file_download",
+		TransferId:   t.TransferId,
+		Path:         t.Path,
+		Offset:       offset,
+		ChunkSize:    ChunkSize,
+		MaxSize:      MaxSize(policy),
+		AllowedPaths: policy.AllowedPaths,
+	})
+}
+
+// HandleMessage 处理 Agent 上报的传输消息
+func HandleMessage(conn *ws.SafeConn, client string, message []byte) {
+	var msg AgentMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		conn.WriteJSON(map[string]string{"status": "error", "error": "Invalid file transfer message"})
+		return
+	}
+	t, err := db.GetTransferInfo(msg.TransferId)
+	if err != nil {
+		cancel(conn, msg.TransferId, "transfer not found")
+		return
+	}
+	result, err := db.GetResult(t.TransferId, client)
+	if err != nil {
+		cancel(conn, t.TransferId, "transfer not found")
+		return
+	}
+	if result.Status == models.TransferSuccess || result.Status == models.TransferFailed {
+		cancel(conn, t.TransferId, "transfer already finished")
+		return
+	}
+	switch {
+	case msg.Type == "file_chunk_request" && t.Direction == models.TransferUpload:
+		err = sendChunk(conn, t, client, msg.Offset)
+	case msg.Type == "file_chunk" && t.Direction == models.TransferDownload:
+		err = receiveChunk(conn, t, client, msg)
+	case msg.Type == "file_result" && t.Direction == models.TransferUpload:
+		err = finishUpload(t, client, msg)
+	case msg.Type == "file_result" && t.Direction == models.TransferDownload:
+		err = finishDownload(t, client, msg)
+	default:
+		err = fmt.Errorf("unexpected %s for %s transfer", msg.Type, t.Direction)
+	}
+	if err != nil {
+		log.Printf("File transfer %s of client %s failed: %v", t.TransferId, client, err)
+		db.Finish(t.TransferId, client, result.Transferred, "", err.Error())
+		cancel(conn, t.TransferId, err.Error())
+	}
+}
+
+func cancel(conn *ws.SafeConn, id, reason string) {
+	conn.WriteJSON(controlMessage{Message: "file_cancel", TransferId: id, Error: reason})
+}
+
+func sendChunk(conn *ws.SafeConn, t *models.FileTransfer, client string, offset int64) error {
+	if offset < 0 || offset > t.Size {
+		return fmt.Errorf("invalid offset %d", offset)
+	}
+	f, err := os.Open(SourcePath(t.TransferId))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, ChunkSize)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	sum := sha256.Sum256(buf[:n])
+	if err := conn.WriteJSON(chunkMessage{
+		Message:    "file_chunk",
+		TransferId: t.TransferId,
+		Offset:     offset,
+		Data:       buf[:n],
+		SHA256:     hex.EncodeToString(sum[:]),
+		EOF:        offset+int64(n) >= t.Size,
+	}); err != nil {
+		return err
+	}
+	return db.UpdateProgress(t.TransferId, client, offset+int64(n), 0)
+}
+
+func receiveChunk(conn *ws.SafeConn, t *models.FileTransfer, client string, msg AgentMessage) error {
+	policy, err := db.GetPolicy(client)
+	if err != nil {
+		return err
+	}
+	maxSize := MaxSize(policy)
+	if msg.Size > maxSize {
+		return fmt.Errorf("file exceeds the size limit of %d bytes", maxSize)
+	}
+	sum := sha256.Sum256(msg.Data)
+	if hex.EncodeToString(sum[:]) != msg.SHA256 {
+		// 分块损坏，要求 Agent 从该位置重发
+		return conn.WriteJSON(controlMessage{Message: "file_resume", TransferId: t.TransferId, Offset: msg.Offset})
+	}
+	name := partPath(t.TransferId, client)
+	var current int64
+	if info, err := os.Stat(name); err == nil {
+		current = info.Size()
+	}
+	if msg.Offset != current {
+		return conn.WriteJSON(controlMessage{Message: "file_resume", TransferId: t.TransferId, Offset: current})
+	}
+	if current+int64(len(msg.Data)) > maxSize {
+		return fmt.Errorf("file exceeds the size limit of %d bytes", maxSize)
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(msg.Data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return db.UpdateProgress(t.TransferId, client, current+int64(len(msg.Data)), msg.Size)
+}
+
+func finishUpload(t *models.FileTransfer, client string, msg AgentMessage) error {
+	if !msg.Success {
+		return fmt.Errorf("agent: %s", msg.Error)
+	}
+	if msg.SHA256 != t.SHA256 {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", t.SHA256, msg.SHA256)
+	}
+	return db.Finish(t.TransferId, client, t.Size, msg.SHA256, "")
+}
+
+func finishDownload(t *models.FileTransfer, client string, msg AgentMessage) error {
+	if !msg.Success {
+		// 保留已接收的部分，便于续传
+		return fmt.Errorf("agent: %s", msg.Error)
+	}
+	name := partPath(t.TransferId, client)
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if msg.Size != info.Size() {
+		return fmt.Errorf("size mismatch: expected %d, received %d", msg.Size, info.Size())
+	}
+	sum, err := fileSHA256(name)
+	if err != nil {
+		return err
+	}
+	if sum != msg.SHA256 {
+		os.Remove(name)
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", msg.SHA256, sum)
+	}
+	if err := os.Rename(name, DownloadedPath(t.TransferId, client)); err != nil {
+		return err
+	}
+	return db.Finish(t.TransferId, client, info.Size(), sum, "")
+}
@@ -3,6 +3,7 @@ package geoip
 import (
 	"log"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -15,8 +16,13 @@ var CurrentProvider GeoIPService
 var geoCache *cache.Cache
 
 type GeoInfo struct {
-	ISOCode string
-	Name    string
+	ISOCode      string
+	Name         string
+	City         string
+	Latitude     float64
+	Longitude    float64
+	ASN          uint
+	Organization string // ASN 所属组织，通常即服务商
 }
 
 func init() {
@@ -59,9 +65,15 @@ func InitGeoIp() {
 	if !conf.GeoIpEnabled {
 		return
 	}
+	// 数据源可能已变化，旧的缓存结果不再可靠
+	geoCache.Flush()
 	switch conf.GeoIpProvider {
 	case "mmdb":
-		NewCurrentProvider, err := NewMaxMindGeoIPService()
+		NewCurrentProvider, err := NewMaxMindGeoIPServiceWithSources(MmdbSources{
+			Country: conf.GeoIpCountryDb,
+			City:    conf.GeoIpCityDb,
+			ASN:     conf.GeoIpAsnDb,
+		})
 		if err != nil {
 			log.Printf("Failed to initialize MaxMind GeoIP service: " + err.Error())
 		}
@@ -137,3 +149,55 @@ func UpdateDatabase() error {
 	}
 	return err
 }
+
+// ClientGeoUpdates 解析客户端 IP 的地理信息并返回可直接写入 models.Client 的字段，
+// 优先使用 IPv4，均无结果时返回 nil。
+func ClientGeoUpdates(ipv4, ipv6 string) map[string]interface{} {
+	return GeoUpdates(LookupClientGeo(ipv4, ipv6))
+}
+
+// LookupClientGeo 查询客户端 IP 的地理信息，优先使用 IPv4，均无结果时返回 nil
+func LookupClientGeo(ipv4, ipv6 string) *GeoInfo {
+	for _, addr := range []string{ipv4, ipv6} {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			continue
+		}
+		if info, _ := GetGeoInfo(ip); info != nil {
+			return info
+		}
+	}
+	return nil
+}
+
+// GeoUpdates 将地理信息转换为可直接写入 models.Client 的字段，info 为 nil 时返回 nil
+func GeoUpdates(info *GeoInfo) map[string]interface{} {
+	if info == nil {
+		return nil
+	}
+	updates := map[string]interface{}{
+		"city":         info.City,
+		"latitude":     info.Latitude,
+		"longitude":    info.Longitude,
+		"asn":          info.ASN,
+		"organization": info.Organization,
+	}
+	if region := GetRegionUnicodeEmoji(info.ISOCode); region != "" {
+		updates["region"] = region
+	}
+	return updates
+}
+
+// parseASN 解析 "AS15169 Google LLC" 形式的字符串
+func parseASN(s string) (uint, string) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(strings.ToUpper(s), "AS") {
+		return 0, s
+	}
+	numPart, org, _ := strings.Cut(s[2:], " ")
+	n, err := strconv.ParseUint(numPart, 10, 32)
+	if err != nil {
+		return 0, s
+	}
+	return uint(n), strings.TrimSpace(org)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -16,11 +17,13 @@ type GeoJSService struct {
 // geoJSResponse 定义了 geojs.io 服务返回的 JSON 响应的结构。
 // 我们只定义我们需要的字段。
 type geoJSResponse struct {
-	Country     string `json:"country"`
-	CountryCode string `json:"country_code"`
-	// 可以根据需要添加其他字段，例如:
-	// City    string `json:"city"`
-	// Region  string `json:"region"`
+	Country          string `json:"country"`
+	CountryCode      string `json:"country_code"`
+	City             string `json:"city"`
+	Latitude         string `json:"latitude"` // geojs 以字符串返回坐标
+	Longitude        string `json:"longitude"`
+	ASN              uint   `json:"asn"`
+	OrganizationName string `json:"organization_name"`
 }
 
 // NewGeoJSService 创建并返回一个 GeoJSService 的新实例。
@@ -63,9 +66,16 @@ func (s *GeoJSService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 		return nil, fmt.Errorf("geojs.io returned empty geo info for ip: %s", ip.String())
 	}
 
+	lat, _ := strconv.ParseFloat(apiResp.Latitude, 64)
+	lon, _ := strconv.ParseFloat(apiResp.Longitude, 64)
 	return &GeoInfo{
-		ISOCode: apiResp.CountryCode,
-		Name:    apiResp.Country,
+		ISOCode:      apiResp.CountryCode,
+		Name:         apiResp.Country,
+		City:         apiResp.City,
+		Latitude:     lat,
+		Longitude:    lon,
+		ASN:          apiResp.ASN,
+		Organization: apiResp.OrganizationName,
 	}, nil
 }
 
@@ -48,7 +48,7 @@ func NewIPAPIService() (*IPAPIService, error) {
 // GetGeoInfo 使用 ip-api.com 服务检索给定 IP 地址的地理位置信息。
 func (s *IPAPIService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 	// API URL, 使用 fields 参数来仅请求需要的字段
-	apiURL := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,message,country,countryCode", ip.String())
+	apiURL := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,message,country,countryCode,city,lat,lon,org,as", ip.String())
 
 	resp, err := s.Client.Get(apiURL)
 	if err != nil {
@@ -65,9 +65,18 @@ func (s *IPAPIService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 		return nil, fmt.Errorf("ip-api.com returned an error: %s", apiResp.Message)
 	}
 
+	asn, org := parseASN(apiResp.As)
+	if apiResp.Org != "" {
+		org = apiResp.Org
+	}
 	return &GeoInfo{
-		ISOCode: apiResp.CountryCode,
-		Name:    apiResp.Country,
+		ISOCode:      apiResp.CountryCode,
+		Name:         apiResp.Country,
+		City:         apiResp.City,
+		Latitude:     apiResp.Lat,
+		Longitude:    apiResp.Lon,
+		ASN:          asn,
+		Organization: org,
 	}, nil
 }
 
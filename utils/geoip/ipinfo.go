@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -72,10 +74,18 @@ func (s *IPInfoService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 	// 实际上，IPinfo 的 'country' 字段就是 ISO 2-letter code。
 	// 如果需要完整的国家名称，可能需要一个本地的 ISO 代码到名称的映射。
 	// 为了与 GetRegionUnicodeEmoji 函数兼容，我们直接使用 country 作为 ISOCode。
-	return &GeoInfo{
+	info := &GeoInfo{
 		ISOCode: apiResp.Country, // IPinfo 的 'country' 字段就是 ISO 2-letter code
 		Name:    apiResp.Country, // 免费额度通常只提供 ISO 编码，这里暂时用 ISO 编码作为名称
-	}, nil
+		City:    apiResp.City,
+	}
+	// loc 格式为 "纬度,经度"，org 格式为 "AS15169 Google LLC"
+	if lat, lon, ok := strings.Cut(apiResp.Loc, ","); ok {
+		info.Latitude, _ = strconv.ParseFloat(lat, 64)
+		info.Longitude, _ = strconv.ParseFloat(lon, 64)
+	}
+	info.ASN, info.Organization = parseASN(apiResp.Org)
+	return info, nil
 }
 
 // UpdateDatabase 对于 ipinfo.io 是一个空操作，因为它是一个 Web 服务。
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath" // 新增导入，用于处理文件路径
+	"strings"
 	"sync"
 
 	"github.com/komari-monitor/komari/database/auditlog"
@@ -20,23 +21,135 @@ var GeoIpUrl = "https://raw.githubusercontent.com/Loyalsoldier/geoip/release/Geo
 // GeoIpFilePath 是本地存储 MaxMind 数据库的路径。
 var GeoIpFilePath = "./data/GeoLite2-Country.mmdb"
 
+// GeoIpCityFilePath 与 GeoIpAsnFilePath 是通过 URL 下载的 City / ASN 数据库的本地存储路径。
+var (
+	GeoIpCityFilePath = "./data/GeoLite2-City.mmdb"
+	GeoIpAsnFilePath  = "./data/GeoLite2-ASN.mmdb"
+)
+
+// MmdbSources 指定各数据库的来源，可以是本地文件路径或 http(s) 下载地址。
+// Country 留空时使用默认下载地址，City 与 ASN 留空表示不启用。
+type MmdbSources struct {
+	Country string
+	City    string
+	ASN     string
+}
+
 // GeoIpRecord 结构体定义了 MaxMind 数据库查询结果的原始结构。
 // 它是 MaxMind 库特有的，用于从 .mmdb 文件中解析数据。
+// Country 与 City 数据库共用该结构，Country 数据库中 City 与 Location 为空。
 type GeoIpRecord struct {
 	Country struct {
 		ISOCode string            `maxminddb:"iso_code"`
 		Names   map[string]string `maxminddb:"names"`
 	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// AsnRecord 是 ASN 数据库的查询结果。
+type AsnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// mmdbDatabase 表示一个 mmdb 文件及其来源。
+type mmdbDatabase struct {
+	source string // 本地路径或下载地址
+	path   string // 实际读取的本地文件
+	reader *maxminddb.Reader
+}
+
+func newMmdbDatabase(source, downloadPath string) *mmdbDatabase {
+	if isRemoteSource(source) {
+		return &mmdbDatabase{source: source, path: downloadPath}
+	}
+	return &mmdbDatabase{source: source, path: source}
+}
+
+func isRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// download 下载到临时文件后再替换，避免下载中断时破坏正在使用的数据库。
+func (d *mmdbDatabase) download() error {
+	resp, err := http.Get(d.source)
+	if err != nil {
+		return fmt.Errorf("failed to initiate MaxMind database download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download MaxMind database: HTTP status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create data directory for MaxMind database: %w", err)
+	}
+
+	tmp := d.path + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create MaxMind database file at %s: %w", tmp, err)
+	}
+	if _, err = io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write MaxMind database file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write MaxMind database file: %w", err)
+	}
+	// 确认下载的文件可以正常打开
+	if r, err := maxminddb.Open(tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("downloaded file is not a valid MaxMind database: %w", err)
+	} else {
+		r.Close()
+	}
+	return os.Rename(tmp, d.path)
+}
+
+// open 打开数据库文件，远程来源且本地不存在时先下载。
+func (d *mmdbDatabase) open() error {
+	if isRemoteSource(d.source) {
+		if _, err := os.Stat(d.path); os.IsNotExist(err) {
+			if err := d.download(); err != nil {
+				return err
+			}
+		}
+	}
+	reader, err := maxminddb.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("error opening MaxMind database at %s: %w", d.path, err)
+	}
+	d.close()
+	d.reader = reader
+	return nil
+}
+
+func (d *mmdbDatabase) close() error {
+	if d.reader == nil {
+		return nil
+	}
+	err := d.reader.Close()
+	d.reader = nil
+	return err
 }
 
 // MaxMindGeoIPService 是 GeoIPService 接口的一个具体实现，
-// 它使用 MaxMind 数据库作为后端。
+// 它使用 MaxMind 数据库作为后端，可同时加载 Country、City 与 ASN 数据库。
 type MaxMindGeoIPService struct {
-	// maxMindDBReader 内部持有的 MaxMind 数据库读取器实例。
-	maxMindDBReader *maxminddb.Reader
-	// dbFilePath 是 MaxMind 数据库文件的路径。
-	dbFilePath string
-	// mu 用于保护对 maxMindDBReader 的并发访问，确保线程安全。
+	country *mmdbDatabase
+	city    *mmdbDatabase // 未配置时为 nil
+	asn     *mmdbDatabase // 未配置时为 nil
+	// mu 用于保护对数据库读取器的并发访问，确保线程安全。
 	mu sync.RWMutex
 }
 
@@ -45,26 +158,27 @@ func (s *MaxMindGeoIPService) Name() string {
 	return "MaxMind"
 }
 
-// NewMaxMindGeoIPService 创建并返回一个 MaxMindGeoIPService 实例。
-// 它负责初始化服务，包括尝试加载或下载数据库。
+// NewMaxMindGeoIPService 使用默认的 Country 数据库创建 MaxMindGeoIPService 实例。
 func NewMaxMindGeoIPService() (*MaxMindGeoIPService, error) {
-	dbFilePath := GeoIpFilePath
-	service := &MaxMindGeoIPService{
-		dbFilePath: dbFilePath,
-	}
+	return NewMaxMindGeoIPServiceWithSources(MmdbSources{})
+}
 
-	// 确保数据目录存在
-	if err := os.MkdirAll(filepath.Dir(dbFilePath), os.ModePerm); err != nil {
-		auditlog.Log("", "", "Failed to create data directory for MaxMind database: "+err.Error(), "error")
-		return nil, fmt.Errorf("failed to create data directory for MaxMind database: %w", err)
+// NewMaxMindGeoIPServiceWithSources 按指定来源创建 MaxMindGeoIPService 实例。
+// 它负责初始化服务，包括尝试加载或下载数据库。
+func NewMaxMindGeoIPServiceWithSources(sources MmdbSources) (*MaxMindGeoIPService, error) {
+	service := &MaxMindGeoIPService{}
+	// City 数据库已包含国家信息，仅配置 City 时不再下载默认的 Country 数据库
+	switch {
+	case sources.Country != "":
+		service.country = newMmdbDatabase(sources.Country, GeoIpFilePath)
+	case sources.City == "":
+		service.country = newMmdbDatabase(GeoIpUrl, GeoIpFilePath)
 	}
-
-	// 检查数据库文件是否存在，如果不存在则尝试下载
-	if _, err := os.Stat(dbFilePath); os.IsNotExist(err) {
-		if err := service.UpdateDatabase(); err != nil {
-			auditlog.Log("", "", "Failed to download initial MaxMind database: "+err.Error(), "error")
-			return nil, fmt.Errorf("failed to download initial MaxMind database: %w", err)
-		}
+	if sources.City != "" {
+		service.city = newMmdbDatabase(sources.City, GeoIpCityFilePath)
+	}
+	if sources.ASN != "" {
+		service.asn = newMmdbDatabase(sources.ASN, GeoIpAsnFilePath)
 	}
 
 	// 初始化或重新加载 MaxMind 数据库。
@@ -75,43 +189,58 @@ func NewMaxMindGeoIPService() (*MaxMindGeoIPService, error) {
 	return service, nil
 }
 
-// initialize 初始化或重新加载 MaxMind 数据库。
-// 这是一个内部方法，供 NewMaxMindGeoIPService 和 UpdateDatabase 调用。
-// 它会关闭现有连接（如果存在）并重新打开数据库文件。
+// initialize 打开全部已配置的数据库。
+// Country 与 City 至少需要一个可用；ASN 数据库打开失败只记录日志，不影响国家信息的查询。
 func (s *MaxMindGeoIPService) initialize() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// 如果已存在数据库读取器，则先关闭它。
-	if s.maxMindDBReader != nil {
-		s.maxMindDBReader.Close()
-		s.maxMindDBReader = nil
+	var lastErr error
+	opened := false
+	for _, db := range []*mmdbDatabase{s.city, s.country} {
+		if db == nil {
+			continue
+		}
+		if err := db.open(); err != nil {
+			log.Printf("Failed to open MaxMind database %s: %v", db.path, err)
+			lastErr = err
+			continue
+		}
+		opened = true
 	}
-
-	// 尝试打开新的数据库文件。
-	reader, err := maxminddb.Open(s.dbFilePath)
-	if err != nil {
-		return fmt.Errorf("error opening MaxMind database at %s: %w", s.dbFilePath, err)
+	if !opened {
+		return lastErr
+	}
+	if s.asn != nil {
+		if err := s.asn.open(); err != nil {
+			log.Printf("Failed to open MaxMind ASN database: %v", err)
+		}
 	}
-	s.maxMindDBReader = reader
 	return nil
 }
 
 // GetGeoInfo 根据 IP 地址获取 MaxMind 的地理位置信息。
-// 它查询 MaxMind 数据库并将其特有的 GeoIpRecord 转换为通用的 GeoInfo 结构体。
+// 它查询 MaxMind 数据库并将其特有的记录转换为通用的 GeoInfo 结构体。
 func (s *MaxMindGeoIPService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.maxMindDBReader == nil {
-		return nil, fmt.Errorf("MaxMind database is not initialized or failed to open")
-	}
 	if ip == nil {
 		return nil, fmt.Errorf("IP address cannot be nil")
 	}
+	// City 数据库同时包含国家信息，可用时优先使用
+	var reader *maxminddb.Reader
+	if s.city != nil && s.city.reader != nil {
+		reader = s.city.reader
+	} else if s.country != nil {
+		reader = s.country.reader
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("MaxMind database is not initialized or failed to open")
+	}
 
 	var record GeoIpRecord // 使用原始的 GeoIpRecord 结构体来接收查询结果
-	err := s.maxMindDBReader.Lookup(ip, &record)
+	err := reader.Lookup(ip, &record)
 	if err != nil {
 		// 返回错误，但避免直接返回 maxminddb 库的内部错误，提供更友好的信息
 		return nil, fmt.Errorf("error looking up IP %s in MaxMind database: %w", ip.String(), err)
@@ -121,45 +250,36 @@ func (s *MaxMindGeoIPService) GetGeoInfo(ip net.IP) (*GeoInfo, error) {
 	geoInfo := &GeoInfo{
 		ISOCode: record.Country.ISOCode,
 		// 尝试获取英文国家名称，如果不存在则使用 ISO 代码作为备用
-		Name: record.Country.Names["en"],
+		Name:      record.Country.Names["en"],
+		City:      record.City.Names["en"],
+		Latitude:  record.Location.Latitude,
+		Longitude: record.Location.Longitude,
 	}
 	if geoInfo.Name == "" && geoInfo.ISOCode != "" {
 		geoInfo.Name = geoInfo.ISOCode // 如果没有英文名称，回退到 ISO 代码
 	}
+
+	if s.asn != nil && s.asn.reader != nil {
+		var asn AsnRecord
+		if err := s.asn.reader.Lookup(ip, &asn); err == nil {
+			geoInfo.ASN = asn.Number
+			geoInfo.Organization = asn.Organization
+		}
+	}
 	return geoInfo, nil
 }
 
 // UpdateDatabase 实现了 GeoIPService 接口的 UpdateDatabase 方法。
-// 它会下载最新的 GeoLite2-Country.mmdb 文件并重新加载数据库。
+// 远程来源的数据库会重新下载，本地路径的数据库只重新加载。
 func (s *MaxMindGeoIPService) UpdateDatabase() error {
-	s.mu.Lock() // 获取写锁，确保更新过程的互斥性
-
-	resp, err := http.Get(GeoIpUrl) // GeoIpUrl 是预定义的 MaxMind 数据库下载地址
-	if err != nil {
-		return fmt.Errorf("failed to initiate MaxMind database download: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download MaxMind database: HTTP status %s", resp.Status)
-	}
-
-	// 确保数据目录存在（NewMaxMindGeoIPService 已处理，但这里再次确保以防直接调用）
-	if err := os.MkdirAll(filepath.Dir(s.dbFilePath), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create data directory for MaxMind database update: %w", err)
-	}
-
-	out, err := os.Create(s.dbFilePath) // 创建或覆盖本地数据库文件
-	if err != nil {
-		return fmt.Errorf("failed to create MaxMind database file at %s: %w", s.dbFilePath, err)
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body) // 将下载内容写入文件
-	if err != nil {
-		return fmt.Errorf("failed to write MaxMind database file: %w", err)
+	for _, db := range []*mmdbDatabase{s.country, s.city, s.asn} {
+		if db == nil || !isRemoteSource(db.source) {
+			continue
+		}
+		if err := db.download(); err != nil {
+			return err
+		}
 	}
-	s.mu.Unlock() // initialize 方法需要在解锁后调用，以避免死锁
 	// 重新加载数据库以使用新下载的文件
 	return s.initialize()
 }
@@ -169,10 +289,11 @@ func (s *MaxMindGeoIPService) UpdateDatabase() error {
 func (s *MaxMindGeoIPService) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.maxMindDBReader != nil {
-		err := s.maxMindDBReader.Close()
-		s.maxMindDBReader = nil // 清空读取器实例
-		if err != nil {
+	for _, db := range []*mmdbDatabase{s.country, s.city, s.asn} {
+		if db == nil {
+			continue
+		}
+		if err := db.close(); err != nil {
 			return fmt.Errorf("error closing MaxMind database: %w", err)
 		}
 	}
@@ -0,0 +1,165 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// encodeMmdb 按 MaxMind DB 数据段格式编码，只支持测试需要的类型
+func encodeMmdb(buf *bytes.Buffer, v any) {
+	ctrl := func(typ, size int) {
+		sizeBits, extra := size, -1
+		if size >= 29 {
+			sizeBits, extra = 29, size-29
+		}
+		if typ <= 7 {
+			buf.WriteByte(byte(typ<<5 | sizeBits))
+		} else {
+			buf.WriteByte(byte(sizeBits))
+			buf.WriteByte(byte(typ - 7))
+		}
+		if extra >= 0 {
+			buf.WriteByte(byte(extra))
+		}
+	}
+	switch val := v.(type) {
+	case string:
+		ctrl(2, len(val))
+		buf.WriteString(val)
+	case float64:
+		ctrl(3, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(val))
+	case uint16:
+		ctrl(5, 2)
+		binary.Write(buf, binary.BigEndian, val)
+	case uint32:
+		ctrl(6, 4)
+		binary.Write(buf, binary.BigEndian, val)
+	case uint64:
+		ctrl(9, 8)
+		binary.Write(buf, binary.BigEndian, val)
+	case []any:
+		ctrl(11, len(val))
+		for _, item := range val {
+			encodeMmdb(buf, item)
+		}
+	case map[string]any:
+		ctrl(7, len(val))
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			encodeMmdb(buf, k)
+			encodeMmdb(buf, val[k])
+		}
+	}
+}
+
+// writeTestMmdb 生成只包含 1.0.0.0/8 一条记录的 IPv4 数据库
+func writeTestMmdb(t *testing.T, path, dbType string, record map[string]any) {
+	t.Helper()
+	const nodeCount = 8
+	var tree bytes.Buffer
+	put24 := func(v uint32) { tree.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)}) }
+	// 1.0.0.0/8 的前缀为 00000001
+	for i := 0; i < nodeCount; i++ {
+		if i < nodeCount-1 {
+			put24(uint32(i + 1))
+			put24(nodeCount)
+		} else {
+			put24(nodeCount)
+			put24(nodeCount + 16) // 指向数据段偏移 0
+		}
+	}
+	var out bytes.Buffer
+	out.Write(tree.Bytes())
+	out.Write(make([]byte, 16))
+	encodeMmdb(&out, record)
+	out.WriteString("\xAB\xCD\xEFMaxMind.com")
+	encodeMmdb(&out, map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               dbType,
+		"languages":                   []any{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(0),
+		"description":                 map[string]any{"en": "test"},
+	})
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxMindCityAndASN(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	writeTestMmdb(t, cityPath, "GeoLite2-City", map[string]any{
+		"country":  map[string]any{"iso_code": "AU", "names": map[string]any{"en": "Australia"}},
+		"city":     map[string]any{"names": map[string]any{"en": "Sydney"}},
+		"location": map[string]any{"latitude": -33.8688, "longitude": 151.2093},
+	})
+	writeTestMmdb(t, asnPath, "GeoLite2-ASN", map[string]any{
+		"autonomous_system_number":       uint32(13335),
+		"autonomous_system_organization": "CLOUDFLARENET",
+	})
+
+	// 仅配置本地 City / ASN 时不应尝试下载默认的 Country 数据库
+	s, err := NewMaxMindGeoIPServiceWithSources(MmdbSources{City: cityPath, ASN: asnPath})
+	if err != nil {
+		t.Fatalf("NewMaxMindGeoIPServiceWithSources() error = %v", err)
+	}
+	defer s.Close()
+	if s.country != nil {
+		t.Error("country database should not be loaded when only city is configured")
+	}
+
+	info, err := s.GetGeoInfo(net.ParseIP("1.1.1.1"))
+	if err != nil {
+		t.Fatalf("GetGeoInfo() error = %v", err)
+	}
+	want := GeoInfo{ISOCode: "AU", Name: "Australia", City: "Sydney", Latitude: -33.8688, Longitude: 151.2093, ASN: 13335, Organization: "CLOUDFLARENET"}
+	if *info != want {
+		t.Errorf("GetGeoInfo() = %+v, want %+v", *info, want)
+	}
+
+	// 不在库中的地址返回空记录
+	info, err = s.GetGeoInfo(net.ParseIP("8.8.8.8"))
+	if err != nil || info.ISOCode != "" || info.ASN != 0 {
+		t.Errorf("unexpected result for unknown address: %+v, %v", info, err)
+	}
+
+	// 本地来源更新时只重新加载
+	if err := s.UpdateDatabase(); err != nil {
+		t.Errorf("UpdateDatabase() error = %v", err)
+	}
+}
+
+func TestParseASN(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantASN uint
+		wantOrg string
+	}{
+		{"AS15169 Google LLC", 15169, "Google LLC"},
+		{"as13335", 13335, ""},
+		{"Some ISP", 0, "Some ISP"},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		asn, org := parseASN(tt.in)
+		if asn != tt.wantASN || org != tt.wantOrg {
+			t.Errorf("parseASN(%q) = %d, %q; want %d, %q", tt.in, asn, org, tt.wantASN, tt.wantOrg)
+		}
+	}
+}
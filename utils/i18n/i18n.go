@@ -0,0 +1,163 @@
+// Package i18n 提供服务端消息的多语言目录。
+// 目录位于 locales/<lang>.json，键为点分的消息 ID，消息中以 {0}、{1} 引用参数。
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default 默认语言，其他语言缺失的消息回退到该语言
+const Default = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var catalogs = map[string]map[string]string{}
+
+func init() {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+}
+
+// Languages 返回支持的语言
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supported 判断是否支持该语言
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Lookup 查找消息，当前语言缺失时回退到默认语言
+func Lookup(lang, key string) (string, bool) {
+	if msg, ok := catalogs[Normalize(lang)][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+// T 翻译消息并填入参数，key 不在目录中时原样返回，因此也可以直接传入普通文本
+func T(lang, key string, args ...any) string {
+	msg, ok := Lookup(lang, key)
+	if !ok {
+		msg = key
+	}
+	for i, arg := range args {
+		msg = strings.ReplaceAll(msg, "{"+strconv.Itoa(i)+"}", argString(lang, arg))
+	}
+	return msg
+}
+
+func argString(lang string, arg any) string {
+	switch v := arg.(type) {
+	case error:
+		var e *Error
+		if errors.As(v, &e) {
+			return e.Localize(lang)
+		}
+		return v.Error()
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Normalize 将语言标签转换为目录名，例如 zh-CN、zh_Hans -> zh，不支持时返回默认语言
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if Supported(lang) {
+		return lang
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 && Supported(lang[:i]) {
+		return lang[:i]
+	}
+	return Default
+}
+
+// Match 根据 Accept-Language 请求头选择语言，按 q 值从高到低匹配
+func Match(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" || fields[0] == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		candidates = append(candidates, candidate{fields[0], q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if lang := Normalize(c.lang); lang != Default || strings.HasPrefix(strings.ToLower(c.lang), Default) {
+			return lang
+		}
+	}
+	return Default
+}
+
+// Error 可翻译的错误，Error() 返回默认语言的文本
+type Error struct {
+	Key  string
+	Args []any
+}
+
+// NewError 创建可翻译的错误
+func NewError(key string, args ...any) error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return e.Localize(Default)
+}
+
+// Localize 返回指定语言的错误文本
+func (e *Error) Localize(lang string) string {
+	return T(lang, e.Key, e.Args...)
+}
+
+// Localize 翻译错误，非 *Error 时返回原始文本
+func Localize(lang string, err error) string {
+	return argString(lang, err)
+}
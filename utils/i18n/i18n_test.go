@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\d+\}`)
+
+func placeholders(msg string) string {
+	found := placeholderPattern.FindAllString(msg, -1)
+	sort.Strings(found)
+	return strings.Join(found, ",")
+}
+
+// TestCatalogsComplete 每个语言目录都必须包含全部 key，且参数占位符一致
+func TestCatalogsComplete(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for other, otherCatalog := range catalogs {
+			for key, msg := range otherCatalog {
+				translated, ok := catalog[key]
+				if !ok {
+					t.Errorf("%s: missing key %q (present in %s)", lang, key, other)
+					continue
+				}
+				if placeholders(translated) != placeholders(msg) {
+					t.Errorf("%s: placeholders of %q differ from %s: %q vs %q", lang, key, other, translated, msg)
+				}
+			}
+		}
+	}
+}
+
+// 代码中以字面量引用消息 key 的位置
+var keyUsagePattern = regexp.MustCompile(`(?:RespondError|RespondErrorT|RespondSuccessMessage)\(c, (?:[^"()]*, )?"([a-z][a-z0-9_]*(?:\.[a-z0-9_]+)+)"|i18n\.(?:T|Lookup)\([^,()]+, "([a-z][a-z0-9_]*(?:\.[a-z0-9_]+)+)"|i18n\.NewError\("([^"]+)"`)
+
+// 错误响应中以字面量、拼接或 fmt.Sprintf 构造的消息
+var literalMessagePattern = regexp.MustCompile(`RespondErrorT?\(c, [^,()]+, (fmt\.Sprintf\(|"([^"]*)"(\s*\+)?)`)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(?:\.[a-z0-9_]+)+$`)
+
+// TestSourceKeysExist 代码中使用的 key 必须存在于默认语言目录，错误响应不能直接使用文本
+func TestSourceKeysExist(t *testing.T) {
+	root := filepath.Join("..", "..")
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if name := info.Name(); name == "data" || name == "node_modules" || strings.HasPrefix(name, ".") && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range keyUsagePattern.FindAllStringSubmatch(string(src), -1) {
+			key := m[1] + m[2] + m[3]
+			if _, ok := catalogs[Default][key]; !ok {
+				t.Errorf("%s: key %q is not in the %s catalog", path, key, Default)
+			}
+		}
+		for _, m := range literalMessagePattern.FindAllStringSubmatch(string(src), -1) {
+			if m[1] == "fmt.Sprintf(" || m[3] != "" || !keyPattern.MatchString(m[2]) {
+				t.Errorf("%s: error message should be a catalog key: %s", path, m[0])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"空请求头", "", "en"},
+		{"简体中文", "zh-CN,zh;q=0.9,en;q=0.8", "zh"},
+		{"按 q 值排序", "en;q=0.5,zh-TW;q=0.8", "zh"},
+		{"跳过不支持的语言", "fr-FR,en-US;q=0.7", "en"},
+		{"不支持的语言回退默认", "ja", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.header); got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("zh", "common.invalid_params", errors.New("bad")); got != "参数错误: bad" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := T("zh", "Plain message"); got != "Plain message" {
+		t.Errorf("non-key message should be returned as-is, got %q", got)
+	}
+	nested := NewError("theme.setting.invalid", "Logo", NewError("theme.setting.expect_image"))
+	if got := Localize("zh", nested); got != "配置项 Logo 无效: 需要图片地址" {
+		t.Errorf("Localize() = %q", got)
+	}
+	if got := nested.Error(); got != "Setting Logo is invalid: expected an image URL" {
+		t.Errorf("Error() = %q", got)
+	}
+}
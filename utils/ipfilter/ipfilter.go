@@ -0,0 +1,85 @@
+// Package ipfilter 解析并匹配 CIDR 列表，用于访问控制
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// List 一组 CIDR，空列表不匹配任何地址
+type List []*net.IPNet
+
+// Parse 解析以逗号、空白或换行分隔的 IP / CIDR 列表，单个 IP 视为 /32 或 /128
+func Parse(s string) (List, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	list := make(List, 0, len(fields))
+	for _, f := range fields {
+		if !strings.Contains(f, "/") {
+			ip := net.ParseIP(f)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", f)
+			}
+			if ip.To4() != nil {
+				f += "/32"
+			} else {
+				f += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR: %s", f)
+		}
+		list = append(list, ipNet)
+	}
+	return list, nil
+}
+
+// Contains 判断 IP 是否落在列表中
+func (l List) Contains(ip net.IP) bool {
+	for _, n := range l {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed 拒绝列表优先；允许列表为空时放行所有未被拒绝的地址
+func Allowed(ip string, allow, deny List) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return len(allow) == 0 && len(deny) == 0
+	}
+	if deny.Contains(parsed) {
+		return false
+	}
+	return len(allow) == 0 || allow.Contains(parsed)
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[string]List)
+)
+
+// Cached 与 Parse 相同，但会缓存解析结果，适合在每个请求中调用。无效条目返回空列表
+func Cached(s string) List {
+	cacheMu.RLock()
+	list, ok := cache[s]
+	cacheMu.RUnlock()
+	if ok {
+		return list
+	}
+	list, _ = Parse(s)
+	cacheMu.Lock()
+	// 配置变化不频繁，简单地限制缓存大小即可
+	if len(cache) > 64 {
+		cache = make(map[string]List)
+	}
+	cache[s] = list
+	cacheMu.Unlock()
+	return list
+}
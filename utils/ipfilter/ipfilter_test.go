@@ -0,0 +1,38 @@
+package ipfilter
+
+import "testing"
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		ip    string
+		allow string
+		deny  string
+		want  bool
+	}{
+		{name: "未配置", ip: "1.2.3.4", want: true},
+		{name: "允许列表命中", ip: "10.0.0.5", allow: "10.0.0.0/8, 192.168.1.1", want: true},
+		{name: "允许列表未命中", ip: "1.2.3.4", allow: "10.0.0.0/8", want: false},
+		{name: "单个 IP", ip: "192.168.1.1", allow: "10.0.0.0/8\n192.168.1.1", want: true},
+		{name: "拒绝优先", ip: "10.0.0.5", allow: "10.0.0.0/8", deny: "10.0.0.0/24", want: false},
+		{name: "IPv6", ip: "2001:db8::1", deny: "2001:db8::/32", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allow, err := Parse(tt.allow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			deny, err := Parse(tt.deny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Allowed(tt.ip, allow, deny); got != tt.want {
+				t.Fatalf("Allowed(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+	if _, err := Parse("10.0.0.0/33"); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
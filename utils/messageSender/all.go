@@ -2,8 +2,15 @@ package messageSender
 
 import (
 	_ "github.com/komari-monitor/komari/utils/messageSender/bark"
+	_ "github.com/komari-monitor/komari/utils/messageSender/discord"
 	_ "github.com/komari-monitor/komari/utils/messageSender/email"
 	_ "github.com/komari-monitor/komari/utils/messageSender/empty"
+	_ "github.com/komari-monitor/komari/utils/messageSender/gotify"
+	_ "github.com/komari-monitor/komari/utils/messageSender/matrix"
+	_ "github.com/komari-monitor/komari/utils/messageSender/ntfy"
+	_ "github.com/komari-monitor/komari/utils/messageSender/pagerduty"
+	_ "github.com/komari-monitor/komari/utils/messageSender/slack"
+	_ "github.com/komari-monitor/komari/utils/messageSender/sms"
 	_ "github.com/komari-monitor/komari/utils/messageSender/telegram"
 	_ "github.com/komari-monitor/komari/utils/messageSender/webhook"
 )
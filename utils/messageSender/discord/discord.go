@@ -0,0 +1,94 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+// Discord embed 的长度上限
+const (
+	maxEmbedTitle       = 256
+	maxEmbedDescription = 4096
+)
+
+type DiscordSender struct {
+	Addition
+}
+
+func (d *DiscordSender) GetName() string {
+	return "discord"
+}
+
+func (d *DiscordSender) GetConfiguration() factory.Configuration {
+	return &d.Addition
+}
+
+func (d *DiscordSender) Init() error {
+	return nil
+}
+
+func (d *DiscordSender) Destroy() error {
+	return nil
+}
+
+func (d *DiscordSender) SendTextMessage(message, title string) error {
+	if d.Addition.WebhookURL == "" {
+		return fmt.Errorf("discord webhook URL is not configured")
+	}
+	if message == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	embed := map[string]interface{}{
+		"description": truncate(message, maxEmbedDescription),
+		"color":       d.Addition.Color,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if title != "" {
+		embed["title"] = truncate(title, maxEmbedTitle)
+	}
+	payload := map[string]interface{}{
+		"embeds": []interface{}{embed},
+	}
+	if d.Addition.Username != "" {
+		payload["username"] = d.Addition.Username
+	}
+	if d.Addition.AvatarURL != "" {
+		payload["avatar_url"] = d.Addition.AvatarURL
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(d.Addition.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// 成功时 Discord 返回 204 No Content（带 ?wait=true 时为 200）
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("discord webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*DiscordSender)(nil)
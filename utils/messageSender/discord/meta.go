@@ -0,0 +1,18 @@
+package discord
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	WebhookURL string `json:"webhook_url" required:"true" help:"Discord webhook URL, e.g. https://discord.com/api/webhooks/..."`
+	Username   string `json:"username" help:"Optional. Override the default username of the webhook"`
+	AvatarURL  string `json:"avatar_url" help:"Optional. Override the default avatar of the webhook"`
+	Color      int    `json:"color" default:"15105570" help:"Embed color as a decimal number, e.g. 15105570 for orange"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &DiscordSender{}
+	})
+}
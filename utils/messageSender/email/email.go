@@ -36,6 +36,15 @@ func (e *EmailSender) Destroy() error {
 }
 
 func (e *EmailSender) SendTextMessage(message, title string) error {
+	return e.SendFormattedMessage(message, title, "text")
+}
+
+// SendFormattedMessage html 格式以 text/html 发送，其余格式按纯文本发送
+func (e *EmailSender) SendFormattedMessage(message, title, format string) error {
+	contentType := "text/plain"
+	if format == "html" {
+		contentType = "text/html"
+	}
 
 	if e.Addition.Host == "" || e.Addition.Sender == "" || e.Addition.Username == "" || e.Addition.Password == "" || e.Addition.Receiver == "" {
 		return fmt.Errorf("email sending is not fully configured")
@@ -109,7 +118,7 @@ func (e *EmailSender) SendTextMessage(message, title string) error {
 		"From: " + senderHeader,
 		"Subject: " + encodedSubject,
 		"MIME-Version: 1.0",
-		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Type: " + contentType + "; charset=UTF-8",
 		"Content-Transfer-Encoding: quoted-printable",
 		"Date: " + time.Now().Format(time.RFC1123Z),
 		fmt.Sprintf("Message-ID: <%d@%s>", time.Now().UnixNano(), e.Addition.Host),
@@ -212,3 +221,4 @@ func (e *EmailSender) SendTextMessage(message, title string) error {
 
 // 确保实现了 IMessageSender 接口
 var _ factory.IMessageSender = (*EmailSender)(nil)
+var _ factory.IFormattedMessageSender = (*EmailSender)(nil)
@@ -1,5 +1,11 @@
 package factory
 
+import (
+	"strings"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
 type IMessageSender interface {
 	GetName() string
 	// 请务必返回 &Configuration{} 的指针
@@ -12,3 +18,25 @@ type IMessageSender interface {
 type Configuration interface{}
 
 type MessageSenderConstructor func() IMessageSender
+
+// IFormattedMessageSender 可选接口，支持按模板格式（text / markdown / html）发送消息
+type IFormattedMessageSender interface {
+	SendFormattedMessage(message, title, format string) error
+}
+
+// IEventMessageSender 可选接口，需要事件原始信息（如按客户端去重告警）的发送器实现
+type IEventMessageSender interface {
+	SendEventMessage(event models.EventMessage, message string) error
+}
+
+// EscapeMarkdownV2 转义 Telegram MarkdownV2 的保留字符，供模板与发送器共用
+func EscapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("_*[]()~`>#+-=|{}.!\\", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package gotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type GotifySender struct {
+	Addition
+}
+
+func (g *GotifySender) GetName() string {
+	return "gotify"
+}
+
+func (g *GotifySender) GetConfiguration() factory.Configuration {
+	return &g.Addition
+}
+
+func (g *GotifySender) Init() error {
+	return nil
+}
+
+func (g *GotifySender) Destroy() error {
+	return nil
+}
+
+func (g *GotifySender) SendTextMessage(message, title string) error {
+	return g.SendFormattedMessage(message, title, "text")
+}
+
+// SendFormattedMessage markdown 格式通过 extras 告知客户端按 Markdown 渲染
+func (g *GotifySender) SendFormattedMessage(message, title, format string) error {
+	if g.Addition.ServerURL == "" || g.Addition.AppToken == "" {
+		return fmt.Errorf("gotify is not fully configured")
+	}
+	if message == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	payload := map[string]interface{}{
+		"message":  message,
+		"priority": g.Addition.Priority,
+	}
+	if title != "" {
+		payload["title"] = title
+	}
+	if format == "markdown" {
+		payload["extras"] = map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		}
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(g.Addition.ServerURL, "/")+"/message", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.Addition.AppToken)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gotify API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*GotifySender)(nil)
+var _ factory.IFormattedMessageSender = (*GotifySender)(nil)
@@ -0,0 +1,17 @@
+package gotify
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	ServerURL string `json:"server_url" required:"true" help:"Gotify server URL, e.g. https://gotify.example.com"`
+	AppToken  string `json:"app_token" required:"true" help:"Application token created in Gotify"`
+	Priority  int    `json:"priority" default:"5" help:"Message priority, 0-10"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &GotifySender{}
+	})
+}
@@ -10,22 +10,33 @@ import (
 func LoadProvider(name string, addition string) error {
 	mu.Lock()
 	defer mu.Unlock()
+	provider, err := newProvider(name, addition)
+	if err != nil {
+		return err
+	}
+	if currentProvider != nil {
+		currentProvider.Destroy()
+	}
+	currentProvider = provider
+	return nil
+}
+
+// newProvider 按名称与配置创建并初始化一个提供者实例
+func newProvider(name string, addition string) (factory.IMessageSender, error) {
 	constructor, exists := factory.GetConstructor(name)
 	if !exists {
-		return fmt.Errorf("message sender provider not found: %s", name)
+		return nil, fmt.Errorf("message sender provider not found: %s", name)
 	}
 
 	provider := constructor()
 	err := json.Unmarshal([]byte(addition), provider.GetConfiguration())
 	if err != nil {
-		return fmt.Errorf("failed to load config for provider %s: %w", name, err)
+		return nil, fmt.Errorf("failed to load config for provider %s: %w", name, err)
 	}
-	provider.Init()
-	if currentProvider != nil {
-		currentProvider.Destroy()
+	if err := provider.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize provider %s: %w", name, err)
 	}
-	currentProvider = provider
-	return nil
+	return provider, nil
 }
 
 func GetProviderConfiguration(name string) (map[string]interface{}, error) {
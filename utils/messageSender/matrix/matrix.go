@@ -0,0 +1,114 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+// 同一进程内的事务序号，配合时间戳保证 txnId 唯一
+var txnCounter atomic.Int64
+
+type MatrixSender struct {
+	Addition
+}
+
+func (m *MatrixSender) GetName() string {
+	return "matrix"
+}
+
+func (m *MatrixSender) GetConfiguration() factory.Configuration {
+	return &m.Addition
+}
+
+func (m *MatrixSender) Init() error {
+	return nil
+}
+
+func (m *MatrixSender) Destroy() error {
+	return nil
+}
+
+func (m *MatrixSender) SendTextMessage(message, title string) error {
+	return m.SendFormattedMessage(message, title, "text")
+}
+
+// SendFormattedMessage html 格式使用 org.matrix.custom.html 发送，其余格式按纯文本发送
+func (m *MatrixSender) SendFormattedMessage(message, title, format string) error {
+	if m.Addition.Homeserver == "" || m.Addition.AccessToken == "" || m.Addition.RoomID == "" {
+		return fmt.Errorf("matrix is not fully configured")
+	}
+	if message == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	msgType := m.Addition.MsgType
+	if msgType == "" {
+		msgType = "m.text"
+	}
+	body := message
+	if title != "" {
+		body = title + "\n" + message
+	}
+	content := map[string]interface{}{
+		"msgtype": msgType,
+		"body":    body,
+	}
+	if format == "html" {
+		formatted := message
+		if title != "" {
+			formatted = "<b>" + html.EscapeString(title) + "</b><br>" + message
+		}
+		content["format"] = "org.matrix.custom.html"
+		content["formatted_body"] = formatted
+	}
+
+	jsonData, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	txnID := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + strconv.FormatInt(txnCounter.Add(1), 10)
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.Addition.Homeserver, "/"), url.PathEscape(m.Addition.RoomID), txnID)
+
+	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.Addition.AccessToken)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(respBody, &result) == nil && result.ErrCode != "" {
+			return fmt.Errorf("matrix API error (%s): %s", result.ErrCode, result.Error)
+		}
+		return fmt.Errorf("matrix API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*MatrixSender)(nil)
+var _ factory.IFormattedMessageSender = (*MatrixSender)(nil)
@@ -0,0 +1,18 @@
+package matrix
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	Homeserver  string `json:"homeserver" required:"true" default:"https://matrix.org" help:"Matrix homeserver URL"`
+	AccessToken string `json:"access_token" required:"true" help:"Access token of the bot account"`
+	RoomID      string `json:"room_id" required:"true" help:"Room ID to send messages to, e.g. !abcdef:matrix.org"`
+	MsgType     string `json:"msg_type" type:"option" default:"m.text" options:"m.text,m.notice" help:"Message type, m.notice is usually not highlighted"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &MatrixSender{}
+	})
+}
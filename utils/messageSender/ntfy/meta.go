@@ -0,0 +1,21 @@
+package ntfy
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	ServerURL string `json:"server_url" required:"true" default:"https://ntfy.sh" help:"ntfy server URL"`
+	Topic     string `json:"topic" required:"true" help:"Topic to publish to"`
+	Token     string `json:"token" help:"Optional. Access token for protected topics"`
+	Username  string `json:"username" help:"Optional. Username for basic auth, ignored when token is set"`
+	Password  string `json:"password" help:"Optional. Password for basic auth"`
+	Priority  string `json:"priority" type:"option" default:"default" options:"min,low,default,high,max" help:"Message priority"`
+	Tags      string `json:"tags" help:"Optional. Comma-separated tags or emoji shortcodes, e.g. warning,computer"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &NtfySender{}
+	})
+}
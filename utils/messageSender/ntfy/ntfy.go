@@ -0,0 +1,88 @@
+package ntfy
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type NtfySender struct {
+	Addition
+}
+
+func (n *NtfySender) GetName() string {
+	return "ntfy"
+}
+
+func (n *NtfySender) GetConfiguration() factory.Configuration {
+	return &n.Addition
+}
+
+func (n *NtfySender) Init() error {
+	return nil
+}
+
+func (n *NtfySender) Destroy() error {
+	return nil
+}
+
+func (n *NtfySender) SendTextMessage(message, title string) error {
+	return n.SendFormattedMessage(message, title, "text")
+}
+
+// SendFormattedMessage markdown 格式通过 Markdown 头启用 ntfy 的 Markdown 渲染
+func (n *NtfySender) SendFormattedMessage(message, title, format string) error {
+	if n.Addition.ServerURL == "" || n.Addition.Topic == "" {
+		return fmt.Errorf("ntfy is not fully configured")
+	}
+	if message == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	endpoint := strings.TrimRight(n.Addition.ServerURL, "/") + "/" + url.PathEscape(n.Addition.Topic)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	// 请求头只能是 ASCII，非 ASCII 标题按 RFC 2047 编码，ntfy 会自动解码
+	if title != "" {
+		req.Header.Set("Title", mime.QEncoding.Encode("UTF-8", title))
+	}
+	if n.Addition.Priority != "" {
+		req.Header.Set("Priority", n.Addition.Priority)
+	}
+	if n.Addition.Tags != "" {
+		req.Header.Set("Tags", n.Addition.Tags)
+	}
+	if format == "markdown" {
+		req.Header.Set("Markdown", "yes")
+	}
+	if n.Addition.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Addition.Token)
+	} else if n.Addition.Username != "" {
+		req.SetBasicAuth(n.Addition.Username, n.Addition.Password)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ntfy returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*NtfySender)(nil)
+var _ factory.IFormattedMessageSender = (*NtfySender)(nil)
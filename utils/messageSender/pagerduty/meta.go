@@ -0,0 +1,18 @@
+package pagerduty
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	URL        string `json:"url" required:"true" default:"https://events.pagerduty.com/v2/enqueue" help:"Events API v2 endpoint. Any compatible endpoint (e.g. Opsgenie or self-hosted gateways) can be used"`
+	RoutingKey string `json:"routing_key" required:"true" help:"Integration / routing key of the service"`
+	Severity   string `json:"severity" type:"option" default:"critical" options:"critical,error,warning,info" help:"Severity of triggered incidents"`
+	Source     string `json:"source" default:"komari" help:"Source used when the event is not related to a specific client"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &PagerDutySender{}
+	})
+}
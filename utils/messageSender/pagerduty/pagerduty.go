@@ -0,0 +1,175 @@
+package pagerduty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+const maxSummary = 1024
+
+type PagerDutySender struct {
+	Addition
+}
+
+type eventPayload struct {
+	RoutingKey  string        `json:"routing_key"`
+	EventAction string        `json:"event_action"`
+	DedupKey    string        `json:"dedup_key,omitempty"`
+	Payload     *alertPayload `json:"payload,omitempty"`
+	Client      string        `json:"client,omitempty"`
+}
+
+type alertPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+func (p *PagerDutySender) GetName() string {
+	return "pagerduty"
+}
+
+func (p *PagerDutySender) GetConfiguration() factory.Configuration {
+	return &p.Addition
+}
+
+func (p *PagerDutySender) Init() error {
+	return nil
+}
+
+func (p *PagerDutySender) Destroy() error {
+	return nil
+}
+
+// SendTextMessage 与客户端无关的消息只触发事件，不做去重
+func (p *PagerDutySender) SendTextMessage(message, title string) error {
+	return p.send(eventPayload{
+		EventAction: "trigger",
+		Payload:     p.alert(message, title, p.Addition.Source, ""),
+	})
+}
+
+// SendEventMessage 每个客户端单独发送一条事件，去重键由事件类别与客户端 UUID 组成，
+// 这样恢复类事件（上线、续费）可以解决对应的告警
+func (p *PagerDutySender) SendEventMessage(event models.EventMessage, message string) error {
+	if len(event.Clients) == 0 {
+		return p.SendTextMessage(message, event.Event)
+	}
+	action := "trigger"
+	if isResolve(event.Event) {
+		action = "resolve"
+	}
+	for _, client := range event.Clients {
+		ev := eventPayload{
+			EventAction: action,
+			DedupKey:    DedupKey(event.Event, client.UUID),
+		}
+		if action == "trigger" {
+			source := client.Name
+			if source == "" {
+				source = client.UUID
+			}
+			ev.Payload = p.alert(message, event.Event, source, client.Group)
+			ev.Payload.Class = event.Event
+			if !event.Time.IsZero() {
+				ev.Payload.Timestamp = event.Time.UTC().Format(time.RFC3339)
+			}
+			ev.Payload.CustomDetails = map[string]any{
+				"uuid":   client.UUID,
+				"region": client.Region,
+				"ipv4":   client.IPv4,
+				"ipv6":   client.IPv6,
+			}
+		}
+		if err := p.send(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DedupKey 同一类别的触发与恢复事件使用相同的去重键
+func DedupKey(event, uuid string) string {
+	return "komari-" + eventCategory(event) + "-" + uuid
+}
+
+func eventCategory(event string) string {
+	switch event {
+	case messageevent.Offline, messageevent.Online:
+		return "availability"
+	case messageevent.Expire, messageevent.Renew:
+		return "expiry"
+	}
+	return strings.ToLower(event)
+}
+
+func isResolve(event string) bool {
+	return event == messageevent.Online || event == messageevent.Renew
+}
+
+func (p *PagerDutySender) alert(message, title, source, group string) *alertPayload {
+	summary := strings.TrimSpace(message)
+	if title != "" {
+		summary = title + ": " + summary
+	}
+	if r := []rune(summary); len(r) > maxSummary {
+		summary = string(r[:maxSummary])
+	}
+	severity := p.Addition.Severity
+	if severity == "" {
+		severity = "critical"
+	}
+	if source == "" {
+		source = "komari"
+	}
+	return &alertPayload{
+		Summary:  summary,
+		Source:   source,
+		Severity: severity,
+		Group:    group,
+	}
+}
+
+func (p *PagerDutySender) send(ev eventPayload) error {
+	if p.Addition.URL == "" || p.Addition.RoutingKey == "" {
+		return fmt.Errorf("pagerduty is not fully configured")
+	}
+	ev.RoutingKey = p.Addition.RoutingKey
+	ev.Client = "Komari"
+
+	jsonData, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(p.Addition.URL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Events API v2 成功时返回 202 Accepted
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("events API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*PagerDutySender)(nil)
+var _ factory.IEventMessageSender = (*PagerDutySender)(nil)
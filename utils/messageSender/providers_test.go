@@ -0,0 +1,183 @@
+package messageSender
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type capturedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   string
+}
+
+func newStub(t *testing.T, status int) (*httptest.Server, func() []capturedRequest) {
+	var mu sync.Mutex
+	var requests []capturedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, capturedRequest{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Clone(), string(body)})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedRequest(nil), requests...)
+	}
+}
+
+func loadSender(t *testing.T, name string, addition map[string]any) factory.IMessageSender {
+	t.Helper()
+	raw, _ := json.Marshal(addition)
+	if err := LoadProvider(name, string(raw)); err != nil {
+		t.Fatalf("LoadProvider(%s) error = %v", name, err)
+	}
+	return CurrentProvider()
+}
+
+func TestProviders(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		addition func(url string) map[string]any
+		check    func(t *testing.T, reqs []capturedRequest)
+	}{
+		{"slack", 200, func(u string) map[string]any { return map[string]any{"webhook_url": u + "/hook"} },
+			func(t *testing.T, reqs []capturedRequest) {
+				var body struct {
+					Text   string           `json:"text"`
+					Blocks []map[string]any `json:"blocks"`
+				}
+				json.Unmarshal([]byte(reqs[0].Body), &body)
+				if len(body.Blocks) != 2 || body.Blocks[0]["type"] != "header" || !strings.Contains(body.Text, "hello") {
+					t.Errorf("unexpected slack payload: %s", reqs[0].Body)
+				}
+			}},
+		{"discord", 204, func(u string) map[string]any { return map[string]any{"webhook_url": u + "/hook", "color": 1} },
+			func(t *testing.T, reqs []capturedRequest) {
+				var body struct {
+					Embeds []struct {
+						Title       string `json:"title"`
+						Description string `json:"description"`
+						Color       int    `json:"color"`
+					} `json:"embeds"`
+				}
+				json.Unmarshal([]byte(reqs[0].Body), &body)
+				if len(body.Embeds) != 1 || body.Embeds[0].Title != "Test" || body.Embeds[0].Description != "hello" || body.Embeds[0].Color != 1 {
+					t.Errorf("unexpected discord payload: %s", reqs[0].Body)
+				}
+			}},
+		{"matrix", 200, func(u string) map[string]any {
+			return map[string]any{"homeserver": u, "access_token": "tk", "room_id": "!room:example.org"}
+		}, func(t *testing.T, reqs []capturedRequest) {
+			r := reqs[0]
+			if r.Method != http.MethodPut || !strings.HasPrefix(r.Path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/") {
+				t.Errorf("unexpected matrix request: %s %s", r.Method, r.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer tk" {
+				t.Errorf("missing matrix token")
+			}
+		}},
+		{"gotify", 200, func(u string) map[string]any { return map[string]any{"server_url": u, "app_token": "app", "priority": 8} },
+			func(t *testing.T, reqs []capturedRequest) {
+				r := reqs[0]
+				if r.Path != "/message" || r.Header.Get("X-Gotify-Key") != "app" || !strings.Contains(r.Body, `"priority":8`) {
+					t.Errorf("unexpected gotify request: %s %v %s", r.Path, r.Header, r.Body)
+				}
+			}},
+		{"ntfy", 200, func(u string) map[string]any { return map[string]any{"server_url": u, "topic": "alerts", "priority": "high", "token": "tk"} },
+			func(t *testing.T, reqs []capturedRequest) {
+				r := reqs[0]
+				if r.Path != "/alerts" || r.Body != "hello" || r.Header.Get("Title") != "Test" || r.Header.Get("Priority") != "high" || r.Header.Get("Authorization") != "Bearer tk" {
+					t.Errorf("unexpected ntfy request: %s %v %s", r.Path, r.Header, r.Body)
+				}
+			}},
+		{"pagerduty", 202, func(u string) map[string]any { return map[string]any{"url": u + "/v2/enqueue", "routing_key": "rk"} },
+			func(t *testing.T, reqs []capturedRequest) {
+				var body map[string]any
+				json.Unmarshal([]byte(reqs[0].Body), &body)
+				if body["routing_key"] != "rk" || body["event_action"] != "trigger" {
+					t.Errorf("unexpected events payload: %s", reqs[0].Body)
+				}
+			}},
+		{"sms", 200, func(u string) map[string]any {
+			return map[string]any{"url": u + "/send", "method": "POST", "content_type": "application/json",
+				"body": `{"to":"{{to}}","text":"{{message}}"}`, "recipients": "+10000000001, +10000000002"}
+		}, func(t *testing.T, reqs []capturedRequest) {
+			if len(reqs) != 2 {
+				t.Fatalf("expected one request per recipient, got %d", len(reqs))
+			}
+			var body map[string]string
+			if err := json.Unmarshal([]byte(reqs[1].Body), &body); err != nil {
+				t.Fatalf("invalid sms body: %v", err)
+			}
+			if body["to"] != "+10000000002" || body["text"] != "Test: hello" {
+				t.Errorf("unexpected sms body: %v", body)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newStub(t, tt.status)
+			sender := loadSender(t, tt.name, tt.addition(srv.URL))
+			if err := sender.SendTextMessage("hello", "Test"); err != nil {
+				t.Fatalf("SendTextMessage() error = %v", err)
+			}
+			reqs := requests()
+			if len(reqs) == 0 {
+				t.Fatal("stub received no request")
+			}
+			tt.check(t, reqs)
+		})
+	}
+}
+
+func TestProvidersErrorStatus(t *testing.T) {
+	srv, _ := newStub(t, 500)
+	sender := loadSender(t, "discord", map[string]any{"webhook_url": srv.URL})
+	if err := sender.SendTextMessage("hello", "Test"); err == nil {
+		t.Error("expected error on non-2xx status")
+	}
+}
+
+func TestPagerDutyDedup(t *testing.T) {
+	srv, requests := newStub(t, 202)
+	sender := loadSender(t, "pagerduty", map[string]any{"url": srv.URL, "routing_key": "rk"})
+	ep, ok := sender.(factory.IEventMessageSender)
+	if !ok {
+		t.Fatal("pagerduty sender should implement IEventMessageSender")
+	}
+	clients := []models.Client{{UUID: "a", Name: "node-a"}}
+	if err := ep.SendEventMessage(models.EventMessage{Event: "Offline", Clients: clients}, "down"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.SendEventMessage(models.EventMessage{Event: "Online", Clients: clients}, "up"); err != nil {
+		t.Fatal(err)
+	}
+	reqs := requests()
+	var trigger, resolve map[string]any
+	json.Unmarshal([]byte(reqs[0].Body), &trigger)
+	json.Unmarshal([]byte(reqs[1].Body), &resolve)
+	if trigger["event_action"] != "trigger" || resolve["event_action"] != "resolve" {
+		t.Errorf("unexpected actions: %v / %v", trigger["event_action"], resolve["event_action"])
+	}
+	if trigger["dedup_key"] == nil || trigger["dedup_key"] != resolve["dedup_key"] {
+		t.Errorf("dedup keys should match: %v / %v", trigger["dedup_key"], resolve["dedup_key"])
+	}
+	if _, has := resolve["payload"]; has {
+		t.Error("resolve event should not carry a payload")
+	}
+}
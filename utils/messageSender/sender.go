@@ -6,12 +6,12 @@ import (
 	"log"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/komari-monitor/komari/database"
 	"github.com/komari-monitor/komari/database/auditlog"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/i18n"
 	"github.com/komari-monitor/komari/utils/messageSender/factory"
 )
 
@@ -92,7 +92,8 @@ func SendTextMessage(message string, title string) error {
 	return err
 }
 func SendEvent(event models.EventMessage) error {
-	if CurrentProvider() == nil {
+	provider := CurrentProvider()
+	if provider == nil {
 		return fmt.Errorf("message sender provider is not initialized")
 	}
 	var err error
@@ -103,14 +104,43 @@ func SendEvent(event models.EventMessage) error {
 	if !cfg.NotificationEnabled {
 		return nil
 	}
-	messageTemplate := cfg.NotificationTemplate
-	if messageTemplate == "" {
-		messageTemplate = "{{emoji}}{{emoji}}{{emoji}}\nEvent: {{event}}\nClients: {{client}}\nMessage: {{message}}\nTime: {{time}}"
+	return sendEventWith(provider, cfg, event)
+}
+
+// SendEventVia 使用指定的渠道发送事件，不影响当前默认渠道，用于升级提醒等场景
+func SendEventVia(channel string, event models.EventMessage) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	if !cfg.NotificationEnabled {
+		return nil
+	}
+	senderConfig, err := database.GetMessageSenderConfigByName(channel)
+	if err != nil {
+		return fmt.Errorf("message sender provider not configured: %s", channel)
+	}
+	provider, err := newProvider(channel, senderConfig.Addition)
+	if err != nil {
+		return err
 	}
-	messageTemplate = parseTemplate(messageTemplate, event)
+	defer provider.Destroy()
+	return sendEventWith(provider, cfg, event)
+}
+
+func sendEventWith(provider factory.IMessageSender, cfg models.Config, event models.EventMessage) error {
+	var err error
+	message, format := renderEvent(cfg, event, provider.GetName())
+	title := EventTitle(cfg.NotificationLocale, event.Event)
 
 	for i := 0; i < 3; i++ {
-		err = CurrentProvider().SendTextMessage(messageTemplate, event.Event)
+		if ep, ok := provider.(factory.IEventMessageSender); ok {
+			err = ep.SendEventMessage(event, message)
+		} else if fp, ok := provider.(factory.IFormattedMessageSender); ok && format != FormatText {
+			err = fp.SendFormattedMessage(message, title, format)
+		} else {
+			err = provider.SendTextMessage(message, title)
+		}
 		if err == nil || err.Error() == "short response: \x00\x00\x00\x1a\x00\x00\x00" { // QQ 会返回这个错误，但实际上消息是发送成功的
 			auditlog.Log("", "", "Event message sent: "+event.Event, "info")
 			return nil
@@ -120,29 +150,45 @@ func SendEvent(event models.EventMessage) error {
 	return err
 }
 
-func parseTemplate(messageTemplate string, event models.EventMessage) string {
-	// Aggregate client names. If Name is empty, fall back to UUID.
-	clientNames := make([]string, 0, len(event.Clients))
-	for _, c := range event.Clients {
-		name := c.Name
-		if strings.TrimSpace(name) == "" {
-			// fallback to UUID when name is not set
-			name = c.UUID
+// ResolveTemplate 返回事件在指定渠道下使用的模板与格式，未配置专用模板时使用全局模板
+func ResolveTemplate(cfg models.Config, eventName, channel string) (string, string) {
+	if tmpl, err := database.GetNotificationTemplate(eventName, channel); err == nil && tmpl.Template != "" {
+		format := tmpl.Format
+		if !ValidFormat(format) {
+			format = FormatText
 		}
-		clientNames = append(clientNames, name)
+		return tmpl.Template, format
 	}
-	joinedClients := strings.Join(clientNames, ", ")
+	if cfg.NotificationTemplate != "" {
+		return cfg.NotificationTemplate, FormatText
+	}
+	return LocalizedDefaultTemplate(cfg.NotificationLocale), FormatText
+}
 
-	replaceMap := map[string]string{
-		"{{event}}":   event.Event,
-		"{{client}}":  joinedClients,
-		"{{time}}":    event.Time.Format(time.RFC3339),
-		"{{message}}": event.Message,
-		"{{emoji}}":   event.Emoji,
-	}
-	result := messageTemplate
-	for placeholder, value := range replaceMap {
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-	return result
+// LocalizedDefaultTemplate 返回指定语言的默认模板
+func LocalizedDefaultTemplate(locale string) string {
+	if tmpl, ok := i18n.Lookup(locale, "notify.default_template"); ok {
+		return tmpl
+	}
+	return DefaultTemplate
+}
+
+// EventTitle 返回事件在指定语言下的名称，用作邮件主题等标题，未翻译的事件使用原名
+func EventTitle(locale, event string) string {
+	if title, ok := i18n.Lookup(locale, "event."+strings.ToLower(event)); ok {
+		return title
+	}
+	return event
+}
+
+// renderEvent 渲染失败时回退到默认模板，保证通知仍能送达
+func renderEvent(cfg models.Config, event models.EventMessage, channel string) (string, string) {
+	messageTemplate, format := ResolveTemplate(cfg, event.Event, channel)
+	message, err := RenderTemplate(messageTemplate, event, channel)
+	if err == nil {
+		return message, format
+	}
+	log.Printf("Failed to render notification template for %s: %v", event.Event, err)
+	message, _ = RenderTemplate(LocalizedDefaultTemplate(cfg.NotificationLocale), event, channel)
+	return message, FormatText
 }
@@ -0,0 +1,18 @@
+package slack
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	WebhookURL string `json:"webhook_url" required:"true" help:"Slack incoming webhook URL, e.g. https://hooks.slack.com/services/..."`
+	Channel    string `json:"channel" help:"Optional. Override the default channel of the webhook"`
+	Username   string `json:"username" help:"Optional. Override the default username of the webhook"`
+	IconEmoji  string `json:"icon_emoji" help:"Optional. Emoji used as the icon, e.g. :bell:"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &SlackSender{}
+	})
+}
@@ -0,0 +1,102 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+// Slack 单个 section 文本的长度上限
+const maxSectionText = 3000
+
+type SlackSender struct {
+	Addition
+}
+
+func (s *SlackSender) GetName() string {
+	return "slack"
+}
+
+func (s *SlackSender) GetConfiguration() factory.Configuration {
+	return &s.Addition
+}
+
+func (s *SlackSender) Init() error {
+	return nil
+}
+
+func (s *SlackSender) Destroy() error {
+	return nil
+}
+
+func (s *SlackSender) SendTextMessage(message, title string) error {
+	if s.Addition.WebhookURL == "" {
+		return fmt.Errorf("slack webhook URL is not configured")
+	}
+	if message == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	// Block Kit: 标题 + 正文，text 字段作为通知预览与不支持 blocks 时的回退
+	var blocks []map[string]interface{}
+	if title != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": title, "emoji": true},
+		})
+	}
+	text := message
+	if r := []rune(text); len(r) > maxSectionText {
+		text = string(r[:maxSectionText])
+	}
+	blocks = append(blocks, map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{"type": "mrkdwn", "text": text},
+	})
+
+	payload := map[string]interface{}{
+		"text":   fallbackText(message, title),
+		"blocks": blocks,
+	}
+	if s.Addition.Channel != "" {
+		payload["channel"] = s.Addition.Channel
+	}
+	if s.Addition.Username != "" {
+		payload["username"] = s.Addition.Username
+	}
+	if s.Addition.IconEmoji != "" {
+		payload["icon_emoji"] = s.Addition.IconEmoji
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(s.Addition.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("slack webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func fallbackText(message, title string) string {
+	if title == "" {
+		return message
+	}
+	return title + "\n" + message
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*SlackSender)(nil)
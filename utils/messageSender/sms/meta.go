@@ -0,0 +1,21 @@
+package sms
+
+import (
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type Addition struct {
+	URL         string `json:"url" required:"true" help:"Gateway URL. For GET requests {{to}}, {{message}} and {{title}} are replaced with URL-encoded values"`
+	Method      string `json:"method" default:"POST" type:"option" options:"POST,GET"`
+	ContentType string `json:"content_type" default:"application/json" type:"option" options:"application/json,application/x-www-form-urlencoded"`
+	Body        string `json:"body" default:"{\"to\":\"{{to}}\",\"text\":\"{{message}}\"}" help:"Request body template, {{to}}, {{message}} and {{title}} are escaped according to the content type"`
+	Recipients  string `json:"recipients" required:"true" help:"Comma-separated phone numbers, one request is sent per recipient"`
+	Headers     string `json:"headers" help:"HTTP headers in JSON format, e.g. {\"Authorization\":\"Bearer xxx\"}"`
+	MaxLength   int    `json:"max_length" default:"0" help:"Truncate the text to this many characters, 0 means no limit"`
+}
+
+func init() {
+	factory.RegisterMessageSender(func() factory.IMessageSender {
+		return &SmsSender{}
+	})
+}
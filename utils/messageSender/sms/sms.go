@@ -0,0 +1,144 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+)
+
+type SmsSender struct {
+	Addition
+}
+
+func (s *SmsSender) GetName() string {
+	return "sms"
+}
+
+func (s *SmsSender) GetConfiguration() factory.Configuration {
+	return &s.Addition
+}
+
+func (s *SmsSender) Init() error {
+	return nil
+}
+
+func (s *SmsSender) Destroy() error {
+	return nil
+}
+
+func (s *SmsSender) SendTextMessage(message, title string) error {
+	if s.Addition.URL == "" {
+		return fmt.Errorf("SMS gateway URL is not configured")
+	}
+	recipients := parseRecipients(s.Addition.Recipients)
+	if len(recipients) == 0 {
+		return fmt.Errorf("no SMS recipients configured")
+	}
+	// 短信不支持富文本，标题与正文合并为一段
+	text := message
+	if title != "" {
+		text = title + ": " + message
+	}
+	if r := []rune(text); s.Addition.MaxLength > 0 && len(r) > s.Addition.MaxLength {
+		text = string(r[:s.Addition.MaxLength])
+	}
+
+	var failed []string
+	for _, to := range recipients {
+		if err := s.send(to, text, title); err != nil {
+			failed = append(failed, to+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send SMS to %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (s *SmsSender) send(to, text, title string) error {
+	method := strings.ToUpper(s.Addition.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := s.Addition.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	var req *http.Request
+	var err error
+	switch method {
+	case http.MethodGet:
+		req, err = http.NewRequest(http.MethodGet, render(s.Addition.URL, to, text, title, url.QueryEscape), nil)
+	case http.MethodPost:
+		escape := url.QueryEscape
+		if strings.Contains(strings.ToLower(contentType), "json") {
+			escape = jsonEscape
+		}
+		req, err = http.NewRequest(http.MethodPost, s.Addition.URL, bytes.NewBufferString(render(s.Addition.Body, to, text, title, escape)))
+		if err == nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+	default:
+		return fmt.Errorf("unsupported HTTP method: %s", method)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	if s.Addition.Headers != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(s.Addition.Headers), &headers); err != nil {
+			return fmt.Errorf("invalid headers: %v", err)
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gateway returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func render(template, to, text, title string, escape func(string) string) string {
+	return strings.NewReplacer(
+		"{{to}}", escape(to),
+		"{{message}}", escape(text),
+		"{{title}}", escape(title),
+	).Replace(template)
+}
+
+// jsonEscape 返回可直接放入 JSON 字符串字面量引号内的内容
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+func parseRecipients(s string) []string {
+	var result []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*SmsSender)(nil)
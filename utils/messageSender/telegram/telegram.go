@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
 	"net/url"
 
@@ -33,9 +34,28 @@ func (t *TelegramSender) Destroy() error {
 }
 
 func (t *TelegramSender) SendTextMessage(message, title string) error {
+	return t.SendFormattedMessage(message, title, "html")
+}
+
+// SendFormattedMessage 根据模板格式选择 parse_mode，markdown 对应 MarkdownV2
+func (t *TelegramSender) SendFormattedMessage(message, title, format string) error {
 	fullMessage := message
-	if title != "" {
-		fullMessage = fmt.Sprintf("<b>%s</b>\n%s", title, message)
+	parseMode := ""
+	switch format {
+	case "html":
+		parseMode = "HTML"
+		if title != "" {
+			fullMessage = fmt.Sprintf("<b>%s</b>\n%s", html.EscapeString(title), message)
+		}
+	case "markdown":
+		parseMode = "MarkdownV2"
+		if title != "" {
+			fullMessage = fmt.Sprintf("*%s*\n%s", factory.EscapeMarkdownV2(title), message)
+		}
+	default:
+		if title != "" {
+			fullMessage = title + "\n" + message
+		}
 	}
 
 	if fullMessage == "" {
@@ -47,7 +67,9 @@ func (t *TelegramSender) SendTextMessage(message, title string) error {
 	data := url.Values{}
 	data.Set("chat_id", t.Addition.ChatID)
 	data.Set("text", fullMessage)
-	data.Set("parse_mode", "HTML")
+	if parseMode != "" {
+		data.Set("parse_mode", parseMode)
+	}
 
 	// Add message_thread_id if provided
 	if t.Addition.MessageThreadID != "" {
@@ -87,3 +109,4 @@ func init() {
 
 // 确保实现了 IMessageSender 接口
 var _ factory.IMessageSender = (*TelegramSender)(nil)
+var _ factory.IFormattedMessageSender = (*TelegramSender)(nil)
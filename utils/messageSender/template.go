@@ -0,0 +1,225 @@
+package messageSender
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/komari-monitor/komari/common"
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/messageSender/factory"
+	"github.com/komari-monitor/komari/ws"
+)
+
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+
+	DefaultTemplate = "{{emoji}}{{emoji}}{{emoji}}\nEvent: {{event}}\nClients: {{client}}\nMessage: {{message}}\nTime: {{time}}"
+
+	// 渲染结果的最大长度，防止模板中的循环产生超大消息
+	maxRenderedSize = 64 * 1024
+)
+
+var ErrTemplateTooLarge = errors.New("rendered message exceeds size limit")
+
+// TemplateClient 模板中可访问的客户端信息，Token 不会暴露给模板
+type TemplateClient struct {
+	models.Client
+	Online bool
+	Report *common.Report // 最新上报的监控数据，离线时可能为 nil
+}
+
+// TemplateData 模板渲染时的根数据
+type TemplateData struct {
+	Event   string
+	Channel string
+	Message string
+	Emoji   string
+	Time    time.Time
+	Clients []TemplateClient
+}
+
+// ValidFormat 判断是否为支持的消息格式
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatText, FormatMarkdown, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// RenderTemplate 使用 text/template 渲染通知模板，兼容旧版的 {{event}} 等占位符
+func RenderTemplate(messageTemplate string, event models.EventMessage, channel string) (string, error) {
+	data := buildTemplateData(event, channel)
+	tmpl, err := template.New("notification").Option("missingkey=zero").Funcs(templateFuncs(data)).Parse(messageTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&limitedWriter{w: &buf, n: maxRenderedSize}, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ParseTemplate 仅检查模板语法
+func ParseTemplate(messageTemplate string) error {
+	_, err := template.New("notification").Funcs(templateFuncs(TemplateData{})).Parse(messageTemplate)
+	return err
+}
+
+func buildTemplateData(event models.EventMessage, channel string) TemplateData {
+	reports := ws.GetLatestReport()
+	connected := ws.GetConnectedClients()
+	clients := make([]TemplateClient, 0, len(event.Clients))
+	for _, c := range event.Clients {
+		c.Token = ""
+		_, online := connected[c.UUID]
+		clients = append(clients, TemplateClient{
+			Client: c,
+			Online: online,
+			Report: reports[c.UUID],
+		})
+	}
+	eventTime := event.Time
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+	return TemplateData{
+		Event:   event.Event,
+		Channel: channel,
+		Message: event.Message,
+		Emoji:   event.Emoji,
+		Time:    eventTime.In(models.GetAppLocation()),
+		Clients: clients,
+	}
+}
+
+func clientNames(clients []TemplateClient) string {
+	// Aggregate client names. If Name is empty, fall back to UUID.
+	names := make([]string, 0, len(clients))
+	for _, c := range clients {
+		name := c.Name
+		if strings.TrimSpace(name) == "" {
+			name = c.UUID
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func templateFuncs(data TemplateData) template.FuncMap {
+	return template.FuncMap{
+		// 旧版占位符
+		"event":   func() string { return data.Event },
+		"client":  func() string { return clientNames(data.Clients) },
+		"time":    func() string { return data.Time.Format(time.RFC3339) },
+		"message": func() string { return data.Message },
+		"emoji":   func() string { return data.Emoji },
+
+		"formatTime": formatTime,
+		"now":        func() time.Time { return time.Now().In(models.GetAppLocation()) },
+		"bytes":      humanBytes,
+		"percent": func(used, total any) string {
+			u, t := toFloat(used), toFloat(total)
+			if t == 0 {
+				return "0.00%"
+			}
+			return fmt.Sprintf("%.2f%%", u/t*100)
+		},
+		"join":     strings.Join,
+		"split":    strings.Split,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"contains": strings.Contains,
+		"default": func(def, value any) any {
+			if value == nil || fmt.Sprint(value) == "" {
+				return def
+			}
+			return value
+		},
+		"escapeMarkdown": factory.EscapeMarkdownV2,
+		"tags": func(tags string) []string {
+			var result []string
+			for _, t := range strings.Split(tags, ";") {
+				if t = strings.TrimSpace(t); t != "" {
+					result = append(result, t)
+				}
+			}
+			return result
+		},
+	}
+}
+
+// formatTime 以应用时区格式化时间，layout 为空时使用 "2006-01-02 15:04:05"
+func formatTime(t any, layout ...string) string {
+	var tm time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tm = v
+	case models.LocalTime:
+		tm = v.ToTime()
+	case int64:
+		tm = time.Unix(v, 0)
+	default:
+		return ""
+	}
+	if tm.IsZero() {
+		return ""
+	}
+	l := "2006-01-02 15:04:05"
+	if len(layout) > 0 && layout[0] != "" {
+		l = layout[0]
+	}
+	return tm.In(models.GetAppLocation()).Format(l)
+}
+
+func humanBytes(v any) string {
+	b := toFloat(v)
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for b >= 1024 && i < len(units)-1 {
+		b /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", b, units[i])
+	}
+	return fmt.Sprintf("%.2f %s", b, units[i])
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+type limitedWriter struct {
+	w *bytes.Buffer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.w.Len()+len(p) > l.n {
+		return 0, ErrTemplateTooLarge
+	}
+	return l.w.Write(p)
+}
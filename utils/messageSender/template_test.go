@@ -0,0 +1,59 @@
+package messageSender
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	event := models.EventMessage{
+		Event:   "Offline",
+		Time:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "down",
+		Emoji:   "🔴",
+		Clients: []models.Client{
+			{UUID: "a", Name: "node-a", Token: "secret", IPv4: "192.0.2.1", Tags: "hk;cn2", Price: 5},
+			{UUID: "b"},
+		},
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"旧版占位符", "{{emoji}} {{event}}: {{client}} {{message}} {{time}}", "🔴 Offline: node-a, b down 2025-01-02T03:04:05Z"},
+		{"客户端字段与循环", "{{range .Clients}}{{if .Name}}{{.Name}} {{.IPv4}}{{else}}{{.UUID}}{{end}};{{end}}", "node-a 192.0.2.1;b;"},
+		{"标签与价格", `{{with index .Clients 0}}{{join (tags .Tags) ","}} {{printf "%.1f" .Price}}{{end}}`, "hk,cn2 5.0"},
+		{"时间格式化", `{{formatTime .Time "2006/01/02"}}`, "2025/01/02"},
+		{"Token 不可见", "{{(index .Clients 0).Token}}", ""},
+		{"HTML 转义", `{{html "<b>"}}`, "&lt;b&gt;"},
+		{"Markdown 转义", `{{escapeMarkdown "a.b"}}`, `a\.b`},
+		{"字节格式化", "{{bytes 1536}}", "1.50 KiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.template, event, "telegram")
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	if _, err := RenderTemplate("{{.Unknown", models.EventMessage{}, ""); err == nil {
+		t.Error("expected parse error")
+	}
+	huge := "{{range .Clients}}" + strings.Repeat("x", maxRenderedSize) + "{{end}}"
+	_, err := RenderTemplate(huge, models.EventMessage{Clients: []models.Client{{UUID: "a"}, {UUID: "b"}}}, "")
+	if !errors.Is(err, ErrTemplateTooLarge) {
+		t.Errorf("expected ErrTemplateTooLarge, got %v", err)
+	}
+}
@@ -5,13 +5,24 @@ import (
 )
 
 type Addition struct {
-	URL         string `json:"url" required:"true"`
-	Method      string `json:"method" default:"GET" type:"option" options:"POST,GET"`
-	ContentType string `json:"content_type" default:"application/json"`
-	Headers     string `json:"headers" help:"HTTP headers in JSON format"`
-	Body        string `json:"body" default:"{\"message\":\"{{message}}\",\"title\":\"{{title}}\"}"` // 默认使用message和title字段
-	Username    string `json:"username"`
-	Password    string `json:"password"`
+	URL            string `json:"url" required:"true" help:"Webhook URL. For GET requests template fields are URL-encoded"`
+	Method         string `json:"method" default:"GET" type:"option" options:"POST,GET,PUT,PATCH"`
+	ContentType    string `json:"content_type" default:"application/json"`
+	BodyFormat     string `json:"body_format" default:"auto" type:"option" options:"auto,json,form,raw" help:"How {{message}}, {{title}} and escape are escaped. auto decides by content type"`
+	Headers        string `json:"headers" help:"HTTP headers in JSON format"`
+	Body           string `json:"body" default:"{\"message\":\"{{message}}\",\"title\":\"{{title}}\"}" help:"Go template. Available: {{message}} {{title}} {{timestamp}} {{sign}}, fields .Event .Text .Emoji .Time .Clients, and functions escape, json"` // 默认使用message和title字段
+	AuthType       string `json:"auth_type" default:"none" type:"option" options:"none,basic,bearer"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	Token          string `json:"token" help:"Bearer token, used when auth type is bearer"`
+	SignMethod     string `json:"sign_method" default:"none" type:"option" options:"none,hmac-sha256,dingtalk,feishu" help:"hmac-sha256 signs timestamp.body into the signature header; dingtalk appends timestamp and sign to the URL; feishu exposes {{timestamp}} and {{sign}} to the body"`
+	SignSecret     string `json:"sign_secret"`
+	SignHeader     string `json:"sign_header" default:"X-Signature" help:"Header carrying the hmac-sha256 signature, the timestamp is sent in X-Timestamp"`
+	Timeout        int    `json:"timeout" default:"30" help:"Request timeout in seconds"`
+	SkipTLSVerify  bool   `json:"skip_tls_verify" default:"false" help:"Do not verify the server certificate (insecure)"`
+	CACert         string `json:"ca_cert" help:"Optional. PEM encoded CA certificate trusted in addition to the system pool"`
+	ExpectedStatus string `json:"expected_status" help:"Comma-separated status codes treated as success, e.g. 200,204. Empty means any 2xx"`
+	ResponseRegex  string `json:"response_regex" help:"Optional. Regular expression the response body must match, e.g. \"errcode\":0"`
 }
 
 func init() {
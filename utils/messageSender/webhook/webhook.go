@@ -2,19 +2,45 @@ package webhook
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/komari-monitor/komari/database/models"
 	"github.com/komari-monitor/komari/utils/messageSender/factory"
 )
 
+// 校验响应时最多读取的响应体大小
+const maxResponseBody = 64 * 1024
+
 type WebhookSender struct {
 	Addition
+	client        *http.Client
+	responseRegex *regexp.Regexp
+}
+
+// payloadData 请求体与 URL 模板可访问的数据
+type payloadData struct {
+	Title   string // 事件名称
+	Message string // 按通知模板渲染后的消息
+	Text    string // 事件原始消息
+	Event   string
+	Emoji   string
+	Time    time.Time
+	Clients []models.Client
 }
 
 func (w *WebhookSender) GetName() string {
@@ -26,6 +52,33 @@ func (w *WebhookSender) GetConfiguration() factory.Configuration {
 }
 
 func (w *WebhookSender) Init() error {
+	timeout := time.Duration(w.Addition.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	tlsCfg := &tls.Config{InsecureSkipVerify: w.Addition.SkipTLSVerify}
+	if strings.TrimSpace(w.Addition.CACert) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(w.Addition.CACert)) {
+			return fmt.Errorf("invalid CA certificate")
+		}
+		tlsCfg.RootCAs = pool
+	}
+	var responseRegex *regexp.Regexp
+	if w.Addition.ResponseRegex != "" {
+		re, err := regexp.Compile(w.Addition.ResponseRegex)
+		if err != nil {
+			return fmt.Errorf("invalid response regex: %v", err)
+		}
+		responseRegex = re
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	w.client = &http.Client{Timeout: timeout, Transport: transport}
+	w.responseRegex = responseRegex
 	return nil
 }
 
@@ -34,27 +87,61 @@ func (w *WebhookSender) Destroy() error {
 }
 
 func (w *WebhookSender) SendTextMessage(message, title string) error {
+	return w.send(payloadData{
+		Title:   title,
+		Message: message,
+		Text:    message,
+		Event:   title,
+		Time:    time.Now(),
+	})
+}
+
+// SendEventMessage 让请求体模板可以使用事件的结构化字段
+func (w *WebhookSender) SendEventMessage(event models.EventMessage, message string) error {
+	clients := make([]models.Client, len(event.Clients))
+	for i, c := range event.Clients {
+		c.Token = ""
+		clients[i] = c
+	}
+	eventTime := event.Time
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+	return w.send(payloadData{
+		Title:   event.Event,
+		Message: message,
+		Text:    event.Message,
+		Event:   event.Event,
+		Emoji:   event.Emoji,
+		Time:    eventTime,
+		Clients: clients,
+	})
+}
+
+func (w *WebhookSender) send(data payloadData) error {
 	if w.Addition.URL == "" {
 		return fmt.Errorf("webhook URL is not configured")
 	}
+	if w.client == nil {
+		if err := w.Init(); err != nil {
+			return err
+		}
+	}
 
 	method := strings.ToUpper(w.Addition.Method)
 	if method == "" {
 		method = "GET" // 默认使用 GET
 	}
-
-	client := &http.Client{
-		Timeout: 30 * time.Second, // 固定30秒超时
-	}
+	ts, sign := w.signature(time.Now())
 
 	var req *http.Request
 	var err error
 
 	switch method {
-	case "POST":
-		req, err = w.createPOSTRequest(message, title)
+	case "POST", "PUT", "PATCH":
+		req, err = w.createBodyRequest(method, data, ts, sign)
 	case "GET":
-		req, err = w.createGETRequest(message, title)
+		req, err = w.createGETRequest(data, ts, sign)
 	default:
 		return fmt.Errorf("unsupported HTTP method: %s", method)
 	}
@@ -66,57 +153,134 @@ func (w *WebhookSender) SendTextMessage(message, title string) error {
 	// 解析并设置自定义头部
 	if w.Addition.Headers != "" {
 		var headers map[string]string
-		if err := json.Unmarshal([]byte(w.Addition.Headers), &headers); err == nil {
-			for key, value := range headers {
-				req.Header.Set(key, value)
-			}
+		if err := json.Unmarshal([]byte(w.Addition.Headers), &headers); err != nil {
+			return fmt.Errorf("invalid headers: %v", err)
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
 		}
 	}
 
-	// 设置基本认证
-	if w.Addition.Username != "" && w.Addition.Password != "" {
-		req.SetBasicAuth(w.Addition.Username, w.Addition.Password)
-	}
+	w.setAuth(req)
 
-	resp, err := client.Do(req)
+	resp, err := w.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send webhook request: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("webhook request failed with status %d: %s", resp.StatusCode, string(body))
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return w.checkResponse(resp.StatusCode, body)
+}
+
+func (w *WebhookSender) setAuth(req *http.Request) {
+	switch w.Addition.AuthType {
+	case "bearer":
+		if w.Addition.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+w.Addition.Token)
+		}
+	case "basic":
+		req.SetBasicAuth(w.Addition.Username, w.Addition.Password)
+	case "", "none":
+		// 兼容旧配置：未设置认证类型但填写了用户名密码时使用基本认证
+		if w.Addition.AuthType == "" && w.Addition.Username != "" && w.Addition.Password != "" {
+			req.SetBasicAuth(w.Addition.Username, w.Addition.Password)
+		}
 	}
+}
 
+// checkResponse 未配置期望状态码时任意 2xx 视为成功，配置正则时响应体还需匹配
+func (w *WebhookSender) checkResponse(status int, body []byte) error {
+	if !w.statusAccepted(status) {
+		return fmt.Errorf("webhook request failed with status %d: %s", status, string(body))
+	}
+	if w.responseRegex != nil && !w.responseRegex.Match(body) {
+		return fmt.Errorf("webhook response does not match %q: %s", w.Addition.ResponseRegex, string(body))
+	}
 	return nil
 }
 
-func (w *WebhookSender) createPOSTRequest(message, title string) (*http.Request, error) {
+func (w *WebhookSender) statusAccepted(status int) bool {
+	expected := strings.TrimSpace(w.Addition.ExpectedStatus)
+	if expected == "" {
+		return status >= 200 && status < 300
+	}
+	for _, s := range strings.Split(expected, ",") {
+		if code, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// signature 按签名方式生成时间戳与签名，hmac-sha256 的签名依赖请求体，在创建请求时计算
+func (w *WebhookSender) signature(now time.Time) (string, string) {
+	secret := w.Addition.SignSecret
+	switch w.Addition.SignMethod {
+	case "dingtalk":
+		// 钉钉：毫秒时间戳，HMAC-SHA256(secret, timestamp + "\n" + secret) 后 Base64
+		ts := strconv.FormatInt(now.UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(ts + "\n" + secret))
+		return ts, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	case "feishu":
+		// 飞书：秒级时间戳，以 timestamp + "\n" + secret 为密钥对空串做 HMAC-SHA256 后 Base64
+		ts := strconv.FormatInt(now.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(ts+"\n"+secret))
+		return ts, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	return strconv.FormatInt(now.Unix(), 10), ""
+}
+
+func (w *WebhookSender) bodyFormat(contentType string) string {
+	switch w.Addition.BodyFormat {
+	case "json", "form", "raw":
+		return w.Addition.BodyFormat
+	}
+	ct := strings.ToLower(contentType)
+	switch {
+	case isJSONContentType(ct):
+		return "json"
+	case strings.Contains(ct, "application/x-www-form-urlencoded"):
+		return "form"
+	}
+	return "raw"
+}
+
+func (w *WebhookSender) createBodyRequest(method string, data payloadData, ts, sign string) (*http.Request, error) {
 	contentType := w.Addition.ContentType
 	if contentType == "" {
 		contentType = "application/json"
 	}
 
-	// 用户自定义模板，按 Content-Type 决定如何替换占位符
-	var body string
-	if isJSONContentType(contentType) {
-		body = w.replaceTemplateJSON(w.Addition.Body, message, title)
-	} else {
-		body = w.replaceTemplate(w.Addition.Body, message, title)
+	// 用户自定义模板，按格式决定如何转义占位符
+	body, err := renderTemplate(w.Addition.Body, data, escaper(w.bodyFormat(contentType)), ts, sign)
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", w.Addition.URL, bytes.NewBufferString(body))
+	target := w.Addition.URL
+	if w.Addition.SignMethod == "dingtalk" {
+		target = appendQuery(target, ts, sign)
+	}
+	req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", contentType)
+	w.signRequest(req, ts, body)
 	return req, nil
 }
 
-func (w *WebhookSender) createGETRequest(message, title string) (*http.Request, error) {
-	URL := w.replaceTemplate(w.Addition.URL, message, title)
+func (w *WebhookSender) createGETRequest(data payloadData, ts, sign string) (*http.Request, error) {
+	URL, err := renderTemplate(w.Addition.URL, data, url.QueryEscape, ts, sign)
+	if err != nil {
+		return nil, err
+	}
+	if w.Addition.SignMethod == "dingtalk" {
+		URL = appendQuery(URL, ts, sign)
+	}
 	u, err := url.Parse(URL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %v", err)
@@ -126,24 +290,75 @@ func (w *WebhookSender) createGETRequest(message, title string) (*http.Request,
 	if err != nil {
 		return nil, err
 	}
+	w.signRequest(req, ts, "")
 
 	return req, nil
 }
 
+// signRequest hmac-sha256 方式下对 "timestamp.body" 签名，接收方可据此校验来源并拒绝重放
+func (w *WebhookSender) signRequest(req *http.Request, ts, body string) {
+	if w.Addition.SignMethod != "hmac-sha256" {
+		return
+	}
+	header := w.Addition.SignHeader
+	if header == "" {
+		header = "X-Signature"
+	}
+	mac := hmac.New(sha256.New, []byte(w.Addition.SignSecret))
+	mac.Write([]byte(ts + "." + body))
+	req.Header.Set(header, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-Timestamp", ts)
+}
+
+// renderTemplate 使用 text/template 渲染，解析失败时回退为旧版的 {{message}} / {{title}} 直接替换
+func renderTemplate(tmpl string, data payloadData, escape func(string) string, ts, sign string) (string, error) {
+	funcs := template.FuncMap{
+		"message":   func() string { return escape(data.Message) },
+		"title":     func() string { return escape(data.Title) },
+		"timestamp": func() string { return ts },
+		"sign":      func() string { return escape(sign) },
+		"escape":    func(v any) string { return escape(fmt.Sprint(v)) },
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join": strings.Join,
+	}
+	t, err := template.New("webhook").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return replaceTemplate(tmpl, escape(data.Message), escape(data.Title)), nil
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.String(), nil
+}
+
 // replaceTemplate 替换模板中的 {{message}} 和 {{title}} 占位符（不做转义）
-func (w *WebhookSender) replaceTemplate(template, message, title string) string {
+func replaceTemplate(template, message, title string) string {
 	result := template
 	result = strings.ReplaceAll(result, "{{message}}", message)
 	result = strings.ReplaceAll(result, "{{title}}", title)
 	return result
 }
 
-// replaceTemplateJSON 在 JSON 场景下替换占位符，使用 \uXXXX 形式进行转义（尤其换行/控制字符）
-func (w *WebhookSender) replaceTemplateJSON(template, message, title string) string {
-	result := template
-	result = strings.ReplaceAll(result, "{{message}}", jsonUnicodeEscapeString(message))
-	result = strings.ReplaceAll(result, "{{title}}", jsonUnicodeEscapeString(title))
-	return result
+func appendQuery(rawURL, ts, sign string) string {
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + "timestamp=" + ts + "&sign=" + url.QueryEscape(sign)
+}
+
+func escaper(format string) func(string) string {
+	switch format {
+	case "json":
+		return jsonUnicodeEscapeString
+	case "form":
+		return url.QueryEscape
+	}
+	return func(s string) string { return s }
 }
 
 // isJSONContentType 判断是否为 JSON 内容类型
@@ -183,3 +398,7 @@ func jsonUnicodeEscapeString(s string) string {
 	}
 	return b.String()
 }
+
+// 确保实现了 IMessageSender 接口
+var _ factory.IMessageSender = (*WebhookSender)(nil)
+var _ factory.IEventMessageSender = (*WebhookSender)(nil)
@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+type captured struct {
+	header http.Header
+	query  url.Values
+	body   string
+}
+
+func stub(t *testing.T, tlsServer bool, status int, response string) (*httptest.Server, *captured) {
+	got := &captured{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got.header, got.query, got.body = r.Header.Clone(), r.URL.Query(), string(b)
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	})
+	var srv *httptest.Server
+	if tlsServer {
+		srv = httptest.NewTLSServer(handler)
+	} else {
+		srv = httptest.NewServer(handler)
+	}
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func newSender(t *testing.T, a Addition) *WebhookSender {
+	t.Helper()
+	w := &WebhookSender{Addition: a}
+	if err := w.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return w
+}
+
+func TestStructuredBodyAndHMAC(t *testing.T) {
+	srv, got := stub(t, false, 200, "ok")
+	w := newSender(t, Addition{
+		URL:        srv.URL,
+		Method:     "POST",
+		Body:       `{"text":"{{message}}","event":{{json .Event}},"clients":[{{range $i, $c := .Clients}}{{if $i}},{{end}}{{json $c.Name}}{{end}}]}`,
+		SignMethod: "hmac-sha256",
+		SignSecret: "s3cret",
+		AuthType:   "bearer",
+		Token:      "tk",
+	})
+	err := w.SendEventMessage(models.EventMessage{
+		Event:   "Offline",
+		Time:    time.Now(),
+		Clients: []models.Client{{UUID: "a", Name: "node \"a\"", Token: "hidden"}},
+	}, "line1\nline2")
+	if err != nil {
+		t.Fatalf("SendEventMessage() error = %v", err)
+	}
+	var body struct {
+		Text    string   `json:"text"`
+		Event   string   `json:"event"`
+		Clients []string `json:"clients"`
+	}
+	if err := json.Unmarshal([]byte(got.body), &body); err != nil {
+		t.Fatalf("body is not valid JSON: %v, %s", err, got.body)
+	}
+	if body.Text != "line1\nline2" || body.Event != "Offline" || len(body.Clients) != 1 || body.Clients[0] != `node "a"` {
+		t.Errorf("unexpected body: %+v", body)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(got.header.Get("X-Timestamp") + "." + got.body))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got.header.Get("X-Signature") != want {
+		t.Errorf("signature = %q, want %q", got.header.Get("X-Signature"), want)
+	}
+	if got.header.Get("Authorization") != "Bearer tk" {
+		t.Errorf("missing bearer token")
+	}
+}
+
+func TestLegacyConfig(t *testing.T) {
+	srv, got := stub(t, false, 200, "")
+	w := newSender(t, Addition{URL: srv.URL + "/?msg={{message}}", Method: "GET", Username: "u", Password: "p"})
+	if err := w.SendTextMessage("a b&c", "Test"); err != nil {
+		t.Fatal(err)
+	}
+	if got.query.Get("msg") != "a b&c" {
+		t.Errorf("query msg = %q", got.query.Get("msg"))
+	}
+	if u, p, ok := (&http.Request{Header: got.header}).BasicAuth(); !ok || u != "u" || p != "p" {
+		t.Errorf("legacy basic auth not applied")
+	}
+}
+
+func TestDingTalkSign(t *testing.T) {
+	srv, got := stub(t, false, 200, `{"errcode":0}`)
+	w := newSender(t, Addition{URL: srv.URL + "/robot/send?access_token=x", Method: "POST", Body: `{"msgtype":"text","text":{"content":"{{message}}"}}`,
+		SignMethod: "dingtalk", SignSecret: "SEC", ResponseRegex: `"errcode":0\b`})
+	if err := w.SendTextMessage("hi", "Test"); err != nil {
+		t.Fatal(err)
+	}
+	if got.query.Get("access_token") != "x" || got.query.Get("timestamp") == "" || got.query.Get("sign") == "" {
+		t.Errorf("unexpected query: %v", got.query)
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		expected string
+		regex    string
+		wantErr  bool
+	}{
+		{"默认 2xx", 204, "", "", "", false},
+		{"非 2xx", 500, "", "", "", true},
+		{"期望状态码", 202, "", "200,201", "", true},
+		{"正则匹配", 200, `{"code":0}`, "", `"code":0`, false},
+		{"正则不匹配", 200, `{"code":19001}`, "", `"code":0\b`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := stub(t, false, tt.status, tt.response)
+			w := newSender(t, Addition{URL: srv.URL, Method: "POST", ExpectedStatus: tt.expected, ResponseRegex: tt.regex})
+			if err := w.SendTextMessage("hi", "Test"); (err != nil) != tt.wantErr {
+				t.Errorf("SendTextMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomCA(t *testing.T) {
+	srv, _ := stub(t, true, 200, "")
+	if err := newSender(t, Addition{URL: srv.URL, Method: "POST"}).SendTextMessage("hi", "Test"); err == nil {
+		t.Error("expected certificate verification error")
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := newSender(t, Addition{URL: srv.URL, Method: "POST", CACert: string(caPEM)}).SendTextMessage("hi", "Test"); err != nil {
+		t.Errorf("custom CA should be trusted: %v", err)
+	}
+	if err := newSender(t, Addition{URL: srv.URL, Method: "POST", SkipTLSVerify: true}).SendTextMessage("hi", "Test"); err != nil {
+		t.Errorf("skip verify should succeed: %v", err)
+	}
+}
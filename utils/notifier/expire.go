@@ -2,91 +2,242 @@ package notifier
 
 import (
 	"fmt"
+	"log"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/dbcore"
 	"github.com/komari-monitor/komari/database/models"
 	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/utils/actions"
+	"github.com/komari-monitor/komari/utils/i18n"
 	"github.com/komari-monitor/komari/utils/messageSender"
 	"github.com/komari-monitor/komari/utils/renewal"
 )
 
+// CheckExpireScheduledWork 每天在配置的时间检查到期提醒与自动续费。
+// 启动时若已过当天的检查时间会补做一次提醒，已发送的步骤记录在数据库中，不会重复。
 func CheckExpireScheduledWork() {
-	for {
-		now := time.Now()
-		next := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location()) // UTC 9AM = CST 17PM
-		if now.After(next) {
-			next = next.Add(24 * time.Hour)
+	lastRun := ""
+	if cfg, err := config.Get(); err == nil {
+		now := time.Now().In(models.GetAppLocation())
+		if h, m, err := ParseCheckTime(cfg.ExpireNotificationTime); err == nil && now.Hour()*60+now.Minute() >= h*60+m {
+			checkExpireNotifications(cfg, now)
+			lastRun = now.Format("2006-01-02")
 		}
-		duration := next.Sub(now)
-		time.Sleep(duration)
-
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
 		cfg, err := config.Get()
 		if err != nil {
-			time.Sleep(time.Second)
 			continue
 		}
+		now := time.Now().In(models.GetAppLocation())
+		h, m, err := ParseCheckTime(cfg.ExpireNotificationTime)
+		if err != nil {
+			h, m = 9, 0
+		}
+		today := now.Format("2006-01-02")
+		if lastRun == today || now.Hour()*60+now.Minute() < h*60+m {
+			continue
+		}
+		lastRun = today
+
+		checkExpireNotifications(cfg, now)
 
 		clients_all, err := clients.GetAllClientBasicInfo()
 		if err != nil {
-			time.Sleep(time.Second)
 			continue
 		}
+		// 等待1秒，防止多次触发
+		time.Sleep(time.Second)
+		for _, client := range clients_all {
+			renewal.CheckAndAutoRenewal(client)
+		}
+	}
+}
 
-		checkTime := time.Now()
-
-		// 过期提醒检查（仅当启用过期通知时）
-		if cfg.ExpireNotificationEnabled {
-			notificationLeadDays := cfg.ExpireNotificationLeadDays
+// ParseCheckTime 解析 HH:MM 格式的时间
+func ParseCheckTime(s string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
 
-			type clientToExpireInfo struct {
-				Name     string
-				DaysLeft int
-			}
+// ParseLeadDays 解析逗号分隔的提前天数，去重后从大到小排序
+func ParseLeadDays(s string) ([]int, error) {
+	seen := map[int]bool{}
+	var steps []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid lead days %q", part)
+		}
+		if !seen[n] {
+			seen[n] = true
+			steps = append(steps, n)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(steps)))
+	return steps, nil
+}
 
-			var clientLeadToExpire []clientToExpireInfo
+// dueExpireStep 返回当前应提醒的步骤，即不小于剩余天数的最小步骤
+func dueExpireStep(expire, now time.Time, steps []int) (step, daysLeft int, ok bool) {
+	if !expire.After(now) {
+		return 0, 0, false
+	}
+	daysLeft = int(math.Ceil(expire.Sub(now).Hours() / 24))
+	for i := len(steps) - 1; i >= 0; i-- {
+		if daysLeft <= steps[i] {
+			return steps[i], daysLeft, true
+		}
+	}
+	return 0, daysLeft, false
+}
 
-			for _, client := range clients_all {
-				clientExpireTime := client.ExpiredAt.ToTime()
+// globalLeadDays 未配置步骤时回退到单一的提前天数
+func globalLeadDays(cfg models.Config) []int {
+	if steps, err := ParseLeadDays(cfg.ExpireNotificationSteps); err == nil && len(steps) > 0 {
+		return steps
+	}
+	if cfg.ExpireNotificationLeadDays > 0 {
+		return []int{cfg.ExpireNotificationLeadDays}
+	}
+	return nil
+}
 
-				if clientExpireTime.Before(checkTime) {
-					continue
-				}
+// claimExpireStep 登记某次到期的提醒步骤，已登记过时返回 false
+func claimExpireStep(clientUuid string, expiredAt models.LocalTime, step int) bool {
+	db := dbcore.GetDBInstance()
+	var count int64
+	db.Model(&models.ExpireNotificationState{}).
+		Where("client = ? AND expired_at = ? AND step = ?", clientUuid, expiredAt, step).Count(&count)
+	if count > 0 {
+		return false
+	}
+	// 续费后旧到期时间的记录不再需要
+	db.Where("client = ? AND expired_at <> ?", clientUuid, expiredAt).Delete(&models.ExpireNotificationState{})
+	err := db.Create(&models.ExpireNotificationState{Client: clientUuid, ExpiredAt: expiredAt, Step: step}).Error
+	return err == nil
+}
 
-				notificationThreshold := checkTime.Add(time.Duration(notificationLeadDays) * 24 * time.Hour)
+// releaseExpireStep 撤销已登记的提醒步骤，发送失败时调用以便下次重试
+func releaseExpireStep(clientUuid string, expiredAt models.LocalTime, step int) {
+	dbcore.GetDBInstance().
+		Where("client = ? AND expired_at = ? AND step = ?", clientUuid, expiredAt, step).
+		Delete(&models.ExpireNotificationState{})
+}
 
-				if clientExpireTime.Before(notificationThreshold) || clientExpireTime.Equal(notificationThreshold) {
-					remainingDuration := clientExpireTime.Sub(checkTime)
-					daysLeft := int(math.Ceil(remainingDuration.Hours() / 24))
+func checkExpireNotifications(cfg models.Config, checkTime time.Time) {
+	if !cfg.ExpireNotificationEnabled {
+		return
+	}
+	clients_all, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return
+	}
+	var overrides []models.ExpireNotification
+	dbcore.GetDBInstance().Find(&overrides)
+	overrideMap := make(map[string]models.ExpireNotification, len(overrides))
+	for _, o := range overrides {
+		overrideMap[o.Client] = o
+	}
+	defaultSteps := globalLeadDays(cfg)
 
-					clientLeadToExpire = append(clientLeadToExpire, clientToExpireInfo{
-						Name:     client.Name,
-						DaysLeft: daysLeft,
-					})
-				}
+	type claimedStep struct {
+		client models.Client
+		step   int
+	}
+	var claimed []claimedStep
+	var message, escalated string
+	var escalatedClients []models.Client
+	for _, client := range clients_all {
+		steps := defaultSteps
+		if o, ok := overrideMap[client.UUID]; ok {
+			if o.Disable {
+				continue
 			}
-
-			if len(clientLeadToExpire) > 0 {
-				message := ""
-				for _, clientInfo := range clientLeadToExpire {
-					message += fmt.Sprintf("• %s (%dd)\n", clientInfo.Name, clientInfo.DaysLeft)
-				}
-				messageSender.SendEvent(models.EventMessage{
-					Event:   messageevent.Expire,
-					Time:    time.Now(),
-					Message: message,
-					Emoji:   "⏳",
-				})
+			if custom, err := ParseLeadDays(o.LeadDays); err == nil && len(custom) > 0 {
+				steps = custom
 			}
 		}
+		step, daysLeft, ok := dueExpireStep(client.ExpiredAt.ToTime(), checkTime, steps)
+		if !ok || !claimExpireStep(client.UUID, client.ExpiredAt, step) {
+			continue
+		}
+		claimed = append(claimed, claimedStep{client: client, step: step})
+		line := i18n.T(cfg.NotificationLocale, "notify.expire_line", client.Name, daysLeft) + "\n"
+		message += line
+		if cfg.ExpireEscalationChannel != "" && daysLeft <= cfg.ExpireEscalationDays {
+			escalated += line
+			escalatedClients = append(escalatedClients, client)
+		}
+	}
 
-		// 等待1秒，防止多次触发
-		time.Sleep(time.Second)
-		for _, client := range clients_all {
-			renewal.CheckAndAutoRenewal(client)
+	if message != "" {
+		if err := messageSender.SendEvent(models.EventMessage{
+			Event:   messageevent.Expire,
+			Time:    time.Now(),
+			Message: message,
+			Emoji:   "⏳",
+		}); err != nil {
+			log.Printf("Failed to send expire notification: %v", err)
+			for _, c := range claimed {
+				releaseExpireStep(c.client.UUID, c.client.ExpiredAt, c.step)
+			}
+			return
+		}
+	}
+	if escalated != "" {
+		if err := messageSender.SendEventVia(cfg.ExpireEscalationChannel, models.EventMessage{
+			Event:   messageevent.Expire,
+			Clients: escalatedClients,
+			Time:    time.Now(),
+			Message: escalated,
+			Emoji:   "🚨",
+		}); err != nil {
+			log.Printf("Failed to send escalated expire notification: %v", err)
 		}
 	}
+}
 
+// expireActionLookback 到期动作的回溯时长，超过该时长的到期不再触发，避免首次运行时对早已过期的客户端执行动作
+const expireActionLookback = 24 * time.Hour
+
+// CheckExpiredActions 对已过期的客户端执行自动动作，由外部每分钟调用一次。
+// 开启自动续费的客户端会在到期时续费，不执行动作。
+func CheckExpiredActions() {
+	clients_all, err := clients.GetAllClientBasicInfo()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, client := range clients_all {
+		expire := client.ExpiredAt.ToTime()
+		// 未设置到期时间（早于 0002 年）或尚未到期
+		if expire.Year() < 2 || expire.After(now) {
+			continue
+		}
+		if now.Sub(expire) > expireActionLookback {
+			continue
+		}
+		if client.AutoRenewal && client.BillingCycle > 0 {
+			continue
+		}
+		// 以到期时间区分每次到期，续费后再次到期会重新执行
+		actions.Fire(actions.TriggerExpire, client, expire.Format("2006-01-02 15:04"), "expired at "+expire.Format("2006-01-02 15:04"))
+	}
 }
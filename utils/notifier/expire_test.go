@@ -0,0 +1,51 @@
+package notifier
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLeadDays(t *testing.T) {
+	got, err := ParseLeadDays(" 1, 7,30,3,7 ")
+	if err != nil || !reflect.DeepEqual(got, []int{30, 7, 3, 1}) {
+		t.Errorf("ParseLeadDays() = %v, %v", got, err)
+	}
+	for _, bad := range []string{"7,x", "0", "-3"} {
+		if _, err := ParseLeadDays(bad); err == nil {
+			t.Errorf("ParseLeadDays(%q) should fail", bad)
+		}
+	}
+	if h, m, err := ParseCheckTime("17:30"); err != nil || h != 17 || m != 30 {
+		t.Errorf("ParseCheckTime() = %d, %d, %v", h, m, err)
+	}
+	if _, _, err := ParseCheckTime("25:00"); err == nil {
+		t.Error("ParseCheckTime should reject invalid time")
+	}
+}
+
+func TestDueExpireStep(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	steps := []int{30, 7, 3, 1}
+	tests := []struct {
+		name     string
+		expire   time.Time
+		wantStep int
+		wantOK   bool
+	}{
+		{"尚未进入提醒", now.AddDate(0, 0, 45), 0, false},
+		{"30 天", now.AddDate(0, 0, 20), 30, true},
+		{"7 天", now.AddDate(0, 0, 7), 7, true},
+		{"错过的步骤取最近一档", now.AddDate(0, 0, 2), 3, true},
+		{"当天到期", now.Add(5 * time.Hour), 1, true},
+		{"已过期", now.Add(-time.Hour), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _, ok := dueExpireStep(tt.expire, now, steps)
+			if step != tt.wantStep || ok != tt.wantOK {
+				t.Errorf("dueExpireStep() = %d, %v; want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
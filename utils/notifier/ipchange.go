@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/utils/geoip"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/messageSender"
+)
+
+// ipChange 描述某个地址族的 IP 变化
+type ipChange struct {
+	family   string
+	old, new models.ClientIPHistory
+}
+
+// geoChanged 国家或 ASN 发生变化。两边均无地理信息时视为未变化
+func (c ipChange) geoChanged() bool {
+	return c.old.Region != c.new.Region || c.old.ASN != c.new.ASN
+}
+
+// RecordClientIP 记录客户端上报的 IP 并在地址变化时发送通知。
+// previous 为更新前的客户端信息，新客户端传 nil
+func RecordClientIP(clientUuid string, previous *models.Client, ipv4, ipv6 string) {
+	cfg, err := config.Get()
+	if err != nil {
+		return
+	}
+	var changes []ipChange
+	for _, item := range []struct{ family, oldIP, newIP string }{
+		{"ipv4", previousIP(previous, false), strings.TrimSpace(ipv4)},
+		{"ipv6", previousIP(previous, true), strings.TrimSpace(ipv6)},
+	} {
+		if net.ParseIP(item.newIP) == nil {
+			continue
+		}
+		record := lookupIPRecord(cfg.GeoIpEnabled, clientUuid, item.family, item.newIP)
+		if _, err := clients.RecordClientIP(record); err != nil {
+			log.Printf("Failed to record IP history for client %s: %v", clientUuid, err)
+		}
+		if item.oldIP == "" || item.oldIP == item.newIP {
+			continue
+		}
+		old, err := clients.GetClientIPRecord(clientUuid, item.oldIP)
+		if err != nil {
+			old = lookupIPRecord(cfg.GeoIpEnabled, clientUuid, item.family, item.oldIP)
+		}
+		changes = append(changes, ipChange{family: item.family, old: old, new: record})
+	}
+
+	if !cfg.IPChangeNotification || len(changes) == 0 || previous == nil {
+		return
+	}
+	var lines []string
+	for _, change := range changes {
+		if cfg.IPChangeNotifyOnlyGeoChange && !change.geoChanged() {
+			continue
+		}
+		lines = append(lines, i18n.T(cfg.NotificationLocale, "notify.ip_change_line", strings.ToUpper(change.family[:2])+change.family[2:], describeIP(change.old), describeIP(change.new)))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	client := *previous
+	client.IPv4, client.IPv6 = ipv4, ipv6
+	go messageSender.SendEvent(models.EventMessage{
+		Event:   messageevent.IPChange,
+		Clients: []models.Client{client},
+		Time:    time.Now(),
+		Message: strings.Join(lines, "\n"),
+		Emoji:   "🔀",
+	})
+}
+
+func previousIP(previous *models.Client, v6 bool) string {
+	if previous == nil {
+		return ""
+	}
+	if v6 {
+		return previous.IPv6
+	}
+	return previous.IPv4
+}
+
+func lookupIPRecord(geoEnabled bool, clientUuid, family, ip string) models.ClientIPHistory {
+	record := models.ClientIPHistory{Client: clientUuid, Family: family, IP: ip}
+	if !geoEnabled {
+		return record
+	}
+	if info, _ := geoip.GetGeoInfo(net.ParseIP(ip)); info != nil {
+		record.Region = geoip.GetRegionUnicodeEmoji(info.ISOCode)
+		record.City = info.City
+		record.ASN = info.ASN
+		record.Organization = info.Organization
+	}
+	return record
+}
+
+// describeIP 例如 "1.2.3.4 (🇺🇸 Ashburn, AS15169 Google LLC)"
+func describeIP(r models.ClientIPHistory) string {
+	var parts []string
+	if loc := strings.TrimSpace(r.Region + " " + r.City); loc != "" {
+		parts = append(parts, loc)
+	}
+	if r.ASN != 0 {
+		parts = append(parts, strings.TrimSpace(fmt.Sprintf("AS%d %s", r.ASN, r.Organization)))
+	}
+	if len(parts) == 0 {
+		return r.IP
+	}
+	return r.IP + " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestIPChangeDescribe(t *testing.T) {
+	us := models.ClientIPHistory{IP: "1.2.3.4", Region: "🇺🇸", City: "Ashburn", ASN: 15169, Organization: "Google LLC"}
+	tests := []struct {
+		name       string
+		old, new   models.ClientIPHistory
+		geoChanged bool
+		want       string
+	}{
+		{"同 ASN 换 IP", us, models.ClientIPHistory{IP: "1.2.3.5", Region: "🇺🇸", ASN: 15169}, false, "1.2.3.5 (🇺🇸, AS15169)"},
+		{"ASN 变化", us, models.ClientIPHistory{IP: "5.6.7.8", Region: "🇺🇸", ASN: 13335, Organization: "CLOUDFLARENET"}, true, "5.6.7.8 (🇺🇸, AS13335 CLOUDFLARENET)"},
+		{"国家变化", us, models.ClientIPHistory{IP: "9.9.9.9", Region: "🇩🇪", ASN: 15169}, true, "9.9.9.9 (🇩🇪, AS15169)"},
+		{"无地理信息", models.ClientIPHistory{IP: "10.0.0.1"}, models.ClientIPHistory{IP: "10.0.0.2"}, false, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ipChange{family: "ipv4", old: tt.old, new: tt.new}
+			if got := c.geoChanged(); got != tt.geoChanged {
+				t.Errorf("geoChanged() = %v, want %v", got, tt.geoChanged)
+			}
+			if got := describeIP(tt.new); got != tt.want {
+				t.Errorf("describeIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := describeIP(us); got != "1.2.3.4 (🇺🇸 Ashburn, AS15169 Google LLC)" {
+		t.Errorf("describeIP() = %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package notifier
 import (
 	"log"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -80,6 +81,16 @@ func executeLoadNotificationTask(task models.LoadNotification) {
 	windowStart := now.Add(-time.Duration(task.Interval) * time.Minute)
 	overloadClients := make([]string, 0)
 	for _, clientUUID := range task.Clients {
+		if name, ok := strings.CutPrefix(task.Metric, CustomMetricPrefix); ok {
+			customRecords, err := records.GetCustomRecords(clientUUID, []string{name}, windowStart, now)
+			if err != nil {
+				continue
+			}
+			if checkCustomThreshold(customRecords, task) {
+				overloadClients = append(overloadClients, clientUUID)
+			}
+			continue
+		}
 		// 获取客户端在时间窗口内的记录
 		records, err := getRecordsForClient(clientUUID, windowStart, now)
 		if err != nil {
@@ -138,6 +149,30 @@ func checkMetricThreshold(records []models.Record, task models.LoadNotification)
 	return exceededCount >= minRequiredRecords
 }
 
+// CustomMetricPrefix 负载通知的指标以此开头时监控对应名称的自定义指标，例如 custom:queue_depth
+const CustomMetricPrefix = "custom:"
+
+// checkCustomThreshold 检查自定义指标是否达到阈值，阈值为指标的原始值
+func checkCustomThreshold(records []models.CustomRecord, task models.LoadNotification) bool {
+	if len(records) == 0 {
+		return false
+	}
+
+	minRequiredRecords := int(float32(len(records)) * task.Ratio)
+	if minRequiredRecords == 0 {
+		minRequiredRecords = 1
+	}
+
+	exceededCount := 0
+	for _, record := range records {
+		if record.Value >= float64(task.Threshold) {
+			exceededCount++
+		}
+	}
+
+	return exceededCount >= minRequiredRecords
+}
+
 // getMetricValue 根据指标名称获取记录中的对应值
 func getMetricValue(record models.Record, metric string) float32 {
 	client, err := clients.GetClientByUUID(record.Client) // 确保客户端信息已加载
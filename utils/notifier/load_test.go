@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestCheckCustomThreshold(t *testing.T) {
+	rec := func(v float64) models.CustomRecord { return models.CustomRecord{Value: v} }
+	task := models.LoadNotification{Metric: CustomMetricPrefix + "queue_depth", Threshold: 1000, Ratio: 0.5}
+	tests := []struct {
+		name    string
+		records []models.CustomRecord
+		want    bool
+	}{
+		{"无记录", nil, false},
+		{"未达阈值", []models.CustomRecord{rec(10), rec(999)}, false},
+		{"达标比例不足", []models.CustomRecord{rec(10), rec(20), rec(30), rec(1500)}, false},
+		{"达标比例足够", []models.CustomRecord{rec(1000), rec(20), rec(1500)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkCustomThreshold(tt.records, task); got != tt.want {
+				t.Errorf("checkCustomThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
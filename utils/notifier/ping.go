@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/komari-monitor/komari/database/clients"
+	"github.com/komari-monitor/komari/database/config"
+	"github.com/komari-monitor/komari/database/models"
+	messageevent "github.com/komari-monitor/komari/database/models/messageEvent"
+	"github.com/komari-monitor/komari/database/tasks"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
+	"github.com/komari-monitor/komari/utils/messageSender"
+)
+
+// minPingSamples 窗口内至少需要的探测次数，样本过少时不判断告警
+const minPingSamples = 3
+
+// pingAlertResult 单条规则的判断结果
+type pingAlertResult struct {
+	Metric    string // loss 或 p95
+	Firing    bool
+	Value     float64
+	Threshold float64
+}
+
+// evaluatePingAlerts 按任务的告警规则判断窗口统计，样本不足时返回 nil
+func evaluatePingAlerts(task models.PingTask, w utils.PingWindow) []pingAlertResult {
+	if w.Samples < minPingSamples {
+		return nil
+	}
+	var results []pingAlertResult
+	if task.AlertLoss > 0 {
+		results = append(results, pingAlertResult{"loss", w.Loss > task.AlertLoss, w.Loss, task.AlertLoss})
+	}
+	// 全部丢包时没有延迟数据，仅由丢包规则负责
+	if task.AlertP95 > 0 && w.Loss < 100 {
+		results = append(results, pingAlertResult{"p95", w.P95 > task.AlertP95, float64(w.P95), float64(task.AlertP95)})
+	}
+	return results
+}
+
+// CheckPingAlerts 检查各 Ping 任务的丢包率与 P95 延迟告警规则，由外部每分钟调用一次
+func CheckPingAlerts() {
+	cfg, err := config.Get()
+	if err != nil || !cfg.RecordEnabled {
+		return
+	}
+	list, err := tasks.GetAllPingTasks()
+	if err != nil {
+		return
+	}
+	// 告警状态保存在数据库中，key: taskId:client:metric
+	states, err := tasks.GetPingAlertStates()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, task := range list {
+		if task.AlertLoss <= 0 && task.AlertP95 <= 0 {
+			continue
+		}
+		window := task.AlertWindow
+		if window <= 0 {
+			window = 5
+		}
+		for _, uuid := range task.Clients {
+			records, err := tasks.GetPingRecords(uuid, int(task.Id), now.Add(-time.Duration(window)*time.Minute), now)
+			if err != nil {
+				continue
+			}
+			for _, r := range evaluatePingAlerts(task, utils.SummarizePing(records)) {
+				key := fmt.Sprintf("%d:%s:%s", task.Id, uuid, r.Metric)
+				if states[key] == r.Firing {
+					continue
+				}
+				// 发送失败时不更新状态，下次检查时重试
+				if err := sendPingAlert(task, uuid, window, r); err != nil {
+					log.Printf("Failed to send ping alert for task %d on %s: %v", task.Id, uuid, err)
+					continue
+				}
+				if err := tasks.SavePingAlertState(task.Id, uuid, r.Metric, r.Firing); err != nil {
+					log.Printf("Failed to save ping alert state: %v", err)
+				}
+			}
+		}
+	}
+}
+
+func sendPingAlert(task models.PingTask, uuid string, window int, r pingAlertResult) error {
+	client, err := clients.GetClientByUUID(uuid)
+	if err != nil {
+		return err
+	}
+	locale := ""
+	if cfg, err := config.Get(); err == nil {
+		locale = cfg.NotificationLocale
+	}
+	var desc string
+	if r.Metric == "loss" {
+		desc = i18n.T(locale, "notify.ping_loss", fmt.Sprintf("%.1f", r.Value), window, fmt.Sprintf("%.1f", r.Threshold))
+	} else {
+		desc = i18n.T(locale, "notify.ping_p95", fmt.Sprintf("%.0f", r.Value), window, fmt.Sprintf("%.0f", r.Threshold))
+	}
+	key, emoji := "notify.ping_recovered", "✅"
+	if r.Firing {
+		key, emoji = "notify.ping_alert", "📶"
+	}
+	return messageSender.SendEvent(models.EventMessage{
+		Event:   messageevent.Alert,
+		Clients: []models.Client{client},
+		Time:    time.Now(),
+		Message: i18n.T(locale, key, task.Name, task.Target, desc),
+		Emoji:   emoji,
+	})
+}
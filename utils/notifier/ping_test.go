@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+)
+
+func TestEvaluatePingAlerts(t *testing.T) {
+	rec := func(v int, loss float32) models.PingRecord { return models.PingRecord{Value: v, Loss: loss} }
+	task := models.PingTask{AlertLoss: 20, AlertP95: 100}
+	tests := []struct {
+		name    string
+		records []models.PingRecord
+		want    map[string]bool
+	}{
+		{"样本不足", []models.PingRecord{rec(-1, 0), rec(-1, 0)}, map[string]bool{}},
+		{"正常", []models.PingRecord{rec(10, 0), rec(20, 0), rec(30, 0)}, map[string]bool{"loss": false, "p95": false}},
+		{"丢包超限", []models.PingRecord{rec(10, 0), rec(-1, 0), rec(20, 50)}, map[string]bool{"loss": true, "p95": false}},
+		{"延迟超限", []models.PingRecord{rec(10, 0), rec(200, 0), rec(300, 0)}, map[string]bool{"loss": false, "p95": true}},
+		{"全部丢包只判断丢包", []models.PingRecord{rec(-1, 0), rec(-1, 0), rec(-1, 0)}, map[string]bool{"loss": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]bool{}
+			for _, r := range evaluatePingAlerts(task, utils.SummarizePing(tt.records)) {
+				got[r.Metric] = r.Firing
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s: got %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
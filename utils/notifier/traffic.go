@@ -9,24 +9,20 @@ import (
 	"github.com/komari-monitor/komari/database/clients"
 	"github.com/komari-monitor/komari/database/config"
 	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/database/traffic"
+	"github.com/komari-monitor/komari/utils/actions"
+	"github.com/komari-monitor/komari/utils/i18n"
 	"github.com/komari-monitor/komari/utils/messageSender"
-	"github.com/komari-monitor/komari/ws"
 	cache "github.com/patrickmn/go-cache"
 )
 
 // trafficCache 用于记录每个客户端已触发的阈值步进，避免重复提醒
-// key: "traffic:"+clientUUID, value: int 步进百分比（例如 80, 85, 90 ... 100）
+// key: "traffic:"+clientUUID+":"+周期起始日期, value: int 步进百分比（例如 80, 85, 90 ... 100）
 var trafficCache = cache.New(30*24*time.Hour, time.Hour) // 30天缓存，1小时清理
 
 // CheckTraffic 检查各客户端流量使用情况，并在达到阈值和每+5%时提醒一次；100%时额外提醒一次
 // 由外部协程每分钟调用一次
 func CheckTraffic() {
-	// 获取最新上报与客户端配置
-	reports := ws.GetLatestReport()
-	if len(reports) == 0 {
-		return
-	}
-
 	cfg, err := config.Get()
 	if err != nil {
 		return
@@ -56,18 +52,18 @@ func CheckTraffic() {
 			continue
 		}
 
-		r, ok := reports[c.UUID]
-		if !ok || r == nil {
-			continue
-		}
-
-		// 计算不同类型的使用值
-		used := computeUsedByType(strings.ToLower(c.TrafficLimitType), r.Network.TotalUp, r.Network.TotalDown)
+		// 使用服务端按计费周期积分的用量，不受 Agent 重启清零影响
+		cycle := traffic.Current(c.UUID)
+		used := cycle.Used(c.TrafficLimitType)
 		if used <= 0 {
 			continue
 		}
 
 		pct := float64(used) / float64(c.TrafficLimit) * 100.0
+		if pct >= 100 {
+			// 自动动作每个计费周期只执行一次
+			actions.Fire(actions.TriggerTraffic, c, cycle.CycleStart.ToTime().Format("2006-01-02"), fmt.Sprintf("traffic used %.1f%%", pct))
+		}
 		if pct < startThreshold {
 			continue
 		}
@@ -77,18 +73,16 @@ func CheckTraffic() {
 		if curStep < baseStep {
 			curStep = baseStep
 		}
-		// if curStep > 100 {
-		// 	curStep = 100
-		// }
 
-		key := "traffic:" + c.UUID
+		// 每个计费周期单独记录已提醒的步进，新周期重新提醒
+		key := "traffic:" + c.UUID + ":" + cycle.CycleStart.ToTime().Format("2006-01-02")
 		last, _ := trafficCache.Get(key)
 		lastStep, _ := last.(int)
 
 		if curStep > lastStep { // 只在进入新步进时提醒一次
 			trafficCache.SetDefault(key, curStep)
 
-			msg := fmt.Sprintf("used %d%% (%s / %s), type=%s", curStep, humanBytes(used), humanBytes(c.TrafficLimit), strings.ToLower(c.TrafficLimitType))
+			msg := i18n.T(cfg.NotificationLocale, "notify.traffic_used", curStep, humanBytes(used), humanBytes(c.TrafficLimit), strings.ToLower(c.TrafficLimitType), cycle.CycleStart.ToTime().Format("2006-01-02"))
 			// 发送通知（内部会检查 NotificationEnabled）
 			_ = messageSender.SendEvent(models.EventMessage{
 				Event:   "Traffic",
@@ -101,29 +95,6 @@ func CheckTraffic() {
 	}
 }
 
-func computeUsedByType(t string, up, down int64) int64 {
-	switch t {
-	case "up":
-		return up
-	case "down":
-		return down
-	case "sum":
-		return up + down
-	case "min":
-		if up < down {
-			return up
-		}
-		return down
-	case "max":
-		fallthrough
-	default:
-		if up > down {
-			return up
-		}
-		return down
-	}
-}
-
 func humanBytes(b int64) string {
 	const unit = 1024
 	if b < unit {
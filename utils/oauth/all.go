@@ -5,6 +5,7 @@ import (
 	_ "github.com/komari-monitor/komari/utils/oauth/factory"
 	_ "github.com/komari-monitor/komari/utils/oauth/generic"
 	_ "github.com/komari-monitor/komari/utils/oauth/github"
+	_ "github.com/komari-monitor/komari/utils/oauth/oidc"
 	_ "github.com/komari-monitor/komari/utils/oauth/qq"
 )
 
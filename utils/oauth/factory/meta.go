@@ -15,6 +15,9 @@ type IOidcProvider interface {
 
 type OidcCallback struct {
 	UserId string
+	// 以下字段可选，由支持的提供商填写
+	Username      string // 自动创建账号时使用的用户名
+	AutoProvision bool   // 未绑定时是否自动创建账号
 }
 
 type Configuration interface{}
@@ -0,0 +1,41 @@
+package oidc
+
+import (
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/komari-monitor/komari/utils/oauth/factory"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/oauth2"
+)
+
+func init() {
+	factory.RegisterOidcProvider(func() factory.IOidcProvider {
+		return &Oidc{}
+	})
+}
+
+type Oidc struct {
+	Addition
+	provider   *oidc.Provider
+	verifier   *oidc.IDTokenVerifier
+	oauth2     *oauth2.Config
+	stateCache *cache.Cache // state -> authSession
+}
+
+type Addition struct {
+	Issuer        string `json:"issuer" required:"true" help:"OpenID Provider issuer, /.well-known/openid-configuration will be discovered from it"`
+	ClientId      string `json:"client_id" required:"true"`
+	ClientSecret  string `json:"client_secret"`
+	Scope         string `json:"scope" default:"openid profile email" help:"Space separated, openid is always requested"`
+	DisablePKCE   bool   `json:"disable_pkce" help:"Only disable PKCE if the IdP does not support it"`
+	UsernameClaim string `json:"username_claim" default:"preferred_username"`
+	GroupsClaim   string `json:"groups_claim" default:"groups" help:"Claim that holds the user's groups or roles, e.g. groups or roles"`
+	AdminGroups   string `json:"admin_groups" help:"Comma separated groups/roles granted admin access to Komari; empty allows every authenticated user"`
+	AutoProvision bool   `json:"auto_provision" help:"Create a Komari account on first login when no account is bound, requires admin_groups"`
+}
+
+// authSession 授权请求期间需要保存的数据
+type authSession struct {
+	Verifier    string
+	Nonce       string
+	RedirectURI string
+}
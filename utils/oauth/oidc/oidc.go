@@ -0,0 +1,209 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/oauth/factory"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/oauth2"
+)
+
+var discoverMu sync.Mutex
+
+func (o *Oidc) GetName() string {
+	return "oidc"
+}
+
+func (o *Oidc) GetConfiguration() factory.Configuration {
+	return &o.Addition
+}
+
+// discover 通过 .well-known/openid-configuration 获取端点与 JWKS，失败时下次请求会重试
+func (o *Oidc) discover(ctx context.Context) error {
+	discoverMu.Lock()
+	defer discoverMu.Unlock()
+	if o.provider != nil {
+		return nil
+	}
+	provider, err := oidc.NewProvider(ctx, strings.TrimSuffix(o.Addition.Issuer, "/"))
+	if err != nil {
+		return fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	o.provider = provider
+	o.verifier = provider.Verifier(&oidc.Config{ClientID: o.Addition.ClientId})
+	o.oauth2 = &oauth2.Config{
+		ClientID:     o.Addition.ClientId,
+		ClientSecret: o.Addition.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       o.scopes(),
+	}
+	return nil
+}
+
+func (o *Oidc) scopes() []string {
+	scopes := []string{oidc.ScopeOpenID}
+	for _, s := range strings.Fields(o.Addition.Scope) {
+		if s != oidc.ScopeOpenID {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func (o *Oidc) GetAuthorizationURL(redirectURI string) (string, string) {
+	if err := o.discover(context.Background()); err != nil {
+		return "", ""
+	}
+	state := utils.GenerateRandomString(16)
+	session := authSession{
+		Nonce:       utils.GenerateRandomString(16),
+		RedirectURI: redirectURI,
+	}
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("redirect_uri", redirectURI),
+		oidc.Nonce(session.Nonce),
+	}
+	if !o.Addition.DisablePKCE {
+		session.Verifier = oauth2.GenerateVerifier()
+		opts = append(opts, oauth2.S256ChallengeOption(session.Verifier))
+	}
+	o.stateCache.Set(state, session, cache.DefaultExpiration)
+	return o.oauth2.AuthCodeURL(state, opts...), state
+}
+
+func (o *Oidc) OnCallback(ctx *gin.Context, state string, query map[string]string, callbackURI string) (factory.OidcCallback, error) {
+	if errCode := query["error"]; errCode != "" {
+		return factory.OidcCallback{}, fmt.Errorf("authorization failed: %s %s", errCode, query["error_description"])
+	}
+	if state == "" {
+		return factory.OidcCallback{}, fmt.Errorf("invalid state")
+	}
+	cached, ok := o.stateCache.Get(state)
+	if !ok {
+		return factory.OidcCallback{}, fmt.Errorf("invalid state")
+	}
+	o.stateCache.Delete(state)
+	session := cached.(authSession)
+
+	code := query["code"]
+	if code == "" {
+		return factory.OidcCallback{}, fmt.Errorf("no code provided")
+	}
+	if err := o.discover(ctx.Request.Context()); err != nil {
+		return factory.OidcCallback{}, err
+	}
+
+	opts := []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("redirect_uri", session.RedirectURI)}
+	if session.Verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(session.Verifier))
+	}
+	token, err := o.oauth2.Exchange(ctx.Request.Context(), code, opts...)
+	if err != nil {
+		return factory.OidcCallback{}, fmt.Errorf("failed to exchange code: %s", utils.DataMasking(err.Error(), []string{o.Addition.ClientSecret}))
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return factory.OidcCallback{}, fmt.Errorf("no id_token in token response")
+	}
+
+	// 校验签名（JWKS）、issuer、audience 与过期时间
+	idToken, err := o.verifier.Verify(ctx.Request.Context(), rawIDToken)
+	if err != nil {
+		return factory.OidcCallback{}, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	if idToken.Nonce != session.Nonce {
+		return factory.OidcCallback{}, fmt.Errorf("invalid nonce")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return factory.OidcCallback{}, fmt.Errorf("failed to extract claims from token: %w", err)
+	}
+
+	// groups/roles 仅在此处用于判断是否允许登录，不向外传递
+	if !o.isAdmin(claimStrings(claims[o.Addition.GroupsClaim])) {
+		return factory.OidcCallback{}, fmt.Errorf("user is not a member of any admin group")
+	}
+
+	username, _ := claims[o.Addition.UsernameClaim].(string)
+	return factory.OidcCallback{
+		UserId:        idToken.Subject,
+		Username:      username,
+		AutoProvision: o.Addition.AutoProvision && strings.TrimSpace(o.Addition.AdminGroups) != "",
+	}, nil
+}
+
+// isAdmin 将 groups/roles 映射为 Komari 权限，Komari 目前只有管理员一种角色
+func (o *Oidc) isAdmin(groups []string) bool {
+	if strings.TrimSpace(o.Addition.AdminGroups) == "" {
+		return true
+	}
+	for _, want := range strings.Split(o.Addition.AdminGroups, ",") {
+		want = strings.TrimSpace(want)
+		for _, g := range groups {
+			if want != "" && g == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimStrings 兼容字符串数组、单个字符串以及空格分隔的字符串
+func claimStrings(v any) []string {
+	switch val := v.(type) {
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return strings.Fields(val)
+	}
+	return nil
+}
+
+func (o *Oidc) Init() error {
+	if o.Addition.Issuer == "" {
+		return fmt.Errorf("issuer is required")
+	}
+	if o.Addition.ClientId == "" {
+		return fmt.Errorf("client_id is required")
+	}
+	// 自动创建的账号即为管理员，必须限定管理员组，否则任何能在 IdP 登录的人都会获得管理员账号
+	if o.Addition.AutoProvision && strings.TrimSpace(o.Addition.AdminGroups) == "" {
+		return fmt.Errorf("admin_groups is required when auto_provision is enabled")
+	}
+	if o.Addition.Scope == "" {
+		o.Addition.Scope = "openid profile email"
+	}
+	if o.Addition.UsernameClaim == "" {
+		o.Addition.UsernameClaim = "preferred_username"
+	}
+	if o.Addition.GroupsClaim == "" {
+		o.Addition.GroupsClaim = "groups"
+	}
+	o.stateCache = cache.New(time.Minute*5, time.Minute*10)
+	// 启动时 IdP 不可用不应阻止加载，首次登录时会重新发现
+	_ = o.discover(context.Background())
+	return nil
+}
+
+func (o *Oidc) Destroy() error {
+	if o.stateCache != nil {
+		o.stateCache.Flush()
+	}
+	return nil
+}
+
+var _ factory.IOidcProvider = (*Oidc)(nil)
@@ -0,0 +1,168 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// stubIdP 本地最小 OpenID Provider，用于验证 discovery、PKCE、nonce 与 JWKS 校验
+type stubIdP struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	groups    []string
+	replay    bool // 返回错误的 nonce，模拟重放的 id_token
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		nonce := idp.nonce
+		if idp.replay {
+			nonce = "replayed"
+		}
+		signer, _ := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+		idToken, _ := jwt.Signed(signer).Claims(map[string]any{
+			"iss":                idp.server.URL,
+			"sub":                "user-1",
+			"aud":                "komari",
+			"exp":                time.Now().Add(time.Minute).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              nonce,
+			"preferred_username": "alice",
+			"groups":             idp.groups,
+		}).Serialize()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func login(t *testing.T, idp *stubIdP, o *Oidc) error {
+	authURL, state := o.GetAuthorizationURL("http://komari.local/api/oauth_callback")
+	u, err := url.Parse(authURL)
+	if err != nil || authURL == "" {
+		t.Fatalf("invalid authorization url %q", authURL)
+	}
+	if u.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected PKCE S256, got %q", u.Query().Get("code_challenge_method"))
+	}
+	idp.challenge = u.Query().Get("code_challenge")
+	idp.nonce = u.Query().Get("nonce")
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/oauth_callback", nil)
+	cb, err := o.OnCallback(ctx, state, map[string]string{"code": "c", "state": state}, "")
+	if err == nil && (cb.UserId != "user-1" || cb.Username != "alice") {
+		t.Fatalf("unexpected callback %+v", cb)
+	}
+	return err
+}
+
+func TestOidcLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp := newStubIdP(t)
+
+	tests := []struct {
+		name    string
+		groups  []string
+		admins  string
+		tamper  bool
+		wantErr bool
+	}{
+		{name: "未配置管理员组", groups: nil},
+		{name: "属于管理员组", groups: []string{"dev", "ops"}, admins: "ops"},
+		{name: "不属于管理员组", groups: []string{"dev"}, admins: "ops", wantErr: true},
+		{name: "nonce 不匹配", tamper: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Oidc{Addition: Addition{Issuer: idp.server.URL, ClientId: "komari", AdminGroups: tt.admins}}
+			if err := o.Init(); err != nil {
+				t.Fatal(err)
+			}
+			idp.groups, idp.replay = tt.groups, tt.tamper
+			err := login(t, idp, o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOidcAutoProvision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp := newStubIdP(t)
+
+	o := &Oidc{Addition: Addition{Issuer: idp.server.URL, ClientId: "komari", AutoProvision: true}}
+	if err := o.Init(); err == nil {
+		t.Fatal("expected Init to reject auto_provision without admin_groups")
+	}
+
+	tests := []struct {
+		name    string
+		groups  []string
+		wantErr bool
+	}{
+		{name: "属于管理员组时允许自动创建", groups: []string{"ops"}},
+		{name: "不属于管理员组时拒绝自动创建", groups: []string{"dev"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Oidc{Addition: Addition{Issuer: idp.server.URL, ClientId: "komari", AdminGroups: "ops", AutoProvision: true}}
+			if err := o.Init(); err != nil {
+				t.Fatal(err)
+			}
+			idp.groups, idp.replay = tt.groups, false
+			err := login(t, idp, o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
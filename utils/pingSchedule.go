@@ -83,12 +83,23 @@ func executePingTask(ctx context.Context, task models.PingTask, onlineClients ma
 		Message string `json:"message"`
 		Type    string `json:"ping_type"`
 		Target  string `json:"ping_target"`
+		// 以下参数旧版本 Agent 会忽略
+		Count          int    `json:"ping_count,omitempty"`
+		Timeout        int    `json:"ping_timeout,omitempty"`
+		IPFamily       string `json:"ip_family,omitempty"`
+		HttpMethod     string `json:"http_method,omitempty"`
+		ExpectedStatus int    `json:"expected_status,omitempty"`
 	}
 
 	message.Message = "ping"
 	message.TaskID = task.Id
 	message.Type = task.Type
 	message.Target = task.Target
+	message.Count = task.Count
+	message.Timeout = task.Timeout
+	message.IPFamily = task.IPFamily
+	message.HttpMethod = task.HttpMethod
+	message.ExpectedStatus = task.ExpectedStatus
 
 	for _, clientUUID := range task.Clients {
 		select {
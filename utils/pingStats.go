@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// Percentile 对已升序排序的切片做线性插值求分位数，pct 取值 0~1
+func Percentile(vals []int, pct float64) int {
+	if len(vals) == 0 {
+		return 0
+	}
+	if pct <= 0 {
+		return vals[0]
+	}
+	if pct >= 1 {
+		return vals[len(vals)-1]
+	}
+	pos := (float64(len(vals) - 1)) * pct
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return vals[lo]
+	}
+	frac := pos - float64(lo)
+	v := float64(vals[lo]) + (float64(vals[hi])-float64(vals[lo]))*frac
+	return int(math.Round(v))
+}
+
+// PingWindow 一段时间内 Ping 记录的汇总
+type PingWindow struct {
+	Samples int
+	Loss    float64 // 丢包率 %，多包探测按每次探测的丢包比例加权
+	Latest  int     // 最近一次有效延迟，-1 表示没有
+	Avg     int
+	Min     int
+	Max     int
+	P50     int
+	P95     int
+	P99     int
+	Jitter  int // 各次探测抖动的平均值
+}
+
+// SummarizePing 汇总 Ping 记录，全部丢失的探测不计入延迟统计
+func SummarizePing(records []models.PingRecord) PingWindow {
+	w := PingWindow{Latest: -1}
+	values := make([]int, 0, len(records))
+	var latestTs models.LocalTime
+	lost, sum, jitter := 0.0, 0, 0
+	for _, r := range records {
+		w.Samples++
+		lost += r.LossRatio()
+		if r.Value < 0 {
+			continue
+		}
+		values = append(values, r.Value)
+		sum += r.Value
+		jitter += r.Jitter
+		lo, hi := r.Min, r.Max
+		if lo <= 0 {
+			lo = r.Value
+		}
+		if hi <= 0 {
+			hi = r.Value
+		}
+		if w.Min == 0 || lo < w.Min {
+			w.Min = lo
+		}
+		if hi > w.Max {
+			w.Max = hi
+		}
+		if w.Latest < 0 || r.Time.ToTime().After(latestTs.ToTime()) {
+			latestTs = r.Time
+			w.Latest = r.Value
+		}
+	}
+	if w.Samples > 0 {
+		w.Loss = lost / float64(w.Samples) * 100
+	}
+	if len(values) > 0 {
+		w.Avg = sum / len(values)
+		w.Jitter = jitter / len(values)
+		sort.Ints(values)
+		w.P50 = Percentile(values, 0.50)
+		w.P95 = Percentile(values, 0.95)
+		w.P99 = Percentile(values, 0.99)
+	}
+	return w
+}
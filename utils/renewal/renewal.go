@@ -102,6 +102,19 @@ func CheckAndAutoRenewal(client models.Client) {
 			//	NewExpireTime: newExpireTime,
 			//})
 
+			// 记录续费账本，失败不影响续费本身
+			if err := clients.AddRenewalRecord(models.RenewalRecord{
+				Client:            client.UUID,
+				ClientName:        client.Name,
+				Amount:            client.Price,
+				Currency:          client.Currency,
+				BillingCycle:      client.BillingCycle,
+				PreviousExpiredAt: client.ExpiredAt,
+				NewExpiredAt:      models.FromTime(newExpireTime),
+			}); err != nil {
+				auditlog.EventLog("renewal", fmt.Sprintf("Failed to record renewal of client %s (%s): %v", client.Name, client.UUID, err))
+			}
+
 			auditlog.EventLog("renewal", fmt.Sprintf("Auto-renewed client: %s until %s",
 				client.Name, newExpireTime.Format("2006-01-02")))
 
@@ -28,6 +28,8 @@ type ContextMeta struct {
 	RemoteIP string
 	// UserAgent 请求 UA（可选）
 	UserAgent string
+	// Dashboard 通过 /s/<slug> 公开面板访问时关联的面板，按面板的节点选择与字段规则过滤数据
+	Dashboard *models.Dashboard
 }
 
 // 私有类型做 key，避免外部冲突
@@ -0,0 +1,161 @@
+// Package snippet 将剪贴板条目作为命令模板，按参数与目标客户端渲染出实际执行的命令。
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+// 客户端变量，参数不能与之重名
+var clientVars = []string{"name", "uuid", "ip", "ipv4", "ipv6", "os", "arch", "group", "region", "tags"}
+
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// maxRenderedSize 渲染结果的最大长度
+const maxRenderedSize = 64 * 1024
+
+// ValidateParams 检查参数声明，声明了参数时同时检查模板语法；
+// 未声明参数的条目可能只是普通文本，执行时才会按模板渲染。
+func ValidateParams(cb models.Clipboard) error {
+	seen := map[string]bool{}
+	for _, p := range cb.Params {
+		if !paramNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("invalid parameter name: %q", p.Name)
+		}
+		if isClientVar(p.Name) {
+			return fmt.Errorf("parameter name %q is reserved for client variables", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate parameter: %s", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern of %s: %v", p.Name, err)
+			}
+		}
+		if p.Default != "" {
+			if err := checkValue(p, p.Default); err != nil {
+				return fmt.Errorf("invalid default of %s: %v", p.Name, err)
+			}
+		}
+	}
+	if len(cb.Params) == 0 {
+		return nil
+	}
+	_, err := parse(cb.Text)
+	return err
+}
+
+// ResolveParams 校验传入的参数值，未传入的使用默认值，未声明的参数被忽略
+func ResolveParams(params models.ClipboardParams, input map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(params))
+	for _, p := range params {
+		v, ok := input[p.Name]
+		if !ok || v == "" {
+			v = p.Default
+		}
+		if v == "" {
+			if p.Required {
+				return nil, fmt.Errorf("parameter %s is required", p.Name)
+			}
+			values[p.Name] = ""
+			continue
+		}
+		if err := checkValue(p, v); err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", p.Name, err)
+		}
+		values[p.Name] = v
+	}
+	return values, nil
+}
+
+func checkValue(p models.ClipboardParam, v string) error {
+	if len(p.Options) > 0 {
+		found := false
+		for _, o := range p.Options {
+			if o == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%q is not one of %s", v, strings.Join(p.Options, ", "))
+		}
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(v) {
+			return fmt.Errorf("%q does not match %s", v, p.Pattern)
+		}
+	}
+	return nil
+}
+
+// Vars 返回客户端相关的模板变量
+func Vars(c models.Client) map[string]string {
+	ip := c.IPv4
+	if ip == "" {
+		ip = c.IPv6
+	}
+	return map[string]string{
+		"name":   c.Name,
+		"uuid":   c.UUID,
+		"ip":     ip,
+		"ipv4":   c.IPv4,
+		"ipv6":   c.IPv6,
+		"os":     c.OS,
+		"arch":   c.Arch,
+		"group":  c.Group,
+		"region": c.Region,
+		"tags":   c.Tags,
+	}
+}
+
+// Render 为目标客户端渲染命令，values 为 ResolveParams 返回的参数值
+func Render(text string, c models.Client, values map[string]string) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	data := Vars(c)
+	for k, v := range values {
+		data[k] = v
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	if buf.Len() > maxRenderedSize {
+		return "", fmt.Errorf("rendered command exceeds %d bytes", maxRenderedSize)
+	}
+	return buf.String(), nil
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("snippet").Option("missingkey=error").Funcs(template.FuncMap{
+		"quote": ShellQuote,
+	}).Parse(text)
+}
+
+// ShellQuote 以单引号包裹，用于在模板中安全地拼接参数，例如 {{quote .path}}
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isClientVar(name string) bool {
+	for _, v := range clientVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
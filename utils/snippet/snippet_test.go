@@ -0,0 +1,58 @@
+package snippet
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		cb      models.Clipboard
+		wantErr bool
+	}{
+		{"普通文本", models.Clipboard{Text: "echo {{"}, false},
+		{"合法参数", models.Clipboard{Text: "ping {{.host}}", Params: models.ClipboardParams{{Name: "host"}}}, false},
+		{"参数名非法", models.Clipboard{Text: "x", Params: models.ClipboardParams{{Name: "1host"}}}, true},
+		{"与客户端变量重名", models.Clipboard{Text: "x", Params: models.ClipboardParams{{Name: "ip"}}}, true},
+		{"参数重复", models.Clipboard{Text: "x", Params: models.ClipboardParams{{Name: "a"}, {Name: "a"}}}, true},
+		{"默认值不在选项中", models.Clipboard{Text: "x", Params: models.ClipboardParams{{Name: "a", Default: "c", Options: []string{"a", "b"}}}}, true},
+		{"模板语法错误", models.Clipboard{Text: "echo {{.a", Params: models.ClipboardParams{{Name: "a"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateParams(tt.cb); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	params := models.ClipboardParams{
+		{Name: "path", Required: true},
+		{Name: "count", Default: "3", Pattern: `\d+`},
+	}
+	if _, err := ResolveParams(params, map[string]string{}); err == nil {
+		t.Fatal("missing required parameter should fail")
+	}
+	if _, err := ResolveParams(params, map[string]string{"path": "/tmp", "count": "x"}); err == nil {
+		t.Fatal("value not matching pattern should fail")
+	}
+	values, err := ResolveParams(params, map[string]string{"path": "/tmp/it's"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := models.Client{Name: "hk-1", IPv6: "::1"}
+	got, err := Render("ls {{quote .path}} -n {{.count}} # {{.name}} {{.ip}}", client, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `ls '/tmp/it'\''s' -n 3 # hk-1 ::1`; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if _, err := Render("{{.unknown}}", client, values); err == nil {
+		t.Error("unknown variable should fail")
+	}
+}
@@ -0,0 +1,106 @@
+package themes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
+)
+
+// Changelog 主题的版本更新记录
+type Changelog struct {
+	Version string `json:"version"`
+	Date    string `json:"date"`
+	Notes   string `json:"notes"`
+}
+
+// IndexEntry 主题索引中的一个主题
+type IndexEntry struct {
+	Name        string      `json:"name"`
+	Short       string      `json:"short"`
+	Description string      `json:"description"`
+	Author      string      `json:"author"`
+	Version     string      `json:"version"`
+	MinVersion  string      `json:"minKomariVersion,omitempty"`
+	URL         string      `json:"url"`          // 主页或仓库地址
+	DownloadURL string      `json:"download_url"` // 主题 zip 下载地址
+	SHA256      string      `json:"sha256"`
+	Signature   string      `json:"signature"` // base64 Ed25519 签名
+	Preview     string      `json:"preview"`
+	Changelog   []Changelog `json:"changelog"`
+}
+
+// Index 主题索引文件
+type Index struct {
+	Themes []IndexEntry `json:"themes"`
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxDownloadSize 主题包与索引的大小上限
+const maxDownloadSize = 64 << 20
+
+// Download 下载文件内容
+func Download(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, i18n.NewError("theme.fetch_failed", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, i18n.NewError("theme.fetch_http_status", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize))
+	if err != nil {
+		return nil, i18n.NewError("theme.fetch_read_failed", err)
+	}
+	if len(data) == 0 {
+		return nil, i18n.NewError("theme.fetch_empty")
+	}
+	return data, nil
+}
+
+// FetchIndex 获取并解析主题索引
+func FetchIndex(url string) (Index, error) {
+	var index Index
+	if url == "" {
+		return index, i18n.NewError("theme.index_url_missing")
+	}
+	data, err := Download(url)
+	if err != nil {
+		return index, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, i18n.NewError("theme.index_invalid", err)
+	}
+	return index, nil
+}
+
+// Find 按 short 查找主题
+func (i Index) Find(short string) (IndexEntry, bool) {
+	for _, e := range i.Themes {
+		if e.Short == short {
+			return e, true
+		}
+	}
+	return IndexEntry{}, false
+}
+
+// ChangesSince 返回比 installed 更新的版本记录，installed 为空时返回全部
+func (e IndexEntry) ChangesSince(installed string) []Changelog {
+	changes := []Changelog{}
+	for _, c := range e.Changelog {
+		if installed == "" || utils.CompareVersion(c.Version, installed) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// HasUpdate 判断索引中的版本是否比已安装版本新
+func (e IndexEntry) HasUpdate(installed string) bool {
+	return installed != "" && utils.CompareVersion(e.Version, installed) > 0
+}
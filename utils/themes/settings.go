@@ -0,0 +1,238 @@
+package themes
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils/i18n"
+)
+
+// 托管配置项类型
+const (
+	SettingString      = "string"
+	SettingNumber      = "number"
+	SettingSelect      = "select"
+	SettingSwitch      = "switch"
+	SettingTitle       = "title" // 仅用于分组显示，不保存值
+	SettingColor       = "color"
+	SettingImage       = "image"       // 图片地址，可上传到 ./data/theme_assets
+	SettingMultiSelect = "multiselect" // 多选，值为字符串数组
+	SettingClients     = "clients"     // 客户端选择，值为 UUID 数组
+)
+
+// AssetsDir 主题配置中上传的图片保存目录，通过 /theme-assets 访问
+const AssetsDir = "./data/theme_assets"
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// Schema 读取已安装主题声明的托管配置项，非托管主题返回 false
+func Schema(short string) ([]models.ManagedThemeConfigurationItem, bool) {
+	if !IsValidShort(short) {
+		return nil, false
+	}
+	theme, err := LoadConfig(filepath.Join(Dir, short, configName))
+	if err != nil || theme.Configuration.Type != "managed" {
+		return nil, false
+	}
+	raw, err := json.Marshal(theme.Configuration.Data)
+	if err != nil {
+		return nil, false
+	}
+	var items []models.ManagedThemeConfigurationItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+// options 解析以逗号分隔的可选值
+func options(item models.ManagedThemeConfigurationItem) []string {
+	var opts []string
+	for _, o := range strings.Split(item.Options, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			opts = append(opts, o)
+		}
+	}
+	return opts
+}
+
+// DefaultValue 返回配置项的默认值，未声明时按类型取零值，select 取第一个选项
+func DefaultValue(item models.ManagedThemeConfigurationItem) any {
+	def := item.Default
+	if item.Type == SettingSelect && (def == nil || def == "") {
+		if opts := options(item); len(opts) > 0 {
+			def = opts[0]
+		}
+	}
+	if def != nil {
+		return def
+	}
+	switch item.Type {
+	case SettingNumber:
+		return 0
+	case SettingSwitch:
+		return false
+	case SettingMultiSelect, SettingClients:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// Resolve 为未设置的配置项补全默认值
+func Resolve(items []models.ManagedThemeConfigurationItem, values map[string]any) map[string]any {
+	if values == nil {
+		values = map[string]any{}
+	}
+	for _, item := range items {
+		if item.Key == "" || item.Type == SettingTitle {
+			continue
+		}
+		if _, exists := values[item.Key]; !exists {
+			values[item.Key] = DefaultValue(item)
+		}
+	}
+	return values
+}
+
+// Validate 按主题声明校验提交的配置，返回规范化后的值。
+// 未声明的键会被丢弃，缺失的可选项使用默认值；clientExists 用于校验客户端选择项。
+func Validate(items []models.ManagedThemeConfigurationItem, values map[string]any, clientExists func(uuid string) bool) (map[string]any, error) {
+	result := map[string]any{}
+	for _, item := range items {
+		if item.Key == "" || item.Type == SettingTitle {
+			continue
+		}
+		v, exists := values[item.Key]
+		if !exists || v == nil || v == "" {
+			if item.Required {
+				return nil, i18n.NewError("theme.setting.required", itemName(item))
+			}
+			result[item.Key] = DefaultValue(item)
+			continue
+		}
+		normalized, err := validateValue(item, v, clientExists)
+		if err != nil {
+			return nil, i18n.NewError("theme.setting.invalid", itemName(item), err)
+		}
+		if item.Required && isEmptyList(normalized) {
+			return nil, i18n.NewError("theme.setting.required", itemName(item))
+		}
+		result[item.Key] = normalized
+	}
+	return result, nil
+}
+
+func validateValue(item models.ManagedThemeConfigurationItem, v any, clientExists func(string) bool) (any, error) {
+	switch item.Type {
+	case SettingNumber:
+		n, ok := v.(float64)
+		if !ok {
+			return nil, i18n.NewError("theme.setting.expect_number")
+		}
+		if item.Min != nil && n < *item.Min {
+			return nil, i18n.NewError("theme.setting.too_small", *item.Min)
+		}
+		if item.Max != nil && n > *item.Max {
+			return nil, i18n.NewError("theme.setting.too_large", *item.Max)
+		}
+		return n, nil
+	case SettingSwitch:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, i18n.NewError("theme.setting.expect_bool")
+		}
+		return b, nil
+	case SettingSelect:
+		s, ok := v.(string)
+		if !ok || !contains(options(item), s) {
+			return nil, i18n.NewError("theme.setting.not_an_option")
+		}
+		return s, nil
+	case SettingMultiSelect:
+		list, err := stringList(v)
+		if err != nil {
+			return nil, err
+		}
+		opts := options(item)
+		for _, s := range list {
+			if !contains(opts, s) {
+				return nil, i18n.NewError("theme.setting.option_invalid", s)
+			}
+		}
+		return list, nil
+	case SettingClients:
+		list, err := stringList(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, uuid := range list {
+			if clientExists != nil && !clientExists(uuid) {
+				return nil, i18n.NewError("theme.setting.client_not_found", uuid)
+			}
+		}
+		return list, nil
+	case SettingColor:
+		s, ok := v.(string)
+		if !ok || !colorPattern.MatchString(s) {
+			return nil, i18n.NewError("theme.setting.expect_color")
+		}
+		return s, nil
+	case SettingImage:
+		s, ok := v.(string)
+		if !ok || !(strings.HasPrefix(s, "/") || strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")) {
+			return nil, i18n.NewError("theme.setting.expect_image")
+		}
+		// "//host" 与 "/\host" 会被浏览器当作协议相对地址指向外部站点
+		if strings.HasPrefix(s, "//") || strings.HasPrefix(s, "/\\") {
+			return nil, i18n.NewError("theme.setting.expect_image")
+		}
+		return s, nil
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return nil, i18n.NewError("theme.setting.expect_string")
+		}
+		return s, nil
+	}
+}
+
+func stringList(v any) ([]string, error) {
+	raw, ok := v.([]any)
+	if !ok {
+		return nil, i18n.NewError("theme.setting.expect_array")
+	}
+	list := make([]string, 0, len(raw))
+	for _, e := range raw {
+		s, ok := e.(string)
+		if !ok {
+			return nil, i18n.NewError("theme.setting.expect_string_items")
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func isEmptyList(v any) bool {
+	list, ok := v.([]string)
+	return ok && len(list) == 0
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func itemName(item models.ManagedThemeConfigurationItem) string {
+	if item.Name != "" {
+		return item.Name
+	}
+	return item.Key
+}
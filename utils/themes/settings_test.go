@@ -0,0 +1,60 @@
+package themes
+
+import (
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+)
+
+func TestValidate(t *testing.T) {
+	min, max := 1.0, 10.0
+	items := []models.ManagedThemeConfigurationItem{
+		{Key: "section", Type: SettingTitle},
+		{Key: "title", Type: SettingString, Required: true},
+		{Key: "columns", Type: SettingNumber, Min: &min, Max: &max, Default: 3.0},
+		{Key: "layout", Type: SettingSelect, Options: "grid, list"},
+		{Key: "badges", Type: SettingMultiSelect, Options: "cpu,ram,disk"},
+		{Key: "accent", Type: SettingColor},
+		{Key: "logo", Type: SettingImage},
+		{Key: "pinned", Type: SettingClients},
+		{Key: "dark", Type: SettingSwitch},
+	}
+	exists := func(uuid string) bool { return uuid == "a" }
+
+	tests := []struct {
+		name    string
+		values  map[string]any
+		wantErr bool
+	}{
+		{"仅必填项", map[string]any{"title": "Komari"}, false},
+		{"缺少必填项", map[string]any{}, true},
+		{"完整有效", map[string]any{"title": "x", "columns": 4.0, "layout": "list", "badges": []any{"cpu"},
+			"accent": "#ff0000", "logo": "/theme-assets/t/a.png", "pinned": []any{"a"}, "dark": true}, false},
+		{"数字越界", map[string]any{"title": "x", "columns": 11.0}, true},
+		{"选项不存在", map[string]any{"title": "x", "layout": "table"}, true},
+		{"多选项不存在", map[string]any{"title": "x", "badges": []any{"gpu"}}, true},
+		{"协议相对的图片地址", map[string]any{"title": "x", "logo": "//evil.example/a.png"}, true},
+		{"颜色格式错误", map[string]any{"title": "x", "accent": "red"}, true},
+		{"客户端不存在", map[string]any{"title": "x", "pinned": []any{"b"}}, true},
+		{"类型错误", map[string]any{"title": "x", "dark": "yes"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Validate(items, tt.values, exists)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, _ := Validate(items, map[string]any{"title": "x", "unknown": 1}, exists)
+	if _, ok := got["unknown"]; ok {
+		t.Error("undeclared keys should be dropped")
+	}
+	if _, ok := got["section"]; ok {
+		t.Error("title items should not store values")
+	}
+	if got["columns"] != 3.0 || got["layout"] != "grid" || got["dark"] != false {
+		t.Errorf("defaults not filled: %v", got)
+	}
+}
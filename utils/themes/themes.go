@@ -0,0 +1,364 @@
+// Package themes 负责主题包的校验、安装与回滚，以及主题市场索引的获取。
+package themes
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+	"github.com/komari-monitor/komari/utils/i18n"
+)
+
+const (
+	Dir        = "./data/theme" // 主题安装目录
+	stagingDir = ".staging"     // 解压中的主题
+	backupDir  = ".backup"      // 上一个版本，用于回滚
+	configName = "komari-theme.json"
+)
+
+// InstallOptions 安装主题时的校验参数
+type InstallOptions struct {
+	Signature        string              // base64 编码的 Ed25519 签名，签名内容为整个 zip 文件
+	SHA256           string              // 期望的 zip 文件 SHA256（十六进制），为空时不校验
+	Short            string              // 期望的主题 short，更新时防止覆盖成其他主题，为空时不校验
+	TrustedKeys      []ed25519.PublicKey // 受信任的公钥
+	RequireSignature bool                // 为 true 时未签名或签名无效的主题均拒绝安装
+}
+
+// OptionsFromConfig 根据站点配置生成校验参数
+func OptionsFromConfig(cfg models.Config, signature string) (InstallOptions, error) {
+	keys, err := ParseTrustedKeys(cfg.ThemeTrustedKeys)
+	if err != nil {
+		return InstallOptions{}, err
+	}
+	return InstallOptions{
+		Signature:        signature,
+		TrustedKeys:      keys,
+		RequireSignature: cfg.ThemeRequireSignature,
+	}, nil
+}
+
+// ParseTrustedKeys 解析 base64 编码的 Ed25519 公钥，每行或以逗号分隔一个
+func ParseTrustedKeys(raw string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, line := range strings.FieldsFunc(raw, func(r rune) bool { return r == '\n' || r == ',' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, i18n.NewError("theme.trusted_key_invalid", line)
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	return keys, nil
+}
+
+// VerifySignature 使用任一受信任公钥校验 zip 文件的签名
+func VerifySignature(data []byte, signature string, keys []ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return i18n.NewError("theme.signature_malformed")
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return i18n.NewError("theme.signature_untrusted")
+}
+
+// CheckCompatible 检查主题要求的最低版本，开发版本（非数字或未注入的默认版本号）不做限制
+func CheckCompatible(theme models.Theme) error {
+	if theme.MinVersion == "" || !isReleaseVersion(utils.CurrentVersion) {
+		return nil
+	}
+	if utils.CompareVersion(utils.CurrentVersion, theme.MinVersion) < 0 {
+		return i18n.NewError("theme.incompatible", theme.Short, theme.MinVersion, utils.CurrentVersion)
+	}
+	return nil
+}
+
+// unsetVersions 构建时未注入版本号时的默认值，视为开发版本
+var unsetVersions = map[string]bool{"0.0.0": true, "0.0.1": true}
+
+func isReleaseVersion(v string) bool {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	return v != "" && v[0] >= '0' && v[0] <= '9' && !unsetVersions[v]
+}
+
+// verify 校验哈希与签名
+func (o InstallOptions) verify(data []byte) error {
+	if o.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), o.SHA256) {
+			return i18n.NewError("theme.sha256_mismatch")
+		}
+	}
+	if o.Signature == "" {
+		if o.RequireSignature {
+			return i18n.NewError("theme.signature_required")
+		}
+		return nil
+	}
+	if len(o.TrustedKeys) == 0 {
+		if o.RequireSignature {
+			return i18n.NewError("theme.signature_no_keys")
+		}
+		return nil
+	}
+	return VerifySignature(data, o.Signature, o.TrustedKeys)
+}
+
+// Install 校验并安装主题包。主题先解压到临时目录并检查能否加载，
+// 然后替换现有版本，原版本保留在备份目录中，替换失败时自动恢复。
+func Install(data []byte, opts InstallOptions) (models.Theme, error) {
+	var theme models.Theme
+	if err := opts.verify(data); err != nil {
+		return theme, err
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return theme, i18n.NewError("theme.zip_open_failed", err)
+	}
+	theme, err = readManifest(r)
+	if err != nil {
+		return theme, err
+	}
+	if opts.Short != "" && theme.Short != opts.Short {
+		return theme, i18n.NewError("theme.short_mismatch", theme.Short, opts.Short)
+	}
+	if err := CheckCompatible(theme); err != nil {
+		return theme, err
+	}
+
+	staging := filepath.Join(Dir, stagingDir, theme.Short)
+	os.RemoveAll(staging)
+	defer os.RemoveAll(staging)
+	if err := extract(r, staging); err != nil {
+		return theme, err
+	}
+	if err := checkLoadable(staging); err != nil {
+		return theme, err
+	}
+	if err := swap(theme.Short, staging); err != nil {
+		return theme, err
+	}
+	return theme, nil
+}
+
+// Rollback 将主题恢复到上一个版本，当前版本成为新的备份，可再次回滚
+func Rollback(short string) (models.Theme, error) {
+	if !IsValidShort(short) {
+		return models.Theme{}, i18n.NewError("theme.name_invalid")
+	}
+	backup := filepath.Join(Dir, backupDir, short)
+	previous, err := LoadConfig(filepath.Join(backup, configName))
+	if err != nil {
+		return previous, i18n.NewError("theme.no_backup")
+	}
+	current := filepath.Join(Dir, short)
+	tmp := filepath.Join(Dir, stagingDir, short+".rollback")
+	os.RemoveAll(tmp)
+	if err := os.MkdirAll(filepath.Dir(tmp), 0755); err != nil {
+		return previous, err
+	}
+	if err := os.Rename(current, tmp); err != nil && !os.IsNotExist(err) {
+		return previous, i18n.NewError("theme.rollback_failed", err)
+	}
+	if err := os.Rename(backup, current); err != nil {
+		os.Rename(tmp, current)
+		return previous, i18n.NewError("theme.rollback_failed", err)
+	}
+	if _, err := os.Stat(tmp); err == nil {
+		os.Rename(tmp, backup)
+	}
+	return previous, nil
+}
+
+// Backup 返回主题可回滚到的版本
+func Backup(short string) (models.Theme, bool) {
+	if !IsValidShort(short) {
+		return models.Theme{}, false
+	}
+	theme, err := LoadConfig(filepath.Join(Dir, backupDir, short, configName))
+	return theme, err == nil
+}
+
+// Installed 返回已安装的主题，忽略临时与备份目录
+func Installed() ([]models.Theme, error) {
+	entries, err := os.ReadDir(Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.Theme{}, nil
+		}
+		return nil, err
+	}
+	themes := []models.Theme{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if theme, err := LoadConfig(filepath.Join(Dir, entry.Name(), configName)); err == nil {
+			themes = append(themes, theme)
+		}
+	}
+	return themes, nil
+}
+
+// LoadConfig 加载主题配置
+func LoadConfig(configPath string) (models.Theme, error) {
+	var theme models.Theme
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return theme, err
+	}
+	err = json.Unmarshal(data, &theme)
+	return theme, err
+}
+
+// IsValidShort 验证主题short字段格式，只允许字母、数字、下划线和连字符
+func IsValidShort(short string) bool {
+	if short == "" || short == "default" {
+		return false
+	}
+	for _, r := range short {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func readManifest(r *zip.Reader) (models.Theme, error) {
+	var theme models.Theme
+	var manifest *zip.File
+	for _, f := range r.File {
+		if f.Name == configName {
+			manifest = f
+			break
+		}
+	}
+	if manifest == nil {
+		return theme, i18n.NewError("theme.manifest_missing")
+	}
+	rc, err := manifest.Open()
+	if err != nil {
+		return theme, i18n.NewError("theme.manifest_open_failed", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return theme, i18n.NewError("theme.manifest_read_failed", err)
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return theme, i18n.NewError("theme.manifest_invalid", err)
+	}
+	if theme.Name == "" || theme.Short == "" {
+		return theme, i18n.NewError("theme.manifest_missing_fields")
+	}
+	if !IsValidShort(theme.Short) {
+		return theme, i18n.NewError("theme.short_invalid_format")
+	}
+	return theme, nil
+}
+
+func extract(r *zip.Reader, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return i18n.NewError("theme.create_dir_failed", err)
+	}
+	for _, f := range r.File {
+		path := filepath.Join(dest, f.Name)
+		// 安全检查，防止路径遍历攻击
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			os.MkdirAll(path, 0755)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return i18n.NewError("common.create_dir_failed", err)
+		}
+		if err := extractFile(f, path); err != nil {
+			return i18n.NewError("theme.extract_failed", err)
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, path string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.FileInfo().Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, rc)
+	return err
+}
+
+// checkLoadable 确认解压后的主题可以被加载：配置可解析且包含入口页面
+func checkLoadable(dir string) error {
+	if _, err := LoadConfig(filepath.Join(dir, configName)); err != nil {
+		return i18n.NewError("theme.manifest_unloadable", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "dist", "index.html")); err != nil || info.IsDir() {
+		return i18n.NewError("theme.missing_index_html")
+	}
+	return nil
+}
+
+// swap 用解压好的目录替换当前版本，当前版本移入备份目录
+func swap(short, staging string) error {
+	current := filepath.Join(Dir, short)
+	backup := filepath.Join(Dir, backupDir, short)
+	hasCurrent := false
+	if _, err := os.Stat(current); err == nil {
+		if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+			return i18n.NewError("theme.create_backup_dir_failed", err)
+		}
+		os.RemoveAll(backup)
+		if err := os.Rename(current, backup); err != nil {
+			return i18n.NewError("theme.backup_failed", err)
+		}
+		hasCurrent = true
+	}
+	if err := os.Rename(staging, current); err != nil {
+		if hasCurrent {
+			os.Rename(backup, current)
+		}
+		return i18n.NewError("theme.install_failed", err)
+	}
+	if err := checkLoadable(current); err != nil {
+		os.RemoveAll(current)
+		if hasCurrent {
+			os.Rename(backup, current)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveBackup 删除主题的备份版本
+func RemoveBackup(short string) {
+	if IsValidShort(short) {
+		os.RemoveAll(filepath.Join(Dir, backupDir, short))
+	}
+}
@@ -0,0 +1,88 @@
+package themes
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/komari-monitor/komari/database/models"
+	"github.com/komari-monitor/komari/utils"
+)
+
+func TestVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	data := []byte("theme zip")
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+	sum := sha256.Sum256(data)
+
+	keys, err := ParseTrustedKeys(base64.StdEncoding.EncodeToString(otherPub) + "\n# comment\n" + base64.StdEncoding.EncodeToString(pub))
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("ParseTrustedKeys = %d, %v", len(keys), err)
+	}
+	if _, err := ParseTrustedKeys("not-a-key"); err == nil {
+		t.Error("invalid key should be rejected")
+	}
+
+	tests := []struct {
+		name    string
+		opts    InstallOptions
+		wantErr bool
+	}{
+		{"未签名且不强制", InstallOptions{}, false},
+		{"未签名但强制", InstallOptions{RequireSignature: true, TrustedKeys: keys}, true},
+		{"签名有效", InstallOptions{Signature: sig, TrustedKeys: keys, RequireSignature: true}, false},
+		{"签名不匹配", InstallOptions{Signature: sig, TrustedKeys: []ed25519.PublicKey{otherPub}}, true},
+		{"强制但未配置公钥", InstallOptions{Signature: sig, RequireSignature: true}, true},
+		{"哈希匹配", InstallOptions{SHA256: hex.EncodeToString(sum[:])}, false},
+		{"哈希不匹配", InstallOptions{SHA256: "00"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.verify(data); (err != nil) != tt.wantErr {
+				t.Errorf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	old := utils.CurrentVersion
+	defer func() { utils.CurrentVersion = old }()
+
+	tests := []struct {
+		name    string
+		current string
+		min     string
+		wantErr bool
+	}{
+		{"未声明最低版本", "1.0.0", "", false},
+		{"版本满足", "1.2.0", "1.1.9", false},
+		{"带前缀与预发布后缀", "v1.2.0-beta", "1.2", false},
+		{"版本过低", "1.0.10", "1.1.0", true},
+		{"开发版本不限制", "dev", "9.0.0", false},
+		{"未注入版本号视为开发版本", "0.0.1", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.CurrentVersion = tt.current
+			err := CheckCompatible(models.Theme{Short: "t", MinVersion: tt.min})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCompatible() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangesSince(t *testing.T) {
+	e := IndexEntry{Version: "1.2.0", Changelog: []Changelog{{Version: "1.2.0"}, {Version: "1.1.0"}, {Version: "1.0.0"}}}
+	if got := len(e.ChangesSince("1.0.0")); got != 2 {
+		t.Errorf("ChangesSince(1.0.0) = %d, want 2", got)
+	}
+	if !e.HasUpdate("1.1.0") || e.HasUpdate("1.2.0") || e.HasUpdate("") {
+		t.Error("HasUpdate mismatch")
+	}
+}
@@ -259,6 +259,59 @@ func AverageGPUReports(uuid string, time time.Time, reports []common.Report, top
 	return result
 }
 
+// AverageCustomMetrics 聚合一分钟内的自定义指标：gauge 使用与 AverageReport 相同的 topPercentage 平均，
+// counter 取最大值。同时返回每个指标最新的定义（类型、单位与是否公开）。
+func AverageCustomMetrics(uuid string, time time.Time, reports []common.Report, topPercentage float64) ([]models.CustomRecord, []models.CustomMetric) {
+	values := make(map[string][]float64)
+	latest := make(map[string]common.CustomMetric)
+	latestAt := make(map[string]int64)
+	for _, report := range reports {
+		for name, m := range report.Custom {
+			values[name] = append(values[name], m.Value)
+			if ts := report.UpdatedAt.UnixNano(); ts >= latestAt[name] {
+				latest[name] = m
+				latestAt[name] = ts
+			}
+		}
+	}
+
+	var records []models.CustomRecord
+	var defs []models.CustomMetric
+	for name, vals := range values {
+		m := latest[name]
+		sort.Float64s(vals)
+		value := vals[len(vals)-1]
+		if m.Type != common.CustomCounter {
+			n := len(vals)
+			if topPercentage > 0 && topPercentage <= 1 {
+				n = int(float64(len(vals)) * topPercentage)
+				if n == 0 {
+					n = 1
+				}
+			}
+			var sum float64
+			for _, v := range vals[len(vals)-n:] {
+				sum += v
+			}
+			value = sum / float64(n)
+		}
+		records = append(records, models.CustomRecord{
+			Client: uuid,
+			Name:   name,
+			Time:   models.FromTime(time),
+			Value:  value,
+		})
+		defs = append(defs, models.CustomMetric{
+			Client:    uuid,
+			Name:      name,
+			Type:      m.Type,
+			Unit:      m.Unit,
+			UpdatedAt: models.FromTime(time),
+		})
+	}
+	return records, defs
+}
+
 func DataMasking(str string, private []string) string {
 	if str == "" || len(private) == 0 {
 		return str
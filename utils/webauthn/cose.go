@@ -0,0 +1,102 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE 算法标识
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms 注册时向浏览器声明的算法，按优先级排列
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// parseCOSEKey 解析 COSE 编码的公钥
+func parseCOSEKey(coseKey []byte) (crypto.PublicKey, error) {
+	v, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := v.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid COSE key")
+	}
+	kty, _ := coseInt(key[int64(1)])
+	alg, _ := coseInt(key[int64(3)])
+	crv, _ := coseInt(key[int64(-1)])
+
+	switch {
+	case kty == 2 && alg == AlgES256:
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("unsupported EC2 key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("invalid EC2 point")
+		}
+		return pub, nil
+	case kty == 1 && alg == AlgEdDSA:
+		x, _ := key[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("unsupported OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	case kty == 3 && alg == AlgRS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("unsupported RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, fmt.Errorf("unsupported COSE key type %d / algorithm %d", kty, alg)
+}
+
+// verifySignature 使用 COSE 编码的公钥校验签名
+func verifySignature(coseKey []byte, message, sig []byte) error {
+	pub, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(message)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, hash[:], sig) {
+			return errInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, message, sig) {
+			return errInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig) != nil {
+			return errInvalidSignature
+		}
+	}
+	return nil
+}
+
+func coseInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,208 @@
+// Package webauthn 实现了 Komari 所需的最小 WebAuthn (passkey) 依赖方校验逻辑。
+// 注册时不校验 attestation 声明（等同于 attestation: "none"），只信任认证器返回的公钥。
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// 认证器数据标志位
+const (
+	flagUserPresent   = 0x01
+	flagUserVerified  = 0x04
+	flagAttestedCreds = 0x40
+)
+
+// Timeout 一次注册/登录仪式的有效期
+const Timeout = 5 * time.Minute
+
+var ceremonies = cache.New(Timeout, 10*time.Minute)
+
+type ceremony struct {
+	UserUUID  string
+	Challenge []byte
+}
+
+// RelyingParty 依赖方信息，RPID 为不带端口的域名，Origin 为浏览器看到的完整源
+type RelyingParty struct {
+	RPID   string
+	Origin string
+}
+
+// Credential 注册成功后需要保存的凭据
+type Credential struct {
+	ID        []byte
+	PublicKey []byte // COSE 编码
+	SignCount uint32
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	CredID    []byte
+	PublicKey []byte
+}
+
+// Begin 开始一次仪式，返回会话 ID 和需要交给浏览器的 challenge
+func Begin(userUUID string) (sessionID string, challenge []byte, err error) {
+	challenge = make([]byte, 32)
+	if _, err = rand.Read(challenge); err != nil {
+		return "", nil, err
+	}
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	sessionID = base64.RawURLEncoding.EncodeToString(id)
+	ceremonies.Set(sessionID, ceremony{UserUUID: userUUID, Challenge: challenge}, cache.DefaultExpiration)
+	return sessionID, challenge, nil
+}
+
+// Finish 取出并作废一次仪式，确保 challenge 只能使用一次
+func Finish(sessionID string) (userUUID string, challenge []byte, ok bool) {
+	v, found := ceremonies.Get(sessionID)
+	if !found {
+		return "", nil, false
+	}
+	ceremonies.Delete(sessionID)
+	c := v.(ceremony)
+	return c.UserUUID, c.Challenge, true
+}
+
+// VerifyRegistration 校验 navigator.credentials.create() 的结果
+func VerifyRegistration(rp RelyingParty, challenge, clientDataJSON, attestationObject []byte) (Credential, error) {
+	if err := verifyClientData(rp, "webauthn.create", challenge, clientDataJSON); err != nil {
+		return Credential{}, err
+	}
+	v, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, err
+	}
+	att, ok := v.(map[any]any)
+	if !ok {
+		return Credential{}, errors.New("invalid attestation object")
+	}
+	raw, ok := att["authData"].([]byte)
+	if !ok {
+		return Credential{}, errors.New("missing authData")
+	}
+	ad, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return Credential{}, err
+	}
+	if err := ad.verify(rp); err != nil {
+		return Credential{}, err
+	}
+	if ad.Flags&flagAttestedCreds == 0 || len(ad.CredID) == 0 {
+		return Credential{}, errors.New("no attested credential data")
+	}
+	// 提前确认公钥格式受支持，避免保存无法使用的凭据
+	if _, err := parseCOSEKey(ad.PublicKey); err != nil {
+		return Credential{}, err
+	}
+	return Credential{ID: ad.CredID, PublicKey: ad.PublicKey, SignCount: ad.SignCount}, nil
+}
+
+// VerifyAssertion 校验 navigator.credentials.get() 的结果，返回新的签名计数器
+func VerifyAssertion(rp RelyingParty, challenge []byte, cred Credential, clientDataJSON, authData, signature []byte, requireUV bool) (uint32, error) {
+	if err := verifyClientData(rp, "webauthn.get", challenge, clientDataJSON); err != nil {
+		return 0, err
+	}
+	ad, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return 0, err
+	}
+	if err := ad.verify(rp); err != nil {
+		return 0, err
+	}
+	if requireUV && ad.Flags&flagUserVerified == 0 {
+		return 0, errors.New("user verification required")
+	}
+	hash := sha256.Sum256(clientDataJSON)
+	message := append(append([]byte{}, authData...), hash[:]...)
+	if err := verifySignature(cred.PublicKey, message, signature); err != nil {
+		return 0, err
+	}
+	// 计数器未递增说明认证器可能被克隆
+	if (ad.SignCount != 0 || cred.SignCount != 0) && ad.SignCount <= cred.SignCount {
+		return 0, errors.New("signature counter did not increase")
+	}
+	return ad.SignCount, nil
+}
+
+func verifyClientData(rp RelyingParty, typ string, challenge, raw []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("invalid clientDataJSON: %w", err)
+	}
+	if cd.Type != typ {
+		return fmt.Errorf("unexpected ceremony type %q", cd.Type)
+	}
+	got, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	if err != nil || !bytes.Equal(got, challenge) {
+		return errors.New("challenge mismatch")
+	}
+	if cd.Origin != rp.Origin {
+		return fmt.Errorf("unexpected origin %q", cd.Origin)
+	}
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (authenticatorData, error) {
+	var ad authenticatorData
+	if len(data) < 37 {
+		return ad, errors.New("authenticator data too short")
+	}
+	ad.RPIDHash = data[:32]
+	ad.Flags = data[32]
+	ad.SignCount = binary.BigEndian.Uint32(data[33:37])
+	if ad.Flags&flagAttestedCreds == 0 {
+		return ad, nil
+	}
+	rest := data[37:]
+	// aaguid(16) + credentialIdLength(2)
+	if len(rest) < 18 {
+		return ad, errors.New("attested credential data too short")
+	}
+	l := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < l {
+		return ad, errors.New("credential id too short")
+	}
+	ad.CredID = rest[:l]
+	rest = rest[l:]
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return ad, err
+	}
+	ad.PublicKey = rest[:len(rest)-len(after)]
+	return ad, nil
+}
+
+func (ad authenticatorData) verify(rp RelyingParty) error {
+	want := sha256.Sum256([]byte(rp.RPID))
+	if !bytes.Equal(ad.RPIDHash, want[:]) {
+		return errors.New("rpId hash mismatch")
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return errors.New("user not present")
+	}
+	return nil
+}
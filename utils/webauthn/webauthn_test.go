@@ -0,0 +1,191 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+// cborHead 编码 CBOR 数据项头部（测试用）
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(n int) []byte {
+	if n < 0 {
+		return cborHead(1, -1-n)
+	}
+	return cborHead(0, n)
+}
+
+func cborBytes(b []byte) []byte { return append(cborHead(2, len(b)), b...) }
+
+// authenticator 模拟一个 ES256 认证器
+type authenticator struct {
+	key   *ecdsa.PrivateKey
+	id    []byte
+	count uint32
+}
+
+func (a *authenticator) coseKey() []byte {
+	x := a.key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := a.key.PublicKey.Y.FillBytes(make([]byte, 32))
+	out := cborHead(5, 5)
+	out = append(out, cborInt(1)...)
+	out = append(out, cborInt(2)...)
+	out = append(out, cborInt(3)...)
+	out = append(out, cborInt(AlgES256)...)
+	out = append(out, cborInt(-1)...)
+	out = append(out, cborInt(1)...)
+	out = append(out, cborInt(-2)...)
+	out = append(out, cborBytes(x)...)
+	out = append(out, cborInt(-3)...)
+	out = append(out, cborBytes(y)...)
+	return out
+}
+
+func (a *authenticator) authData(rpID string, flags byte, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	out := append([]byte{}, hash[:]...)
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, a.count)
+	if attested {
+		out = append(out, make([]byte, 16)...)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.id)))
+		out = append(out, a.id...)
+		out = append(out, a.coseKey()...)
+	}
+	return out
+}
+
+func clientDataJSON(typ string, challenge []byte, origin string) []byte {
+	b, _ := json.Marshal(clientData{Type: typ, Challenge: base64.RawURLEncoding.EncodeToString(challenge), Origin: origin})
+	return b
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp := RelyingParty{RPID: "komari.local", Origin: "https://komari.local"}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a := &authenticator{key: key, id: []byte("credential-1")}
+
+	// 注册
+	sid, challenge, err := Begin("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, challenge2, ok := Finish(sid)
+	if !ok || owner != "user" || string(challenge2) != string(challenge) {
+		t.Fatal("ceremony not restored")
+	}
+	if _, _, ok := Finish(sid); ok {
+		t.Fatal("ceremony should be single use")
+	}
+	attObj := cborHead(5, 2)
+	attObj = append(attObj, append(cborHead(3, 3), "fmt"...)...)
+	attObj = append(attObj, append(cborHead(3, 4), "none"...)...)
+	attObj = append(attObj, append(cborHead(3, 8), "authData"...)...)
+	attObj = append(attObj, cborBytes(a.authData(rp.RPID, flagUserPresent|flagUserVerified|flagAttestedCreds, true))...)
+	cred, err := VerifyRegistration(rp, challenge, clientDataJSON("webauthn.create", challenge, rp.Origin), attObj)
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	if string(cred.ID) != "credential-1" {
+		t.Fatalf("unexpected credential id %q", cred.ID)
+	}
+
+	sign := func(flags byte, origin string, challenge []byte) ([]byte, []byte, []byte) {
+		a.count++
+		cd := clientDataJSON("webauthn.get", challenge, origin)
+		ad := a.authData(rp.RPID, flags, false)
+		hash := sha256.Sum256(cd)
+		digest := sha256.Sum256(append(append([]byte{}, ad...), hash[:]...))
+		sig, _ := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		return cd, ad, sig
+	}
+
+	tests := []struct {
+		name    string
+		flags   byte
+		origin  string
+		replay  bool
+		wantErr bool
+	}{
+		{name: "正常登录", flags: flagUserPresent | flagUserVerified, origin: rp.Origin},
+		{name: "未进行用户验证", flags: flagUserPresent, origin: rp.Origin, wantErr: true},
+		{name: "来源不匹配", flags: flagUserPresent | flagUserVerified, origin: "https://evil.local", wantErr: true},
+		{name: "计数器未递增", flags: flagUserPresent | flagUserVerified, origin: rp.Origin, replay: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, challenge, _ := Begin("")
+			if tt.replay {
+				a.count = cred.SignCount - 1
+			}
+			cd, ad, sig := sign(tt.flags, tt.origin, challenge)
+			count, err := VerifyAssertion(rp, challenge, cred, cd, ad, sig, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr=%v, got %v", tt.wantErr, err)
+			}
+			if err == nil {
+				cred.SignCount = count
+			}
+		})
+	}
+}
+
+func TestDecodeCBORRejectsMalformed(t *testing.T) {
+	nested := []byte{}
+	for i := 0; i <= maxCBORDepth+1; i++ {
+		nested = append(nested, 0x81) // 单元素数组
+	}
+	nested = append(nested, 0x01)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"数组作为键", []byte{0xa1, 0x80, 0x01}},
+		{"映射作为键", []byte{0xa1, 0xa0, 0x01}},
+		{"字节串作为键", []byte{0xa1, 0x41, 0x00, 0x01}},
+		{"布尔作为键", []byte{0xa1, 0xf5, 0x01}},
+		{"超出 int64 的键", append([]byte{0xa1, 0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 0x01)},
+		{"嵌套过深", nested},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCBOR(tt.data); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDecodeCBORFloat16(t *testing.T) {
+	tests := []struct {
+		bits uint16
+		want float64
+	}{
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x3e00, 1.5},
+		{0x7bff, 65504},
+		{0x0001, 5.960464477539063e-08},
+	}
+	for _, tt := range tests {
+		v, _, err := decodeCBOR([]byte{0xf9, byte(tt.bits >> 8), byte(tt.bits)})
+		if err != nil || v.(float64) != tt.want {
+			t.Errorf("decodeCBOR(%#04x) = %v, %v, want %v", tt.bits, v, err, tt.want)
+		}
+	}
+}